@@ -0,0 +1,79 @@
+// Command copilot-icq-hook is the Copilot CLI hook companion of copilot-icq.
+//
+// Copilot CLI runs it for every configured hook with the event name as the
+// argument and the hook payload on stdin:
+//
+//	copilot-icq-hook preToolUse < payload.json
+//
+// The event is forwarded to the TUI over its Unix socket. For preToolUse the
+// TUI's deny policy decides; when no TUI is running the policy in
+// ~/.copilot-icq/config.yaml is applied here instead. A denial is written to
+// stdout as Copilot CLI expects. Anything else that goes wrong is reported on
+// stderr and the hook exits 0, so a broken hook never blocks Copilot.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/e-9/copilot-icq/internal/config"
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/infra/hookserver"
+	"github.com/e-9/copilot-icq/internal/policy"
+)
+
+// maxPayload caps how much of stdin is read; tool arguments can be large.
+const maxPayload = 4 << 20
+
+// timeout bounds the round trip to the TUI so Copilot CLI is never held up.
+const timeout = 3 * time.Second
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: copilot-icq-hook <event>\n\nevents: %v\n", hookserver.Events)
+		os.Exit(2)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(os.Stdin, maxPayload))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "copilot-icq-hook: read payload: %v\n", err)
+		return
+	}
+	ev, err := hookserver.ParsePayload(os.Args[1], raw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "copilot-icq-hook: %v\n", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	dec, err := hookserver.Send(ctx, hookserver.SocketPath(), ev)
+	if err != nil {
+		// The TUI is not running; the deny policy still applies
+		dec = localDecision(ev)
+	}
+
+	if ev.Name == hookserver.PreToolUse && dec.Deny {
+		json.NewEncoder(os.Stdout).Encode(map[string]string{
+			"permissionDecision":       "deny",
+			"permissionDecisionReason": dec.Reason,
+		})
+	}
+}
+
+// localDecision applies the configured deny policy without the TUI.
+func localDecision(ev hookserver.Event) hookserver.Decision {
+	if ev.Name != hookserver.PreToolUse {
+		return hookserver.Decision{}
+	}
+	pol, err := policy.FromConfig(config.LoadAppConfig(""))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "copilot-icq-hook: config: %v\n", err)
+		return hookserver.Decision{}
+	}
+	return copilot.HookDecision(pol, ev)
+}
@@ -1,13 +1,30 @@
 package main
 
 import (
+"context"
 "fmt"
+"io"
 "os"
+"os/exec"
+"os/signal"
+"strings"
+"syscall"
+"time"
 
 tea "github.com/charmbracelet/bubbletea"
 "github.com/e-9/copilot-icq/internal/app"
+"github.com/e-9/copilot-icq/internal/cli"
 "github.com/e-9/copilot-icq/internal/config"
 "github.com/e-9/copilot-icq/internal/copilot"
+"github.com/e-9/copilot-icq/internal/daemon"
+"github.com/e-9/copilot-icq/internal/exporter"
+"github.com/e-9/copilot-icq/internal/infra/hookinstall"
+"github.com/e-9/copilot-icq/internal/infra/hookserver"
+"github.com/e-9/copilot-icq/internal/infra/notifier"
+"github.com/e-9/copilot-icq/internal/infra/webhook"
+"github.com/e-9/copilot-icq/internal/policy"
+"github.com/e-9/copilot-icq/internal/searchindex"
+"github.com/e-9/copilot-icq/internal/state"
 )
 
 func main() {
@@ -17,26 +34,53 @@ switch os.Args[1] {
 case "doctor":
 runDoctor()
 return
+case "install-hooks":
+runInstallHooks(os.Args[2:])
+return
+case "daemon":
+runDaemon()
+return
+case "list", "send", "tail":
+runCLI(os.Args[1], os.Args[2:])
+return
+case "export":
+runExport(os.Args[2:])
+return
 }
 }
 
-cfg, err := config.Load()
+cfg, appCfg, pol := loadConfig()
+
+st, err := state.Load(state.DefaultPath())
 if err != nil {
 fmt.Fprintf(os.Stderr, "error: %v\n", err)
 os.Exit(1)
 }
 
-// Load user config
-configPath := ""
-for i, arg := range os.Args {
-if arg == "--config" && i+1 < len(os.Args) {
-configPath = os.Args[i+1]
-}
+// Attach to a running daemon; it owns the backend and the hook socket, and
+// sends the notifications and webhooks for all of its TUIs
+var backend copilot.Backend
+attached := false
+if sock := daemon.SocketPath(); daemon.Running(sock) {
+backend = daemon.NewClient(sock)
+attached = true
+} else {
+backend = newBackend(cfg, appCfg, pol)
+stop := serveHooks(backend)
+defer stop()
 }
-appCfg := config.LoadAppConfig(configPath)
 
-adapter := copilot.New()
-model := app.NewModel(cfg.SessionStatePath, appCfg, adapter)
+model := app.NewModel(cfg.SessionStatePath, appCfg, backend, st)
+model.SetSearchIndex(searchindex.Open(searchindex.DefaultPath()))
+
+if !attached {
+// The terminal notifier writes to stderr, out of Bubble Tea's way
+notify, webhooks := announcers(appCfg, os.Stderr)
+// Give queued deliveries a moment to go out on exit
+defer webhooks.Close(3 * time.Second)
+model.SetNotifier(notify)
+model.SetWebhooks(webhooks)
+}
 
 p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 if _, err := p.Run(); err != nil {
@@ -45,6 +89,347 @@ os.Exit(1)
 }
 }
 
+// announcers builds the notifier and webhook dispatcher the config asks
+// for, exiting on config errors. The terminal notifier writes to w.
+func announcers(appCfg *config.AppConfig, w io.Writer) (notifier.Notifier, *webhook.Dispatcher) {
+notify, err := notifier.FromConfig(appCfg, w)
+if err != nil {
+fmt.Fprintf(os.Stderr, "error: config: %v\n", err)
+os.Exit(1)
+}
+webhooks, err := webhook.FromConfig(appCfg)
+if err != nil {
+fmt.Fprintf(os.Stderr, "error: config: %v\n", err)
+os.Exit(1)
+}
+return notify, webhooks
+}
+
+// loadConfig loads the session paths, the user config (--config overrides
+// its path) and the deny policy, exiting on errors.
+func loadConfig() (*config.Config, *config.AppConfig, *policy.Policy) {
+cfg, err := config.Load()
+if err != nil {
+fmt.Fprintf(os.Stderr, "error: %v\n", err)
+os.Exit(1)
+}
+
+appCfg := config.LoadAppConfig(configFlag(os.Args[1:]))
+
+pol, err := policy.FromConfig(appCfg)
+if err != nil {
+fmt.Fprintf(os.Stderr, "error: config: %v\n", err)
+os.Exit(1)
+}
+return cfg, appCfg, pol
+}
+
+// configFlag returns the path given by --config <path> or --config=<path>
+// in args, or "" for the default config.
+func configFlag(args []string) string {
+path := ""
+for i, arg := range args {
+name, val, hasVal := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+if !strings.HasPrefix(arg, "-") || name != "config" {
+continue
+}
+if hasVal {
+path = val
+} else if i+1 < len(args) {
+path = args[i+1]
+}
+}
+return path
+}
+
+// newBackend picks the SDK when it is enabled and copilot is installed,
+// and otherwise follows the session files read-only.
+func newBackend(cfg *config.Config, appCfg *config.AppConfig, pol *policy.Policy) copilot.Backend {
+var backend copilot.Backend
+if appCfg.UseSDK {
+if _, err := exec.LookPath("copilot"); err == nil {
+backend = copilot.New()
+} else {
+fmt.Fprintln(os.Stderr, "warning: copilot not found in PATH; reading session files read-only")
+}
+}
+if backend == nil {
+backend = copilot.NewFileBackend(cfg.SessionStatePath)
+}
+backend.SetPolicy(pol)
+return backend
+}
+
+// serveHooks takes hook events from copilot-icq-hook; everything works
+// without them. The returned func stops the server.
+func serveHooks(backend copilot.Backend) func() {
+hooks := hookserver.New(hookserver.SocketPath(), backend.HandleHook)
+if err := hooks.Listen(); err != nil {
+fmt.Fprintf(os.Stderr, "warning: %v; hook events are disabled\n", err)
+return func() {}
+}
+go hooks.Serve()
+return func() { hooks.Close() }
+}
+
+// runDaemon owns the backend without a UI until interrupted. TUIs started
+// meanwhile attach to it instead of starting their own.
+func runDaemon() {
+cfg, appCfg, pol := loadConfig()
+// Notify once here rather than in every attached TUI
+notify, webhooks := announcers(appCfg, os.Stdout)
+defer webhooks.Close(3 * time.Second)
+
+backend := newBackend(cfg, appCfg, pol)
+if err := backend.Start(context.Background()); err != nil {
+fmt.Fprintf(os.Stderr, "error: %v\n", err)
+os.Exit(1)
+}
+defer backend.Close()
+
+srv := daemon.NewServer(daemon.SocketPath(), backend)
+if err := srv.Listen(); err != nil {
+fmt.Fprintf(os.Stderr, "error: %v\n", err)
+os.Exit(1)
+}
+ann := daemon.NewAnnouncer(backend, notify, webhooks)
+ann.Muted = func(id string) bool {
+// TUIs mute sessions in the state file; read it fresh each time
+st, err := state.Load(state.DefaultPath())
+return err == nil && st.IsMuted(id)
+}
+ann.Watched = srv.Watched
+ann.OnError = func(err error) { fmt.Fprintf(os.Stderr, "notification failed: %v\n", err) }
+srv.Observe(ann.Handle)
+go ann.Run()
+defer ann.Close()
+
+go srv.Serve()
+defer srv.Close()
+
+stop := serveHooks(backend)
+defer stop()
+
+mode := "sdk"
+if backend.ReadOnly() {
+mode = "read-only"
+}
+fmt.Printf("copilot-icq daemon (%s) listening on %s\n", mode, daemon.SocketPath())
+
+sig := make(chan os.Signal, 1)
+signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+<-sig
+fmt.Println("shutting down")
+}
+
+const cliUsage = `usage: copilot-icq list [--json]
+copilot-icq send [--wait] <session> <prompt>
+copilot-icq tail <session>
+
+A session is its ID or an unambiguous prefix of it, as list shows.
+
+list    print the sessions; --json prints them as a JSON array
+send    send a prompt; --wait waits until the session is idle and prints the reply
+tail    stream replies and tool activity until interrupted
+
+--config <path> reads the config from path when no daemon runs.
+
+Without a running daemon, permission requests and questions in the session
+are denied, since nobody can answer them.
+`
+
+// cliArgs are the parsed arguments of list, send and tail.
+type cliArgs struct {
+help   bool
+asJSON bool
+wait   bool
+pos    []string // the session and the prompt
+}
+
+// parseCLIArgs parses the arguments of cmd, one of list, send and tail. It
+// skips --config, which loadConfig reads, and reports false when the
+// arguments are wrong.
+func parseCLIArgs(cmd string, args []string) (cliArgs, bool) {
+var a cliArgs
+for i := 0; i < len(args); i++ {
+arg := args[i]
+switch {
+case arg == "-h" || arg == "--help" || arg == "-help":
+a.help = true
+return a, true
+case cmd == "list" && (arg == "--json" || arg == "-json"):
+a.asJSON = true
+case cmd == "send" && (arg == "--wait" || arg == "-wait" || arg == "-w"):
+a.wait = true
+case arg == "--config" || arg == "-config":
+if i++; i >= len(args) {
+return a, false
+}
+case strings.HasPrefix(arg, "--config=") || strings.HasPrefix(arg, "-config="):
+case strings.HasPrefix(arg, "-") && arg != "-":
+return a, false
+default:
+a.pos = append(a.pos, arg)
+}
+}
+want := map[string]int{"list": 0, "send": 2, "tail": 1}[cmd]
+return a, len(a.pos) == want
+}
+
+func runCLI(cmd string, args []string) {
+a, ok := parseCLIArgs(cmd, args)
+if !ok {
+fmt.Fprint(os.Stderr, cliUsage)
+os.Exit(2)
+}
+if a.help {
+fmt.Print(cliUsage)
+return
+}
+pos := a.pos
+
+ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+defer stop()
+
+// Use the daemon when one runs; its TUIs answer prompts
+r := &cli.Runner{Out: os.Stdout}
+if sock := daemon.SocketPath(); daemon.Running(sock) {
+r.Backend = daemon.NewClient(sock)
+} else {
+cfg, appCfg, pol := loadConfig()
+r.Backend = newBackend(cfg, appCfg, pol)
+r.AnswerPrompts = true
+r.OwnsBackend = true
+}
+if err := r.Backend.Start(ctx); err != nil {
+fmt.Fprintf(os.Stderr, "error: %v\n", err)
+os.Exit(1)
+}
+
+var err error
+switch cmd {
+case "list":
+err = r.List(ctx, a.asJSON)
+case "send":
+err = r.Send(ctx, pos[0], pos[1], a.wait)
+case "tail":
+err = r.Tail(ctx, pos[0])
+}
+r.Backend.Close()
+if err != nil {
+fmt.Fprintf(os.Stderr, "error: %v\n", err)
+os.Exit(1)
+}
+}
+
+const exportUsage = `usage: copilot-icq export [options] <session>
+usage: copilot-icq export [options] --filter <expression>
+usage: copilot-icq export [options] --all
+
+Writes the whole history of sessions, with every tool call, read from the
+session files. A session is its ID or an unambiguous prefix of it.
+
+--format <format>      md, json, jsonl or html (default: export_format, else md)
+--out <dir>            where the files go (default: export_dir); - for stdout
+--template <template>  filename template (default: export_template)
+--filter <expression>  sessions matching every term, e.g. "cwd:~/src/app since:7d login"
+--all                  every session
+
+Filename templates use ID, ShortID, Name, Project, Date, Created and Ext; the
+default is copilot-session-{{.ShortID}}.{{.Ext}}. Filter keys are cwd, name,
+model, id, since and until; a bare word matches the name or the CWD.
+`
+
+func runExport(args []string) {
+for _, arg := range args {
+if arg == "-h" || arg == "--help" || arg == "-help" {
+fmt.Print(exportUsage)
+return
+}
+}
+cfg, appCfg, _ := loadConfig()
+opts := cli.ExportOptions{Dir: config.ExpandHome(appCfg.ExportDir), Template: appCfg.ExportTemplate}
+format := appCfg.ExportFormat
+all, filterSet := false, false
+
+usage := func() {
+fmt.Fprint(os.Stderr, exportUsage)
+os.Exit(2)
+}
+for i := 0; i < len(args); i++ {
+arg := args[i]
+name, val, hasVal := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+if !strings.HasPrefix(arg, "-") || arg == "-" {
+if opts.Session != "" {
+usage()
+}
+opts.Session = arg
+continue
+}
+switch name {
+case "all":
+all = true
+continue
+case "config":
+// Handled by loadConfig
+if !hasVal {
+i++
+}
+continue
+case "format", "out", "o", "template", "filter":
+default:
+usage()
+}
+if !hasVal {
+if i+1 >= len(args) {
+usage()
+}
+i++
+val = args[i]
+}
+switch name {
+case "format":
+format = val
+case "out", "o":
+opts.Dir = config.ExpandHome(val)
+case "template":
+opts.Template = val
+case "filter":
+opts.Filter, filterSet = val, true
+}
+}
+// Exactly one of a session, a filter or --all
+chosen := 0
+for _, set := range []bool{opts.Session != "", filterSet, all} {
+if set {
+chosen++
+}
+}
+if chosen != 1 {
+usage()
+}
+
+f, err := exporter.ParseFormat(format)
+if err != nil {
+fmt.Fprintf(os.Stderr, "error: %v\n", err)
+os.Exit(2)
+}
+opts.Format = f
+if opts.Dir == "-" {
+opts.Stdout = true
+}
+if opts.Dir == "" {
+opts.Dir = "."
+}
+
+// The session files hold the whole history; nothing needs resuming
+r := &cli.Runner{Backend: copilot.NewFileBackend(cfg.SessionStatePath), Out: os.Stdout}
+if err := r.Export(context.Background(), opts); err != nil {
+fmt.Fprintf(os.Stderr, "error: %v\n", err)
+os.Exit(1)
+}
+}
+
 func runDoctor() {
 fmt.Println("🟢 Copilot ICQ — Doctor")
 fmt.Println()
@@ -68,3 +453,77 @@ fmt.Println("  Some checks failed. Fix the issues above and try again.")
 os.Exit(1)
 }
 }
+
+const installHooksUsage = `usage: copilot-icq install-hooks [--uninstall] [--dry-run] [directory]
+
+Writes .github/hooks/copilot-icq.json in the project (default: the current
+directory) so Copilot CLI runs copilot-icq-hook for every lifecycle event.
+Hooks already in the file are kept.
+
+--uninstall  remove the copilot-icq-hook entries instead
+--dry-run    show what would change without writing anything
+`
+
+func runInstallHooks(args []string) {
+uninstall, dryRun := false, false
+dir := "."
+dirSet := false
+for _, arg := range args {
+switch arg {
+case "--uninstall", "-uninstall":
+uninstall = true
+case "--dry-run", "-dry-run", "-n":
+dryRun = true
+case "-h", "--help", "-help":
+fmt.Print(installHooksUsage)
+return
+default:
+if strings.HasPrefix(arg, "-") || dirSet {
+fmt.Fprint(os.Stderr, installHooksUsage)
+os.Exit(2)
+}
+dir = arg
+dirSet = true
+}
+}
+
+apply := hookinstall.Install
+verb := map[hookinstall.Action]string{hookinstall.Added: "+ added", hookinstall.Unchanged: "= already installed"}
+if uninstall {
+apply = hookinstall.Uninstall
+verb = map[hookinstall.Action]string{hookinstall.Removed: "- removed", hookinstall.Unchanged: "= not installed"}
+}
+res, err := apply(dir, dryRun)
+if err != nil {
+fmt.Fprintf(os.Stderr, "error: %v\n", err)
+os.Exit(1)
+}
+
+prefix := ""
+if dryRun {
+prefix = "(dry run) "
+}
+fmt.Printf("%s%s\n", prefix, res.Path)
+for _, c := range res.Changes {
+fmt.Printf("  %-22s %s\n", c.Event, verb[c.Action])
+}
+
+switch {
+case !res.Changed():
+fmt.Println("\nNothing to change.")
+case res.Deleted && dryRun:
+fmt.Println("\nNo hooks would be left; the file would be removed.")
+case res.Deleted:
+fmt.Println("\nNo hooks left; removed the file.")
+case dryRun:
+fmt.Printf("\nWould write:\n%s", res.Content)
+default:
+fmt.Println("\nHook file updated.")
+}
+
+if !uninstall {
+if _, err := exec.LookPath("copilot-icq-hook"); err != nil {
+fmt.Println("\n⚠️  copilot-icq-hook is not in your PATH; the hooks will fail until it is (make build-hook).")
+}
+}
+}
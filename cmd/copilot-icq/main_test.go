@@ -0,0 +1,47 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseCLIArgs(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		args []string
+		want cliArgs
+		ok   bool
+	}{
+		{"list", []string{"--json"}, cliArgs{asJSON: true}, true},
+		{"list", []string{"--config", "/tmp/c.yaml", "--json"}, cliArgs{asJSON: true}, true},
+		{"send", []string{"--config=/tmp/c.yaml", "--wait", "abc", "hi"}, cliArgs{wait: true, pos: []string{"abc", "hi"}}, true},
+		{"tail", []string{"abc", "-config", "/tmp/c.yaml"}, cliArgs{pos: []string{"abc"}}, true},
+		{"tail", []string{"abc", "--config"}, cliArgs{pos: []string{"abc"}}, false},
+		{"tail", []string{"--wait", "abc"}, cliArgs{}, false},
+		{"send", []string{"abc"}, cliArgs{pos: []string{"abc"}}, false},
+		{"list", []string{"--help"}, cliArgs{help: true}, true},
+	}
+	for _, tt := range tests {
+		got, ok := parseCLIArgs(tt.cmd, tt.args)
+		if ok != tt.ok || got.help != tt.want.help || got.asJSON != tt.want.asJSON || got.wait != tt.want.wait || !slices.Equal(got.pos, tt.want.pos) {
+			t.Errorf("parseCLIArgs(%q, %q) = %+v, %v; want %+v, %v", tt.cmd, tt.args, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestConfigFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"list", "--config", "/tmp/a.yaml"}, "/tmp/a.yaml"},
+		{[]string{"send", "--config=/tmp/b.yaml", "abc", "hi"}, "/tmp/b.yaml"},
+		{[]string{"tail", "abc", "--config"}, ""},
+	}
+	for _, tt := range tests {
+		if got := configFlag(tt.args); got != tt.want {
+			t.Errorf("configFlag(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/ui/prompt"
+)
+
+// queueApproval records a permission request until the user answers it.
+// Requests are answered in arrival order, one session at a time. Requests
+// the security policy already denied are only shown in the chat.
+func (m *Model) queueApproval(sessionID string, p *copilot.PermissionEvent) {
+	if p.Denied {
+		m.pendingTools[sessionID] = append(m.pendingTools[sessionID], PendingTool{
+			ToolName:   p.ToolName,
+			ToolArgs:   p.Args,
+			ToolCallID: p.ToolCallID,
+			Denied:     true,
+			DenyReason: p.DenyReason,
+		})
+		if m.selected != nil && m.selected.ID == sessionID {
+			m.chat.SetPendingTools(m.pendingToolsForChat())
+		}
+		return
+	}
+
+	m.approvals[sessionID] = append(m.approvals[sessionID], p)
+	m.pendingTools[sessionID] = append(m.pendingTools[sessionID], PendingTool{
+		ToolName:   p.ToolName,
+		ToolArgs:   p.Args,
+		ToolCallID: p.ToolCallID,
+	})
+	m.sidebar.SetApprovals(m.approvalCounts())
+
+	if m.selected != nil && m.selected.ID == sessionID {
+		m.chat.SetPendingTools(m.pendingToolsForChat())
+		if m.focus == FocusInput && !m.hasDialog() {
+			m.focus = FocusChat
+			m.input.Blur()
+		}
+		m.syncApproval()
+	}
+}
+
+// answerApproval sends the user's decision for the selected session's oldest
+// permission request and advances the dialog to the next one.
+func (m *Model) answerApproval(res prompt.ApprovalResult) {
+	if m.selected == nil {
+		return
+	}
+	sessionID := m.selected.ID
+	queue := m.approvals[sessionID]
+	if len(queue) == 0 {
+		return
+	}
+
+	head := queue[0]
+	allow := res.Decision == prompt.AllowOnce || res.Decision == prompt.AllowSession
+	head.Response <- copilot.PermissionResponse{
+		Allow:      allow,
+		ForSession: res.Decision == prompt.AllowSession,
+	}
+	// The reason is only shown in the chat; the agent has no way to receive it
+	m.resolvePendingTool(sessionID, head, allow, res.Reason)
+	queue = queue[1:]
+
+	// Allowing for the session also settles requests already queued for the same tool.
+	if res.Decision == prompt.AllowSession {
+		var remaining []*copilot.PermissionEvent
+		for _, p := range queue {
+			if p.ToolName == head.ToolName && p.Kind == head.Kind {
+				p.Response <- copilot.PermissionResponse{Allow: true}
+				m.resolvePendingTool(sessionID, p, true, "")
+				continue
+			}
+			remaining = append(remaining, p)
+		}
+		queue = remaining
+	}
+
+	if len(queue) == 0 {
+		delete(m.approvals, sessionID)
+	} else {
+		m.approvals[sessionID] = queue
+	}
+	m.sidebar.SetApprovals(m.approvalCounts())
+	m.chat.SetPendingTools(m.pendingToolsForChat())
+	m.syncApproval()
+}
+
+// approvalResolved drops a permission request answered elsewhere, e.g. in
+// another TUI attached to the same daemon, closing its dialog here.
+func (m *Model) approvalResolved(sessionID string, r *copilot.ResolvedEvent) {
+	queue := m.approvals[sessionID]
+	for i, p := range queue {
+		if p.RequestID == "" || p.RequestID != r.RequestID {
+			continue
+		}
+		if queue = append(queue[:i:i], queue[i+1:]...); len(queue) == 0 {
+			delete(m.approvals, sessionID)
+		} else {
+			m.approvals[sessionID] = queue
+		}
+		m.resolvePendingTool(sessionID, p, r.Allow, "")
+		m.sidebar.SetApprovals(m.approvalCounts())
+		if m.selected != nil && m.selected.ID == sessionID {
+			m.chat.SetPendingTools(m.pendingToolsForChat())
+			m.syncApproval()
+		}
+		return
+	}
+}
+
+// resolvePendingTool updates the chat's pending entry for an answered request.
+// Allowed tools are dropped (tool.execution_start re-adds them while running);
+// denied tools stay visible with their reason until the session goes idle.
+func (m *Model) resolvePendingTool(sessionID string, p *copilot.PermissionEvent, allow bool, reason string) {
+	tools := m.pendingTools[sessionID]
+	for i, t := range tools {
+		if t.ToolCallID != p.ToolCallID || t.ToolName != p.ToolName || t.Denied {
+			continue
+		}
+		if allow {
+			m.pendingTools[sessionID] = append(tools[:i:i], tools[i+1:]...)
+		} else {
+			if reason == "" {
+				reason = "denied by you"
+			}
+			tools[i].Denied = true
+			tools[i].DenyReason = reason
+		}
+		return
+	}
+}
+
+// syncApproval shows, refreshes or hides the approval dialog to match the
+// selected session's queue, then re-lays out the right panel.
+func (m *Model) syncApproval() {
+	var queue []*copilot.PermissionEvent
+	if m.selected != nil {
+		queue = m.approvals[m.selected.ID]
+	}
+
+	if len(queue) == 0 {
+		m.approval = nil
+		m.approvalHead = nil
+	} else {
+		head := queue[0]
+		if m.approval == nil || m.approvalHead != head {
+			req := prompt.ApprovalRequest{ToolName: head.ToolName, Kind: head.Kind, Args: head.Args}
+			a := prompt.NewApproval(req, m.chat.Width())
+			m.approval = &a
+			m.approvalHead = head
+		}
+		m.approval.SetQueued(len(queue) - 1)
+	}
+	m.settleDialogFocus()
+	m.resize()
+}
+
+// approvalCounts returns the number of permission requests and questions
+// awaiting an answer per session.
+func (m Model) approvalCounts() map[string]int {
+	counts := make(map[string]int, len(m.approvals)+len(m.questions))
+	for id, q := range m.approvals {
+		counts[id] += len(q)
+	}
+	for id, q := range m.questions {
+		counts[id] += len(q)
+	}
+	return counts
+}
+
+// totalApprovals returns the number of requests awaiting an answer across sessions.
+func (m Model) totalApprovals() int {
+	n := 0
+	for _, c := range m.approvalCounts() {
+		n += c
+	}
+	return n
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/ui/prompt"
+)
+
+func TestAllowForSessionSettlesMatchingRequests(t *testing.T) {
+	m := NewModel("", nil, nil, nil)
+	m.selected = &domain.Session{ID: "s1"}
+
+	bash1 := make(chan copilot.PermissionResponse, 1)
+	edit := make(chan copilot.PermissionResponse, 1)
+	bash2 := make(chan copilot.PermissionResponse, 1)
+	m.queueApproval("s1", &copilot.PermissionEvent{ToolName: "bash", Kind: "shell", ToolCallID: "1", Response: bash1})
+	m.queueApproval("s1", &copilot.PermissionEvent{ToolName: "edit", Kind: "write", ToolCallID: "2", Response: edit})
+	m.queueApproval("s1", &copilot.PermissionEvent{ToolName: "bash", Kind: "shell", ToolCallID: "3", Response: bash2})
+
+	if m.approval == nil {
+		t.Fatal("expected approval dialog for selected session")
+	}
+
+	m.answerApproval(prompt.ApprovalResult{Decision: prompt.AllowSession})
+
+	if r := <-bash1; !r.Allow || !r.ForSession {
+		t.Errorf("first bash response = %+v, want allow for session", r)
+	}
+	if r := <-bash2; !r.Allow {
+		t.Errorf("queued bash response = %+v, want allow", r)
+	}
+	if got := len(m.approvals["s1"]); got != 1 {
+		t.Fatalf("remaining approvals = %d, want 1", got)
+	}
+
+	m.answerApproval(prompt.ApprovalResult{Decision: prompt.DenyWithReason, Reason: "read-only task"})
+
+	if r := <-edit; r.Allow {
+		t.Errorf("edit response = %+v, want deny", r)
+	}
+	if m.approval != nil {
+		t.Error("dialog should close once the queue is empty")
+	}
+	tools := m.pendingTools["s1"]
+	if len(tools) != 1 || !tools[0].Denied || tools[0].DenyReason != "read-only task" {
+		t.Errorf("pending tools = %+v, want one denied edit", tools)
+	}
+}
+
+func TestApprovalKeysNeedDialogFocus(t *testing.T) {
+	m, _ := resumedModel(t)
+	m.focus = FocusChat
+	resp := make(chan copilot.PermissionResponse, 1)
+	m.queueApproval("a", &copilot.PermissionEvent{ToolName: "bash", Kind: "shell", ToolCallID: "1", Response: resp})
+
+	// With the chat focused, d opens the session diff instead of denying
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = model.(Model)
+	if m.diffView == nil || len(resp) != 0 {
+		t.Fatal("d should reach the chat while the dialog is unfocused")
+	}
+	m.diffView = nil
+
+	for _, k := range []tea.KeyMsg{{Type: tea.KeyTab}, {Type: tea.KeyRunes, Runes: []rune("a")}} {
+		model, _ = m.Update(k)
+		m = model.(Model)
+	}
+	if r := <-resp; !r.Allow || r.ForSession {
+		t.Errorf("response = %+v, want allow once", r)
+	}
+	if m.approval != nil || m.focus != FocusInput {
+		t.Errorf("focus = %v; the input should take over once the dialog closes", m.focus)
+	}
+}
+
+func TestAnsweredElsewhereClosesDialogs(t *testing.T) {
+	m, _ := resumedModel(t)
+	m.queueApproval("a", &copilot.PermissionEvent{ToolName: "bash", ToolCallID: "1", RequestID: "1", Response: make(chan copilot.PermissionResponse, 1)})
+	m.queueQuestion("a", &copilot.UserInputEvent{Question: "Which?", RequestID: "2", Response: make(chan copilot.UserInputResponse, 1)})
+
+	model, _ := m.Update(SDKEventMsg{Event: copilot.Event{Type: copilot.EventResolved, SessionID: "a", Resolved: &copilot.ResolvedEvent{RequestID: "1"}}})
+	m = model.(Model)
+	if m.approval != nil || m.question == nil {
+		t.Fatal("the approval should close and the question take its place")
+	}
+	if tools := m.pendingTools["a"]; len(tools) != 1 || tools[0].DenyReason != "denied by you" {
+		t.Errorf("pending tools = %+v, want the denial shown", tools)
+	}
+
+	model, _ = m.Update(SDKEventMsg{Event: copilot.Event{Type: copilot.EventResolved, SessionID: "a", Resolved: &copilot.ResolvedEvent{RequestID: "2", Answer: "y"}}})
+	m = model.(Model)
+	if m.question != nil || m.totalApprovals() != 0 {
+		t.Error("the question should close too")
+	}
+	msgs := m.chat.Messages()
+	if tc := msgs[len(msgs)-1].ToolCalls[0]; tc.Status != domain.ToolCallComplete || tc.Summary != "y" {
+		t.Errorf("ask_user = %+v, want the answer recorded", tc)
+	}
+}
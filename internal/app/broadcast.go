@@ -0,0 +1,145 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/e-9/copilot-icq/internal/ui/broadcast"
+)
+
+// openBroadcast shows the broadcast composer for the sessions marked in the
+// sidebar, or the progress of a broadcast that is still running.
+func (m *Model) openBroadcast() tea.Cmd {
+	if m.broadcast != nil {
+		m.broadcast.Reopen()
+		m.input.Blur()
+		return nil
+	}
+	if m.readOnly() {
+		m.statusFlash = "📣 Broadcasts need use_sdk: true"
+		return clearFlashAfter(3 * time.Second)
+	}
+	marked := m.sidebar.Marked()
+	if len(marked) == 0 {
+		m.statusFlash = "📣 Mark sessions with Space first"
+		return clearFlashAfter(3 * time.Second)
+	}
+	targets := make([]broadcast.Target, len(marked))
+	for i, s := range marked {
+		targets[i] = broadcast.Target{ID: s.ID, Name: s.DisplayName()}
+	}
+	b := broadcast.New(targets, m.chat.Width(), m.panelHeight())
+	m.broadcast = &b
+	m.input.Blur()
+	return nil
+}
+
+// updateBroadcast routes a key to the broadcast panel and starts sending
+// once the prompt is submitted.
+func (m *Model) updateBroadcast(msg tea.KeyMsg) tea.Cmd {
+	_, wasSubmitted := m.broadcast.Submitted()
+	b, cmd := m.broadcast.Update(msg)
+	m.broadcast = &b
+
+	if b.Closed() {
+		// A running broadcast keeps going in the background
+		if !wasSubmitted || b.Finished() {
+			m.broadcast = nil
+		}
+		return nil
+	}
+	if _, ok := b.Submitted(); ok && !wasSubmitted {
+		m.sidebar.ClearMarked()
+		return m.startBroadcast()
+	}
+	return cmd
+}
+
+// startBroadcast sends the prompt to every target: idle sessions right away,
+// busy ones when their current turn ends and the rest once resumed.
+func (m *Model) startBroadcast() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, t := range m.broadcast.Targets() {
+		switch {
+		case !m.sdkResumed[t.ID]:
+			m.broadcast.SetStatus(t.ID, broadcast.Resuming, nil)
+			cmds = append(cmds, sdkResumeSession(m.backend, t.ID, ""))
+		case m.pendingSends[t.ID]:
+			m.broadcast.SetStatus(t.ID, broadcast.Waiting, nil)
+		default:
+			cmds = append(cmds, m.broadcastTo(t.ID))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// broadcastTo sends the broadcast prompt to one target.
+func (m *Model) broadcastTo(id string) tea.Cmd {
+	text, _ := m.broadcast.Submitted()
+	m.broadcast.SetStatus(id, broadcast.Sending, nil)
+	return m.dispatch(id, text)
+}
+
+// broadcastStatus returns a target's progress, if a broadcast is running
+// and the session is one of its targets.
+func (m Model) broadcastStatus(id string) (broadcast.Status, bool) {
+	if m.broadcast == nil {
+		return 0, false
+	}
+	if _, ok := m.broadcast.Submitted(); !ok {
+		return 0, false
+	}
+	return m.broadcast.Status(id)
+}
+
+// broadcastResumed sends the prompt to a target that was just resumed.
+func (m *Model) broadcastResumed(id string, err error) tea.Cmd {
+	if st, ok := m.broadcastStatus(id); !ok || st != broadcast.Resuming {
+		return nil
+	}
+	switch {
+	case err != nil:
+		m.broadcastFailed(id, err)
+		return nil
+	case m.pendingSends[id]:
+		m.broadcast.SetStatus(id, broadcast.Waiting, nil)
+		return nil
+	}
+	return m.broadcastTo(id)
+}
+
+// broadcastIdle moves a target on when its session goes idle: a waiting
+// target gets the prompt now, a sending one is done.
+func (m *Model) broadcastIdle(id string) tea.Cmd {
+	st, ok := m.broadcastStatus(id)
+	if !ok {
+		return nil
+	}
+	switch st {
+	case broadcast.Waiting:
+		return m.broadcastTo(id)
+	case broadcast.Sending:
+		m.broadcast.SetStatus(id, broadcast.Idle, nil)
+		m.finishBroadcast()
+	}
+	return nil
+}
+
+// broadcastFailed records why a target did not get through. It reports
+// whether the session was a target still in progress.
+func (m *Model) broadcastFailed(id string, err error) bool {
+	st, ok := m.broadcastStatus(id)
+	if !ok || st == broadcast.Idle || st == broadcast.Failed {
+		return false
+	}
+	m.broadcast.SetStatus(id, broadcast.Failed, err)
+	m.finishBroadcast()
+	return true
+}
+
+// finishBroadcast forgets a broadcast that has finished while hidden.
+func (m *Model) finishBroadcast() {
+	if m.broadcast.Finished() && m.broadcast.Closed() {
+		m.broadcast = nil
+	}
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/ui/broadcast"
+)
+
+func TestBroadcastProgress(t *testing.T) {
+	m := NewModel("", nil, nil, nil)
+	m.sessions = []domain.Session{{ID: "idle"}, {ID: "busy"}, {ID: "cold"}}
+	m.sidebar.SetItems(m.sessions)
+	m.sdkResumed["idle"] = true
+	m.sdkResumed["busy"] = true
+	m.pendingSends["busy"] = true
+	for _, s := range m.sessions {
+		m.sidebar.ToggleMarked(s.ID)
+	}
+
+	m.openBroadcast()
+	if m.broadcast == nil {
+		t.Fatal("broadcast composer should open for marked sessions")
+	}
+	for _, r := range "rebase on main" {
+		m.updateBroadcast(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	m.updateBroadcast(tea.KeyMsg{Type: tea.KeyEnter})
+
+	want := map[string]broadcast.Status{
+		"idle": broadcast.Sending,
+		"busy": broadcast.Waiting,
+		"cold": broadcast.Resuming,
+	}
+	for id, st := range want {
+		if got, _ := m.broadcast.Status(id); got != st {
+			t.Errorf("%s: status %v, want %v", id, got, st)
+		}
+	}
+	if len(m.sidebar.Marked()) != 0 {
+		t.Error("marks should be cleared once the broadcast starts")
+	}
+
+	var cmds []tea.Cmd
+	idle := sdk.SessionEvent{Type: sdk.SessionIdle}
+	m.handleSDKSessionEvent("busy", idle, &cmds)
+	if got, _ := m.broadcast.Status("busy"); got != broadcast.Sending {
+		t.Errorf("busy: status %v after its turn ended, want sending", got)
+	}
+	m.broadcastResumed("cold", errors.New("boom"))
+	m.handleSDKSessionEvent("idle", idle, &cmds)
+	m.handleSDKSessionEvent("busy", idle, &cmds)
+
+	if !m.broadcast.Finished() {
+		t.Fatalf("broadcast should be finished: %+v", m.broadcast.Targets())
+	}
+	if got, _ := m.broadcast.Status("cold"); got != broadcast.Failed {
+		t.Errorf("cold: status %v, want error", got)
+	}
+}
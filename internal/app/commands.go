@@ -2,10 +2,14 @@ package app
 
 import (
 "context"
+"os"
+"os/exec"
+"strings"
 "time"
 
 tea "github.com/charmbracelet/bubbletea"
 "github.com/e-9/copilot-icq/internal/copilot"
+"github.com/e-9/copilot-icq/internal/state"
 )
 
 // tickEvery returns a Cmd that sends a TickMsg after the given duration.
@@ -15,10 +19,62 @@ return TickMsg{}
 })
 }
 
+// clearFlashAfter returns a Cmd that clears the status bar flash after d.
+func clearFlashAfter(d time.Duration) tea.Cmd {
+return tea.Tick(d, func(_ time.Time) tea.Msg {
+return ClearFlashMsg{}
+})
+}
+
+// saveState persists the local session state.
+func saveState(st *state.Store) tea.Cmd {
+return func() tea.Msg {
+return StateSavedMsg{Err: st.Save()}
+}
+}
+
+// openEditor suspends the TUI and edits text in $VISUAL or $EDITOR
+// (falling back to vi) on a temp file. The saved file is returned as an
+// EditorFinishedMsg.
+func openEditor(text string) tea.Cmd {
+f, err := os.CreateTemp("", "copilot-icq-*.md")
+if err != nil {
+return func() tea.Msg { return EditorFinishedMsg{Err: err} }
+}
+path := f.Name()
+_, err = f.WriteString(text)
+f.Close()
+if err != nil {
+os.Remove(path)
+return func() tea.Msg { return EditorFinishedMsg{Err: err} }
+}
+
+editor := os.Getenv("VISUAL")
+if editor == "" {
+editor = os.Getenv("EDITOR")
+}
+if editor == "" {
+editor = "vi"
+}
+// Allow editors with flags, e.g. EDITOR="code --wait"
+args := append(strings.Fields(editor), path)
+cmd := exec.Command(args[0], args[1:]...)
+
+return tea.ExecProcess(cmd, func(err error) tea.Msg {
+defer os.Remove(path)
+if err != nil {
+return EditorFinishedMsg{Err: err}
+}
+data, err := os.ReadFile(path)
+return EditorFinishedMsg{Text: strings.TrimSpace(string(data)), Err: err}
+})
+}
+
 // --- SDK commands ---
 
-// sdkStart initializes the SDK adapter connection.
-func sdkStart(a *copilot.Adapter) tea.Cmd {
+// sdkStart starts the backend: the SDK connection, or following the
+// session files in read-only mode.
+func sdkStart(a copilot.Backend) tea.Cmd {
 return func() tea.Msg {
 err := a.Start(context.Background())
 return SDKConnectedMsg{Err: err}
@@ -26,7 +82,7 @@ return SDKConnectedMsg{Err: err}
 }
 
 // sdkListSessions lists sessions via the SDK.
-func sdkListSessions(a *copilot.Adapter) tea.Cmd {
+func sdkListSessions(a copilot.Backend) tea.Cmd {
 return func() tea.Msg {
 sessions, err := a.ListSessions(context.Background())
 return SessionsLoadedMsg{Sessions: sessions, Err: err}
@@ -34,15 +90,48 @@ return SessionsLoadedMsg{Sessions: sessions, Err: err}
 }
 
 // sdkResumeSession resumes a session via the SDK.
-func sdkResumeSession(a *copilot.Adapter, sessionID string) tea.Cmd {
+// An empty model keeps the session's current model.
+func sdkResumeSession(a copilot.Backend, sessionID, model string) tea.Cmd {
+return func() tea.Msg {
+model, err := a.ResumeSession(context.Background(), sessionID, model)
+return SDKSessionResumedMsg{SessionID: sessionID, Model: model, Err: err}
+}
+}
+
+// sdkListModels lists the models the CLI offers.
+func sdkListModels(a copilot.Backend) tea.Cmd {
+return func() tea.Msg {
+models, err := a.ListModels(context.Background())
+return ModelsLoadedMsg{Models: models, Err: err}
+}
+}
+
+// sdkSwitchModel switches a resumed session to another model.
+func sdkSwitchModel(a copilot.Backend, sessionID, model string) tea.Cmd {
+return func() tea.Msg {
+err := a.SwitchModel(context.Background(), sessionID, model)
+return ModelSwitchedMsg{SessionID: sessionID, Model: model, Err: err}
+}
+}
+
+// sdkCreateSession starts a new session via the SDK.
+func sdkCreateSession(a copilot.Backend, opts copilot.CreateSessionOptions) tea.Cmd {
+return func() tea.Msg {
+s, err := a.CreateSession(context.Background(), opts)
+return SDKSessionCreatedMsg{Session: s, Err: err}
+}
+}
+
+// sdkDeleteSession deletes a session via the SDK.
+func sdkDeleteSession(a copilot.Backend, sessionID string) tea.Cmd {
 return func() tea.Msg {
-err := a.ResumeSession(context.Background(), sessionID)
-return SDKSessionResumedMsg{SessionID: sessionID, Err: err}
+err := a.DeleteSession(context.Background(), sessionID)
+return SDKSessionDeletedMsg{SessionID: sessionID, Err: err}
 }
 }
 
 // sdkLoadHistory loads conversation history via the SDK.
-func sdkLoadHistory(a *copilot.Adapter, sessionID string) tea.Cmd {
+func sdkLoadHistory(a copilot.Backend, sessionID string) tea.Cmd {
 return func() tea.Msg {
 msgs, err := a.GetHistory(context.Background(), sessionID)
 return EventsLoadedMsg{SessionID: sessionID, Messages: msgs, Err: err}
@@ -50,7 +139,7 @@ return EventsLoadedMsg{SessionID: sessionID, Messages: msgs, Err: err}
 }
 
 // sdkSendMessage sends a message via the SDK.
-func sdkSendMessage(a *copilot.Adapter, sessionID, text string) tea.Cmd {
+func sdkSendMessage(a copilot.Backend, sessionID, text string) tea.Cmd {
 return func() tea.Msg {
 _, err := a.Send(context.Background(), sessionID, text)
 if err != nil {
@@ -63,17 +152,17 @@ return nil
 }
 
 // sdkAbort cancels the current message processing via the SDK.
-func sdkAbort(a *copilot.Adapter, sessionID string) tea.Cmd {
+func sdkAbort(a copilot.Backend, sessionID string) tea.Cmd {
 return func() tea.Msg {
 _ = a.Abort(context.Background(), sessionID)
 return nil
 }
 }
 
-// listenSDKEvents reads from the SDK adapter's Events channel.
-func listenSDKEvents(a *copilot.Adapter) tea.Cmd {
+// listenSDKEvents reads from the backend's Events channel.
+func listenSDKEvents(a copilot.Backend) tea.Cmd {
 return func() tea.Msg {
-evt, ok := <-a.Events
+evt, ok := <-a.Events()
 if !ok {
 return SDKDisconnectedMsg{}
 }
@@ -0,0 +1,75 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/e-9/copilot-icq/internal/diff"
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/ui/diffview"
+)
+
+// openToolDiff shows the patch of the chat's selected tool call in the diff
+// view. It reports false when the selection has no patch.
+func (m *Model) openToolDiff() bool {
+	tc, ok := m.chat.SelectedToolCall()
+	if !ok || tc.Patch == "" {
+		return false
+	}
+	title := "📝 " + tc.Name
+	if tc.FilePath != "" {
+		title += " · " + tc.FilePath
+	}
+	d := diffview.New(title, []diffview.Edit{{Time: tc.StartedAt, Files: diff.Parse(tc.Patch)}}, m.width, m.height)
+	m.diffView = &d
+	return true
+}
+
+// openSessionDiff shows every file the agent changed in the open
+// conversation, with all of its edits.
+func (m *Model) openSessionDiff() bool {
+	if m.selected == nil {
+		return false
+	}
+	d := diffview.New("Files changed · "+m.selected.DisplayName(), sessionEdits(m.chat.Messages()), m.width, m.height)
+	m.diffView = &d
+	return true
+}
+
+// sessionEdits collects the patches of a conversation's tool calls, in
+// order. Failed calls changed nothing and ones still waiting for approval
+// have not changed anything yet, so both are left out.
+func sessionEdits(msgs []domain.Message) []diffview.Edit {
+	var edits []diffview.Edit
+	for _, msg := range msgs {
+		for _, tc := range msg.ToolCalls {
+			if tc.Patch == "" || tc.Status == domain.ToolCallFailed || tc.Status == domain.ToolCallPending || tc.Status == domain.ToolCallRunning {
+				continue
+			}
+			at := tc.StartedAt
+			if at.IsZero() {
+				at = msg.Timestamp
+			}
+			edits = append(edits, diffview.Edit{Time: at, Files: diff.Parse(tc.Patch)})
+		}
+	}
+	return edits
+}
+
+// updateDiffView routes a key or mouse event to the diff view and closes it
+// when the user is done.
+func (m *Model) updateDiffView(msg tea.Msg) tea.Cmd {
+	d, cmd := m.diffView.Update(msg)
+	if d.Closed() {
+		m.diffView = nil
+		return nil
+	}
+	m.diffView = &d
+	return cmd
+}
+
+// resizeDiffView fits the diff view to the screen.
+func (m *Model) resizeDiffView() {
+	if m.diffView != nil {
+		m.diffView.SetSize(m.width, m.height)
+	}
+}
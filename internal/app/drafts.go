@@ -0,0 +1,30 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/e-9/copilot-icq/internal/state"
+)
+
+// syncDraft copies the composer into the selected session's stored draft and
+// reports whether the stored draft changed. An empty composer clears it.
+func (m *Model) syncDraft() bool {
+	if m.selected == nil || m.renaming || m.editingQueued >= 0 {
+		return false
+	}
+	row, col := m.input.Cursor()
+	changed := m.store.SetDraft(m.selected.ID, state.Draft{Text: m.input.Value(), Row: row, Col: col})
+	if changed {
+		m.sidebar.SetDrafts(m.store.DraftIDs())
+	}
+	return changed
+}
+
+// quit stashes the current draft and writes local state and the search
+// index before exiting. There is nowhere left to report a failed write, so
+// it is dropped.
+func (m *Model) quit() tea.Cmd {
+	m.syncDraft()
+	_ = m.store.Save()
+	_ = m.index.Save()
+	return tea.Quit
+}
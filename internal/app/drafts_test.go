@@ -0,0 +1,41 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+func TestDraftsFollowSessionSwitches(t *testing.T) {
+	m := NewModel("", nil, nil, nil)
+	a := &domain.Session{ID: "a"}
+	b := &domain.Session{ID: "b"}
+	m.sessions = []domain.Session{*a, *b}
+
+	m.selectSession(a, "")
+	m.input.SetValue("line one\nline two")
+	m.input.SetCursor(0, 4)
+
+	m.selectSession(b, "")
+	if got := m.input.Value(); got != "" {
+		t.Fatalf("b's composer = %q, want empty", got)
+	}
+	if !m.store.DraftIDs()["a"] {
+		t.Fatal("a's draft should be stashed when switching away")
+	}
+
+	m.selectSession(a, "")
+	if got := m.input.Value(); got != "line one\nline two" {
+		t.Errorf("restored draft = %q", got)
+	}
+	if row, col := m.input.Cursor(); row != 0 || col != 4 {
+		t.Errorf("restored cursor = %d:%d, want 0:4", row, col)
+	}
+
+	// Sending clears the stored draft.
+	m.sdkResumed["a"] = true
+	m.sendPrompt(m.input.Value())
+	if _, ok := m.store.Draft("a"); ok {
+		t.Error("draft should be cleared once sent")
+	}
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/searchindex"
+	"github.com/e-9/copilot-icq/internal/ui/finder"
+)
+
+// finderLimit caps the results shown for a query.
+const finderLimit = 50
+
+// openAt is a search result waiting for its session's history to load.
+type openAt struct {
+	sessionID string
+	message   int
+	query     string
+}
+
+// SetSearchIndex sets the index the finder searches. NewModel starts with
+// an in-memory one.
+func (m *Model) SetSearchIndex(ix *searchindex.Index) {
+	m.index = ix
+}
+
+// openFinder opens the search over every session's history.
+func (m *Model) openFinder() tea.Cmd {
+	f := finder.New(m.chat.Width(), m.panelHeight())
+	m.finder = &f
+	m.input.Blur()
+	m.finderStatus()
+	return tea.Batch(m.finder.Focus(), m.syncIndex())
+}
+
+// updateFinder routes a key to the finder, runs the query when it changes
+// and opens the chosen result.
+func (m *Model) updateFinder(msg tea.KeyMsg) tea.Cmd {
+	before := m.finder.Query()
+	f, cmd := m.finder.Update(msg)
+	m.finder = &f
+
+	if f.Cancelled() {
+		m.finder = nil
+		return nil
+	}
+	if r, ok := f.Chosen(); ok {
+		m.finder = nil
+		return m.openResult(r, f.Query())
+	}
+	if f.Query() != before {
+		m.runFinderQuery()
+	}
+	return cmd
+}
+
+// runFinderQuery searches the index for the finder's query. Hits in
+// sessions no longer listed are left out.
+func (m *Model) runFinderQuery() {
+	q := m.finder.Query()
+	var results []finder.Result
+	for _, h := range m.index.Search(q, finderLimit) {
+		s := m.sessionByID(h.SessionID)
+		if s == nil {
+			continue
+		}
+		results = append(results, finder.Result{
+			SessionID: h.SessionID,
+			Session:   s.DisplayName(),
+			Message:   h.Message,
+			Role:      h.Role,
+			Time:      h.Time,
+			Snippet:   h.Snippet,
+		})
+	}
+	m.finder.SetResults(results, searchindex.WordsPattern(q))
+}
+
+// finderStatus tells the finder while the first sync is still running, as
+// results may be missing until it is done.
+func (m *Model) finderStatus() {
+	if m.finder == nil {
+		return
+	}
+	status := ""
+	switch {
+	case m.indexErr != nil:
+		status = fmt.Sprintf("⚠️  Some sessions could not be indexed: %v", m.indexErr)
+	case m.indexing && !m.indexed:
+		status = "⏳ Indexing sessions..."
+	}
+	m.finder.SetStatus(status)
+}
+
+// openResult opens a result's session and, once its history has loaded,
+// shows the message with the query's words marked.
+func (m *Model) openResult(r finder.Result, query string) tea.Cmd {
+	s := m.sessionByID(r.SessionID)
+	if s == nil {
+		return nil
+	}
+	target := *s
+	m.focus = FocusChat
+	cmd := m.selectSession(&target, "")
+	m.openAt = &openAt{sessionID: r.SessionID, message: r.Message, query: query}
+	return cmd
+}
+
+// showOpenAt shows the pending search result once its history is loaded.
+func (m *Model) showOpenAt(sessionID string) {
+	if m.openAt == nil || m.openAt.sessionID != sessionID {
+		return
+	}
+	m.chat.ShowMessage(m.openAt.message, m.openAt.query, searchindex.WordsPattern(m.openAt.query))
+	m.openAt = nil
+}
+
+// syncIndex brings the search index up to date with the listed sessions in
+// the background, unless a sync is already running. The index is saved
+// after the first sync, so a long first build is kept; later changes are
+// saved on quit.
+func (m *Model) syncIndex() tea.Cmd {
+	if m.indexing || m.sessionBasePath == "" {
+		return nil
+	}
+	m.indexing = true
+	m.finderStatus()
+	ix, base, first := m.index, m.sessionBasePath, !m.indexed
+	sessions := append([]domain.Session(nil), m.sessions...)
+	return func() tea.Msg {
+		_, err := ix.Sync(context.Background(), base, sessions)
+		if first {
+			if saveErr := ix.Save(); err == nil {
+				err = saveErr
+			}
+		}
+		return IndexSyncedMsg{Err: err}
+	}
+}
+
+// indexSynced records a finished sync and refreshes the finder's results.
+func (m *Model) indexSynced(msg IndexSyncedMsg) {
+	m.indexing = false
+	m.indexed = true
+	m.indexErr = msg.Err
+	if m.finder != nil {
+		m.finderStatus()
+		m.runFinderQuery()
+	}
+}
+
+// sessionByID returns a listed session.
+func (m Model) sessionByID(id string) *domain.Session {
+	for i := range m.sessions {
+		if m.sessions[i].ID == id {
+			return &m.sessions[i]
+		}
+	}
+	return nil
+}
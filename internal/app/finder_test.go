@@ -0,0 +1,59 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/e-9/copilot-icq/internal/copilot/copilottest"
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+func TestFinderOpensMatch(t *testing.T) {
+	fake := copilottest.New(domain.Session{ID: "a", Summary: "api"}, domain.Session{ID: "b", Summary: "frontend"})
+	var history []domain.Message
+	for i := 0; i < 30; i++ {
+		history = append(history, domain.Message{Role: domain.RoleUser, Content: fmt.Sprintf("message %d", i)})
+	}
+	history[20].Content = "the flaky websocket test"
+	fake.History["b"] = history
+
+	model, _ := NewModel("", nil, fake, nil).Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m := model.(Model)
+	m.sessions = fake.Sessions
+	m.index.Set("b", history, 1)
+
+	keys := append([]tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("F")}}, runes("WebSock")...)
+	for _, k := range keys {
+		model, _ := m.Update(k)
+		m = model.(Model)
+	}
+	if m.finder == nil || !strings.Contains(m.finder.View(), "frontend") {
+		t.Fatal("the finder should list the match in session b")
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(Model)
+	if m.finder != nil || m.selected == nil || m.selected.ID != "b" || m.openAt == nil {
+		t.Fatalf("enter should close the finder and open session b, selected = %+v", m.selected)
+	}
+
+	model, _ = m.Update(sdkLoadHistory(fake, "b")())
+	m = model.(Model)
+	if m.openAt != nil || !m.chat.HasSearch() {
+		t.Error("the loaded history should show the match with the search open")
+	}
+	if view := m.chat.View(); !strings.Contains(view, "flaky") || strings.Contains(view, "message 29") {
+		t.Errorf("chat should be scrolled to the match, not the bottom:\n%s", view)
+	}
+}
+
+func runes(s string) []tea.KeyMsg {
+	var keys []tea.KeyMsg
+	for _, r := range s {
+		keys = append(keys, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return keys
+}
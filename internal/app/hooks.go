@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/infra/hookserver"
+)
+
+// hookEvent shows live activity from sessions Copilot CLI runs in other
+// terminals. Sessions resumed here already stream their events via the SDK.
+func (m *Model) hookEvent(sessionID string, h *copilot.HookEvent) tea.Cmd {
+	id := sessionID
+	if id == "" {
+		id = m.sessionForCWD(h.CWD)
+	}
+
+	var cmds []tea.Cmd
+	if h.Name == hookserver.SessionStart || id == "" {
+		// A session we do not list yet
+		cmds = append(cmds, sdkListSessions(m.backend))
+	}
+	if h.Denied {
+		m.statusFlash = fmt.Sprintf("🚫 Denied %s in %s: %s", h.ToolName, m.sessionName(id, h.CWD), h.DenyReason)
+		cmds = append(cmds, clearFlashAfter(5*time.Second))
+	}
+	if id == "" || m.sdkResumed[id] {
+		return tea.Batch(cmds...)
+	}
+
+	m.lastSeen[id] = time.Now()
+	m.sidebar.SetLastSeen(m.lastSeen)
+	switch h.Name {
+	case hookserver.UserPromptSubmitted, hookserver.PostToolUse:
+		m.activity[id] = "💭 working"
+	case hookserver.PreToolUse:
+		m.activity[id] = "⚙ " + h.ToolName
+		if h.Denied {
+			m.activity[id] = "🚫 " + h.ToolName + " denied"
+		}
+	case hookserver.SessionEnd:
+		delete(m.activity, id)
+	case hookserver.ErrorOccurred:
+		m.statusFlash = fmt.Sprintf("⚠️  %s: %s", m.sessionName(id, h.CWD), h.Error)
+		cmds = append(cmds, clearFlashAfter(5*time.Second))
+	}
+	m.sidebar.SetActivity(m.activity)
+	m.sidebar.SetItems(m.sessions)
+	return tea.Batch(cmds...)
+}
+
+// sessionForCWD picks the session a hook without a session ID came from:
+// the most recently updated one in that directory that is not resumed here.
+func (m Model) sessionForCWD(cwd string) string {
+	if cwd == "" {
+		return ""
+	}
+	id := ""
+	var latest time.Time
+	for _, s := range m.sessions {
+		if s.CWD != cwd || m.sdkResumed[s.ID] {
+			continue
+		}
+		if id == "" || s.UpdatedAt.After(latest) {
+			id, latest = s.ID, s.UpdatedAt
+		}
+	}
+	return id
+}
+
+// sessionName names a session for the status bar.
+func (m Model) sessionName(id, cwd string) string {
+	for _, s := range m.sessions {
+		if s.ID == id {
+			return s.DisplayName()
+		}
+	}
+	return cwd
+}
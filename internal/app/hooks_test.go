@@ -0,0 +1,39 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/infra/hookserver"
+)
+
+func TestHookActivityFromOtherTerminals(t *testing.T) {
+	m := NewModel("", nil, nil, nil)
+	now := time.Now()
+	m.sessions = []domain.Session{
+		{ID: "old", CWD: "/repo", UpdatedAt: now.Add(-time.Hour)},
+		{ID: "new", CWD: "/repo", UpdatedAt: now},
+		{ID: "mine", CWD: "/repo", UpdatedAt: now.Add(time.Minute)},
+	}
+	m.sdkResumed["mine"] = true
+
+	m.hookEvent("", &copilot.HookEvent{Name: hookserver.PreToolUse, CWD: "/repo", ToolName: "bash"})
+	if got := m.activity["new"]; got != "⚙ bash" {
+		t.Errorf("activity = %q, want the latest session not resumed here", got)
+	}
+	if _, ok := m.activity["mine"]; ok {
+		t.Error("sessions resumed in the TUI get their activity from the SDK")
+	}
+
+	m.hookEvent("new", &copilot.HookEvent{Name: hookserver.SessionEnd})
+	if _, ok := m.activity["new"]; ok {
+		t.Error("sessionEnd should clear the activity")
+	}
+
+	m.hookEvent("", &copilot.HookEvent{Name: hookserver.PreToolUse, CWD: "/elsewhere", ToolName: "bash", Denied: true, DenyReason: "no"})
+	if m.statusFlash == "" {
+		t.Error("a denial should be flashed even for an unknown session")
+	}
+}
@@ -41,12 +41,35 @@ Path string
 Err  error
 }
 
+// IndexSyncedMsg is sent when the search index has caught up with the
+// session files.
+type IndexSyncedMsg struct {
+Err error
+}
+
+// StateSavedMsg is sent when the local session state has been written.
+type StateSavedMsg struct {
+Err error
+}
+
+// NotifyFailedMsg is sent when a notification could not be delivered, e.g.
+// because a push service rejected it.
+type NotifyFailedMsg struct {
+Err error
+}
+
+// EditorFinishedMsg is sent when the external editor exits.
+type EditorFinishedMsg struct {
+Text string
+Err  error
+}
+
 // ClearFlashMsg clears the transient status bar message.
 type ClearFlashMsg struct{}
 
 // --- SDK messages ---
 
-// SDKConnectedMsg is sent when the SDK adapter connects to Copilot CLI.
+// SDKConnectedMsg is sent when the backend has started.
 type SDKConnectedMsg struct {
 Err error
 }
@@ -54,13 +77,39 @@ Err error
 // SDKSessionResumedMsg is sent when a session has been resumed via the SDK.
 type SDKSessionResumedMsg struct {
 SessionID string
+Model     string // model the session is using, if known
+Err       error
+}
+
+// ModelsLoadedMsg is sent when the list of available models arrives.
+type ModelsLoadedMsg struct {
+Models []copilot.ModelInfo
+Err    error
+}
+
+// ModelSwitchedMsg is sent when a session's model has been switched.
+type ModelSwitchedMsg struct {
+SessionID string
+Model     string
+Err       error
+}
+
+// SDKSessionCreatedMsg is sent when a new session has been created via the SDK.
+type SDKSessionCreatedMsg struct {
+Session domain.Session
+Err     error
+}
+
+// SDKSessionDeletedMsg is sent when a session has been deleted via the SDK.
+type SDKSessionDeletedMsg struct {
+SessionID string
 Err       error
 }
 
-// SDKEventMsg wraps an event from the SDK adapter's Events channel.
+// SDKEventMsg wraps an event from the backend's Events channel.
 type SDKEventMsg struct {
 Event copilot.Event
 }
 
-// SDKDisconnectedMsg is sent when the SDK adapter's Events channel closes.
+// SDKDisconnectedMsg is sent when the backend's Events channel closes.
 type SDKDisconnectedMsg struct{}
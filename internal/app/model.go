@@ -7,8 +7,20 @@ tea "github.com/charmbracelet/bubbletea"
 "github.com/e-9/copilot-icq/internal/config"
 "github.com/e-9/copilot-icq/internal/copilot"
 "github.com/e-9/copilot-icq/internal/domain"
+"github.com/e-9/copilot-icq/internal/infra/notifier"
+"github.com/e-9/copilot-icq/internal/infra/webhook"
+"github.com/e-9/copilot-icq/internal/searchindex"
+"github.com/e-9/copilot-icq/internal/state"
+"github.com/e-9/copilot-icq/internal/ui/broadcast"
 "github.com/e-9/copilot-icq/internal/ui/chat"
+"github.com/e-9/copilot-icq/internal/ui/diffview"
+"github.com/e-9/copilot-icq/internal/ui/finder"
 "github.com/e-9/copilot-icq/internal/ui/input"
+"github.com/e-9/copilot-icq/internal/ui/modelpicker"
+"github.com/e-9/copilot-icq/internal/ui/newsession"
+"github.com/e-9/copilot-icq/internal/ui/pager"
+"github.com/e-9/copilot-icq/internal/ui/queue"
+"github.com/e-9/copilot-icq/internal/ui/prompt"
 "github.com/e-9/copilot-icq/internal/ui/sidebar"
 "github.com/e-9/copilot-icq/internal/ui/theme"
 )
@@ -35,14 +47,42 @@ sessions []domain.Session
 selected *domain.Session
 unread   map[string]int           // sessionID → unread count
 lastSeen map[string]time.Time     // sessionID → last update time
+activity map[string]string        // sessionID → what a session in another terminal is doing, from hooks
 err      error
 showHelp        bool              // keyboard shortcuts overlay
 renaming        bool              // inline session rename mode
 statusFlash     string            // transient status bar message
 pendingSends    map[string]bool          // sessionID → has in-flight request
 pendingTools    map[string][]PendingTool // sessionID → tools awaiting execution
+approvals       map[string][]*copilot.PermissionEvent // sessionID → permission requests awaiting an answer
+approval        *prompt.Approval         // dialog for the selected session's oldest permission request
+approvalHead    *copilot.PermissionEvent // request the approval dialog is showing
+questions       map[string][]*copilot.UserInputEvent // sessionID → ask_user questions awaiting an answer
+question        *prompt.Question         // dialog for the selected session's oldest question
+questionHead    *copilot.UserInputEvent  // question the dialog is showing
+newSession      *newsession.Form         // new-session form, while open
+confirmDelete   *domain.Session          // session awaiting delete confirmation
+modelPicker     *modelpicker.Picker      // model list, while open
+modelTarget     *domain.Session          // session the model picker is for
+queued          map[string][]string      // sessionID → prompts to send once the session is idle
+queueList       queue.Model              // the selected session's queue, above the composer
+editingQueued   int                      // index of the queued prompt in the composer, or -1
+editBackup      string                   // composer text set aside while editing a queued prompt
+broadcast       *broadcast.Model         // broadcast composer or progress, until it finishes and is closed
+finder          *finder.Model            // search over every session, while open
+pager           *pager.Model             // selected tool call or message in full, while open
+diffView        *diffview.Model          // a tool call's patch or the session's file changes, while open
+openAt          *openAt                  // search result to show once its history loads
+index           *searchindex.Index       // full-text index of every session's history
+indexing        bool                     // a sync of the index is running
+indexed         bool                     // the index has been synced at least once
+indexErr        error                    // why the last sync skipped sessions
+store           *state.Store             // local state: archived sessions
 cfg             *config.AppConfig
-adapter         *copilot.Adapter
+backend         copilot.Backend          // SDK adapter, or the read-only session file reader
+notifier        notifier.Notifier        // desktop notifications; nil when disabled
+webhooks        *webhook.Dispatcher      // outgoing webhooks; nil when none are configured
+lastReply       map[string]string        // sessionID → reply of the running turn, for the idle notification
 sdkResumed      map[string]bool
 sessionBasePath string           // path to session-state directory
 }
@@ -51,31 +91,49 @@ sessionBasePath string           // path to session-state directory
 type PendingTool struct {
 ToolName   string
 ToolArgs   string
+ToolCallID string
 Denied     bool
 DenyReason string
 }
 
 // NewModel creates the initial application model.
-func NewModel(sessionBasePath string, cfg *config.AppConfig, adapter *copilot.Adapter) Model {
+// A nil store keeps local state in memory only.
+func NewModel(sessionBasePath string, cfg *config.AppConfig, backend copilot.Backend, st *state.Store) Model {
+if st == nil {
+st, _ = state.Load("")
+}
+sb := sidebar.New(nil, theme.SidebarWidth, 20)
+sb.SetArchived(st.Archived())
+sb.SetDrafts(st.DraftIDs())
+sb.SetMuted(st.Muted())
 return Model{
-sidebar:         sidebar.New(nil, theme.SidebarWidth, 20),
+sidebar:         sb,
 chat:            chat.New(80, 20),
 input:           input.New(80),
 unread:          make(map[string]int),
 lastSeen:        make(map[string]time.Time),
+activity:        make(map[string]string),
 pendingSends:    make(map[string]bool),
 pendingTools:    make(map[string][]PendingTool),
+queued:          make(map[string][]string),
+queueList:       queue.New(),
+editingQueued:   -1,
+approvals:       make(map[string][]*copilot.PermissionEvent),
+questions:       make(map[string][]*copilot.UserInputEvent),
 cfg:             cfg,
-adapter:         adapter,
+backend:         backend,
+lastReply:       make(map[string]string),
 sdkResumed:      make(map[string]bool),
 sessionBasePath: sessionBasePath,
+store:           st,
+index:           searchindex.Open(""),
 }
 }
 
 func (m Model) Init() tea.Cmd {
 return tea.Batch(
-sdkStart(m.adapter),
-listenSDKEvents(m.adapter),
+sdkStart(m.backend),
+listenSDKEvents(m.backend),
 tickEvery(5*time.Second),
 )
 }
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/ui/modelpicker"
+)
+
+// openModelPicker lists the available models for a session. Picking one
+// switches a resumed session, or resumes the session with that model.
+func (m *Model) openModelPicker(s *domain.Session) tea.Cmd {
+	if s == nil {
+		return nil
+	}
+	target := *s
+	p := modelpicker.New(target.Model, m.chat.Width(), m.panelHeight())
+	m.modelPicker = &p
+	m.modelTarget = &target
+	m.input.Blur()
+	return sdkListModels(m.backend)
+}
+
+// updateModelPicker routes a key to the picker and applies the choice.
+func (m *Model) updateModelPicker(msg tea.KeyMsg) tea.Cmd {
+	p, cmd := m.modelPicker.Update(msg)
+	m.modelPicker = &p
+
+	if p.Cancelled() {
+		m.closeModelPicker()
+		return nil
+	}
+	model, ok := p.Chosen()
+	if !ok {
+		return cmd
+	}
+
+	target := m.modelTarget
+	m.closeModelPicker()
+	if m.sdkResumed[target.ID] {
+		m.statusFlash = fmt.Sprintf("🧠 Switching %s to %s...", target.DisplayName(), model)
+		return sdkSwitchModel(m.backend, target.ID, model)
+	}
+	m.focus = FocusChat
+	return m.selectSession(target, model)
+}
+
+func (m *Model) closeModelPicker() {
+	m.modelPicker = nil
+	m.modelTarget = nil
+}
+
+// modelSwitched records the outcome of a model switch.
+func (m *Model) modelSwitched(msg ModelSwitchedMsg) tea.Cmd {
+	if msg.Err != nil {
+		m.statusFlash = fmt.Sprintf("⚠️  Model switch failed: %v", msg.Err)
+		return clearFlashAfter(5 * time.Second)
+	}
+	m.setSessionModel(msg.SessionID, msg.Model)
+	m.statusFlash = "🧠 Now using " + msg.Model
+	return clearFlashAfter(3 * time.Second)
+}
+
+// setSessionModel records the model a session reported.
+func (m *Model) setSessionModel(sessionID, model string) {
+	if model == "" {
+		return
+	}
+	for i := range m.sessions {
+		if m.sessions[i].ID == sessionID {
+			m.sessions[i].Model = model
+		}
+	}
+	if m.selected != nil && m.selected.ID == sessionID {
+		m.selected.Model = model
+	}
+	m.sidebar.SetItems(m.sessions)
+}
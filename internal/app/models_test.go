@@ -0,0 +1,42 @@
+package app
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/e-9/copilot-icq/internal/config"
+	"github.com/e-9/copilot-icq/internal/copilot/copilottest"
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+func TestResumeUsesDirectoryModel(t *testing.T) {
+	fake := copilottest.New(domain.Session{ID: "api", CWD: "/src/api/cmd"}, domain.Session{ID: "web", CWD: "/src/web"})
+	fake.Model = "cli-default"
+	cfg := &config.AppConfig{Projects: []config.ProjectConfig{{Path: "/src/api", Model: "claude-sonnet-4.5"}}}
+	m := NewModel("", cfg, fake, nil)
+	m.sessions = fake.Sessions
+	m.sidebar.SetItems(m.sessions)
+
+	tests := []struct {
+		keys []tea.KeyMsg
+		want string
+	}{
+		{[]tea.KeyMsg{{Type: tea.KeyEnter}}, "claude-sonnet-4.5"},
+		// Nothing is configured for web, so it keeps its own model
+		{[]tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyEnter}}, "cli-default"},
+	}
+	for _, tt := range tests {
+		m.focus = FocusSidebar
+		var cmd tea.Cmd
+		for _, k := range tt.keys {
+			var model tea.Model
+			model, cmd = m.Update(k)
+			m = model.(Model)
+		}
+		msg, ok := cmd().(SDKSessionResumedMsg)
+		if !ok || msg.Model != tt.want {
+			t.Errorf("resumed %s with %+v, want model %q", m.selected.ID, msg, tt.want)
+		}
+	}
+}
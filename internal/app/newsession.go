@@ -0,0 +1,85 @@
+package app
+
+import (
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/ui/newsession"
+)
+
+// openNewSession shows the new-session form, prefilled with the working
+// directory of the highlighted session or, failing that, the current one.
+func (m *Model) openNewSession() tea.Cmd {
+	cwd := ""
+	if s := m.sidebar.SelectedSession(); s != nil {
+		cwd = s.CWD
+	}
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+	f := newsession.New(cwd, m.cfg.ModelFor(cwd), m.chat.Width())
+	m.newSession = &f
+	m.input.Blur()
+	return nil
+}
+
+// updateNewSession routes a key to the form and starts the session once
+// the form is submitted.
+func (m *Model) updateNewSession(msg tea.KeyMsg) tea.Cmd {
+	f, cmd := m.newSession.Update(msg)
+	m.newSession = &f
+
+	if f.Cancelled() {
+		m.newSession = nil
+		return nil
+	}
+	if res, ok := f.Submitted(); ok {
+		m.newSession.SetBusy(true)
+		return sdkCreateSession(m.backend, copilot.CreateSessionOptions{
+			CWD:          res.CWD,
+			Model:        res.Model,
+			SystemPrompt: res.SystemPrompt,
+		})
+	}
+	return cmd
+}
+
+// sessionCreated selects a freshly created session and focuses the input.
+// On failure the form stays open with the error so the user can fix it.
+func (m *Model) sessionCreated(msg SDKSessionCreatedMsg) tea.Cmd {
+	if msg.Err != nil {
+		if m.newSession != nil {
+			m.newSession.SetError(msg.Err)
+		}
+		return nil
+	}
+	m.newSession = nil
+
+	s := msg.Session
+	m.sdkResumed[s.ID] = true
+	m.sessions = append(m.sessions, s)
+	m.chat.SetMessages(nil)
+
+	cmd := m.selectSession(&s, "")
+	m.focus = FocusInput
+	m.input.Focus()
+	// The session list picks up the summary the CLI assigns later.
+	return tea.Batch(cmd, sdkListSessions(m.backend))
+}
+
+// withResumed adds sessions we are subscribed to but the CLI does not list
+// yet, such as a new session before its first message, to a fresh listing.
+func (m Model) withResumed(listed []domain.Session) []domain.Session {
+	seen := make(map[string]bool, len(listed))
+	for _, s := range listed {
+		seen[s.ID] = true
+	}
+	for _, s := range m.sessions {
+		if m.sdkResumed[s.ID] && !seen[s.ID] {
+			listed = append(listed, s)
+		}
+	}
+	return listed
+}
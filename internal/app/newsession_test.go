@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+func TestSessionCreatedSelectsAndKeepsSession(t *testing.T) {
+	m := NewModel("", nil, nil, nil)
+	m.sessions = []domain.Session{{ID: "old"}}
+	m.openNewSession()
+	if m.newSession == nil {
+		t.Fatal("expected the new-session form to open")
+	}
+
+	m.sessionCreated(SDKSessionCreatedMsg{Session: domain.Session{ID: "new", CWD: "/tmp"}})
+
+	if m.newSession != nil {
+		t.Error("form should close once the session exists")
+	}
+	if m.selected == nil || m.selected.ID != "new" {
+		t.Fatalf("selected = %+v, want new session", m.selected)
+	}
+	if m.focus != FocusInput || !m.sdkResumed["new"] {
+		t.Errorf("focus = %v, resumed = %v; want input focus on a resumed session", m.focus, m.sdkResumed["new"])
+	}
+
+	// The CLI may not list the session until its first message.
+	got := m.withResumed([]domain.Session{{ID: "old"}})
+	if len(got) != 2 || got[1].ID != "new" {
+		t.Errorf("withResumed = %+v, want old and new", got)
+	}
+}
+
+func TestNewSessionFormSubmitsOnce(t *testing.T) {
+	m, fake := resumedModel(t)
+	m.focus = FocusSidebar
+	var cmds []tea.Cmd
+	for _, k := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("n")}, {Type: tea.KeyEnter}, {Type: tea.KeyRunes, Runes: []rune("x")}, {Type: tea.KeyEsc}} {
+		model, cmd := m.Update(k)
+		m = model.(Model)
+		cmds = append(cmds, cmd)
+	}
+	for _, cmd := range cmds {
+		if cmd != nil {
+			cmd()
+		}
+	}
+	if sessions, _ := fake.ListSessions(context.Background()); len(sessions) != 3 {
+		t.Errorf("backend has %d sessions, want one created after the two it started with", len(sessions))
+	}
+	if m.newSession == nil {
+		t.Error("the form should stay open until the session exists")
+	}
+}
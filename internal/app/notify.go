@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/infra/notifier"
+)
+
+// SetNotifier sets where notifications about background sessions go; nil
+// turns them off.
+func (m *Model) SetNotifier(n notifier.Notifier) {
+	m.notifier = n
+}
+
+// notifyEvent tells the user when an event means a session they are not
+// looking at needs attention. Muted sessions stay quiet. Like
+// publishEvent, it runs before the event is applied.
+func (m *Model) notifyEvent(evt copilot.Event) tea.Cmd {
+	id := evt.SessionID
+	if m.notifier == nil || (m.selected != nil && m.selected.ID == id) || m.store.IsMuted(id) {
+		return nil
+	}
+	n, ok := notifier.For(evt, m.lastReply[id])
+	if !ok {
+		return nil
+	}
+	n.Title = m.sessionName(id, id)
+	send := m.notifier
+	return func() tea.Msg {
+		if err := send.Notify(n); err != nil {
+			return NotifyFailedMsg{Err: err}
+		}
+		return nil
+	}
+}
+
+// watcher is a backend shared through the daemon, which notifies for its
+// TUIs and skips the sessions they show.
+type watcher interface {
+	Watch(ctx context.Context, sessionID string) error
+}
+
+// watchSession tells the daemon, if there is one, which session is on
+// screen. A failure only costs a needless notification, so it is ignored.
+func watchSession(b copilot.Backend, id string) tea.Cmd {
+	w, ok := b.(watcher)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		w.Watch(context.Background(), id)
+		return nil
+	}
+}
+
+// toggleMuted mutes or unmutes notifications for the session under the
+// sidebar cursor.
+func (m *Model) toggleMuted() tea.Cmd {
+	s := m.sidebar.SelectedSession()
+	if s == nil {
+		return nil
+	}
+	muted := !m.store.IsMuted(s.ID)
+	m.store.SetMuted(s.ID, muted)
+	m.sidebar.SetMuted(m.store.Muted())
+	m.sidebar.SetItems(m.sessions)
+
+	if muted {
+		m.statusFlash = fmt.Sprintf("🔕 Muted %s", s.DisplayName())
+	} else {
+		m.statusFlash = fmt.Sprintf("🔔 Unmuted %s", s.DisplayName())
+	}
+	return tea.Batch(saveState(m.store), clearFlashAfter(3*time.Second))
+}
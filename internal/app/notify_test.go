@@ -0,0 +1,96 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/infra/notifier"
+)
+
+type recordNotifier struct {
+	got []notifier.Notification
+	err error
+}
+
+func (r *recordNotifier) Notify(n notifier.Notification) error {
+	r.got = append(r.got, n)
+	return r.err
+}
+
+func runAll(cmds []tea.Cmd) {
+	for _, c := range cmds {
+		if c != nil {
+			c()
+		}
+	}
+}
+
+// deliver applies a session event the way Update does, running the
+// notification it calls for.
+func deliver(m *Model, id string, e sdk.SessionEvent) {
+	if cmd := m.notifyEvent(copilot.Event{Type: copilot.EventSession, SessionID: id, SessionEvent: &e}); cmd != nil {
+		cmd()
+	}
+	var cmds []tea.Cmd
+	m.handleSDKSessionEvent(id, e, &cmds)
+}
+
+func failure(id string) copilot.Event {
+	return copilot.Event{Type: copilot.EventSession, SessionID: id,
+		SessionEvent: &sdk.SessionEvent{Type: sdk.SessionError, Data: sdk.Data{Message: str("boom")}}}
+}
+
+func TestNotifiesForBackgroundSessions(t *testing.T) {
+	m, _ := resumedModel(t)
+	rec := &recordNotifier{}
+	m.notifier = rec
+
+	// A reply in the selected session needs no notification
+	deliver(&m, "a", sdk.SessionEvent{Type: sdk.AssistantMessage, Data: sdk.Data{Content: str("done")}})
+	deliver(&m, "a", sdk.SessionEvent{Type: sdk.SessionIdle})
+	if len(rec.got) != 0 {
+		t.Fatalf("notified %+v for the selected session", rec.got)
+	}
+
+	deliver(&m, "b", sdk.SessionEvent{Type: sdk.AssistantMessage, Data: sdk.Data{Content: str("All\ntests pass")}})
+	deliver(&m, "b", sdk.SessionEvent{Type: sdk.SessionIdle})
+	deliver(&m, "b", sdk.SessionEvent{Type: sdk.SessionIdle})
+	if len(rec.got) != 1 || rec.got[0].Kind != notifier.Reply || rec.got[0].Body != "All tests pass" || rec.got[0].Title != "b" {
+		t.Fatalf("notified %+v, want one reply notification", rec.got)
+	}
+}
+
+func TestMutedSessionsStayQuiet(t *testing.T) {
+	m, _ := resumedModel(t)
+	rec := &recordNotifier{}
+	m.notifier = rec
+
+	m.store.SetMuted("b", true)
+	deliver(&m, "b", sdk.SessionEvent{Type: sdk.AssistantMessage, Data: sdk.Data{Content: str("hi")}})
+	deliver(&m, "b", sdk.SessionEvent{Type: sdk.SessionIdle})
+	if len(rec.got) != 0 {
+		t.Errorf("notified %+v for a muted session", rec.got)
+	}
+
+	m.store.SetMuted("b", false)
+	if cmd := m.notifyEvent(failure("b")); cmd == nil {
+		t.Error("an unmuted background session should notify")
+	}
+}
+
+func TestNotifyFailureIsFlashed(t *testing.T) {
+	m, _ := resumedModel(t)
+	m.notifier = &recordNotifier{err: errors.New("ntfy: 403 Forbidden")}
+
+	msg := m.notifyEvent(failure("b"))()
+	model, _ := m.Update(msg)
+	m = model.(Model)
+	if !strings.Contains(m.statusFlash, "403 Forbidden") {
+		t.Errorf("flash = %q, want the push error", m.statusFlash)
+	}
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/e-9/copilot-icq/internal/ui/pager"
+)
+
+// openPager shows the chat's selected tool call or message in full.
+func (m *Model) openPager() bool {
+	title, body, ok := m.chat.Detail(m.width)
+	if !ok {
+		return false
+	}
+	p := pager.New(title, body, m.width, m.height)
+	m.pager = &p
+	return true
+}
+
+// updatePager routes a key or mouse event to the pager and closes it when
+// the user is done.
+func (m *Model) updatePager(msg tea.Msg) tea.Cmd {
+	p, cmd := m.pager.Update(msg)
+	if p.Closed() {
+		m.pager = nil
+		return nil
+	}
+	m.pager = &p
+	return cmd
+}
+
+// resizePager fits the pager to the screen and re-wraps its text.
+func (m *Model) resizePager() {
+	if m.pager == nil {
+		return
+	}
+	m.pager.SetSize(m.width, m.height)
+	if _, body, ok := m.chat.Detail(m.width); ok {
+		m.pager.SetContent(body)
+	}
+}
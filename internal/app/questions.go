@@ -0,0 +1,136 @@
+package app
+
+import (
+	"time"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/ui/prompt"
+)
+
+// queueQuestion records an ask_user request until the user answers it and
+// shows it in the transcript as a pending ask_user tool call.
+func (m *Model) queueQuestion(sessionID string, q *copilot.UserInputEvent) {
+	m.questions[sessionID] = append(m.questions[sessionID], q)
+	m.sidebar.SetApprovals(m.approvalCounts())
+
+	if m.selected != nil && m.selected.ID == sessionID {
+		m.recordQuestion(q, nil)
+		if m.focus == FocusInput && !m.hasDialog() {
+			m.focus = FocusChat
+			m.input.Blur()
+		}
+		m.syncQuestion()
+	}
+}
+
+// answerQuestion sends the user's answer for the selected session's oldest
+// question and advances the dialog to the next one.
+func (m *Model) answerQuestion(res prompt.QuestionResult) {
+	if m.selected == nil {
+		return
+	}
+	sessionID := m.selected.ID
+	queue := m.questions[sessionID]
+	if len(queue) == 0 {
+		return
+	}
+
+	head := queue[0]
+	head.Response <- copilot.UserInputResponse{Answer: res.Answer, WasFreeform: res.WasFreeform}
+	m.recordQuestion(head, &res.Answer)
+
+	if len(queue) == 1 {
+		delete(m.questions, sessionID)
+	} else {
+		m.questions[sessionID] = queue[1:]
+	}
+	m.sidebar.SetApprovals(m.approvalCounts())
+	m.syncQuestion()
+}
+
+// questionResolved drops a question answered elsewhere, e.g. in another TUI
+// attached to the same daemon, closing its dialog here.
+func (m *Model) questionResolved(sessionID string, r *copilot.ResolvedEvent) {
+	queue := m.questions[sessionID]
+	for i, q := range queue {
+		if q.RequestID == "" || q.RequestID != r.RequestID {
+			continue
+		}
+		if queue = append(queue[:i:i], queue[i+1:]...); len(queue) == 0 {
+			delete(m.questions, sessionID)
+		} else {
+			m.questions[sessionID] = queue
+		}
+		m.sidebar.SetApprovals(m.approvalCounts())
+		if m.selected != nil && m.selected.ID == sessionID {
+			m.recordQuestion(q, &r.Answer)
+			m.syncQuestion()
+		}
+		return
+	}
+}
+
+// recordQuestion adds the question to the selected session's transcript as an
+// ask_user tool call, or completes the pending one once it has an answer.
+func (m *Model) recordQuestion(q *copilot.UserInputEvent, answer *string) {
+	msgs := m.chat.Messages()
+	for i := len(msgs) - 1; i >= 0; i-- {
+		for j, tc := range msgs[i].ToolCalls {
+			if tc.Name == "ask_user" && tc.Question == q.Question && tc.Status == domain.ToolCallPending {
+				if answer != nil {
+					msgs[i].ToolCalls[j].Status = domain.ToolCallComplete
+					msgs[i].ToolCalls[j].Summary = *answer
+					m.chat.SetMessages(msgs)
+				}
+				return
+			}
+		}
+	}
+
+	tc := domain.ToolCall{
+		Name:     "ask_user",
+		Status:   domain.ToolCallPending,
+		Question: q.Question,
+		Choices:  q.Choices,
+	}
+	if answer != nil {
+		tc.Status = domain.ToolCallComplete
+		tc.Summary = *answer
+	}
+	msgs = append(msgs, domain.Message{
+		Role:      domain.RoleAssistant,
+		Timestamp: time.Now(),
+		ToolCalls: []domain.ToolCall{tc},
+	})
+	m.chat.SetMessages(msgs)
+}
+
+// syncQuestion shows, refreshes or hides the ask_user dialog to match the
+// selected session's queue, then re-lays out the right panel.
+func (m *Model) syncQuestion() {
+	var queue []*copilot.UserInputEvent
+	if m.selected != nil {
+		queue = m.questions[m.selected.ID]
+	}
+
+	if len(queue) == 0 {
+		m.question = nil
+		m.questionHead = nil
+	} else {
+		head := queue[0]
+		if m.question == nil || m.questionHead != head {
+			req := prompt.QuestionRequest{
+				Question:      head.Question,
+				Choices:       head.Choices,
+				AllowFreeform: head.AllowFreeform,
+			}
+			q := prompt.NewQuestion(req, m.chat.Width())
+			m.question = &q
+			m.questionHead = head
+		}
+		m.question.SetQueued(len(queue) - 1)
+	}
+	m.settleDialogFocus()
+	m.resize()
+}
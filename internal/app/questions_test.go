@@ -0,0 +1,45 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/ui/prompt"
+)
+
+func TestAnswerQuestionRecordsTranscript(t *testing.T) {
+	m := NewModel("", nil, nil, nil)
+	m.selected = &domain.Session{ID: "s1"}
+
+	resp := make(chan copilot.UserInputResponse, 1)
+	m.queueQuestion("s1", &copilot.UserInputEvent{
+		Question: "Which framework?",
+		Choices:  []string{"Jest", "Vitest"},
+		Response: resp,
+	})
+	if m.question == nil {
+		t.Fatal("expected question dialog for selected session")
+	}
+
+	m.answerQuestion(prompt.QuestionResult{Answer: "Vitest"})
+
+	if r := <-resp; r.Answer != "Vitest" || r.WasFreeform {
+		t.Errorf("response = %+v, want Vitest", r)
+	}
+	if m.question != nil {
+		t.Error("dialog should close once the queue is empty")
+	}
+
+	msgs := m.chat.Messages()
+	if len(msgs) != 1 || len(msgs[0].ToolCalls) != 1 {
+		t.Fatalf("transcript = %+v, want one ask_user tool call", msgs)
+	}
+	tc := msgs[0].ToolCalls[0]
+	if tc.Name != "ask_user" || tc.Question != "Which framework?" || len(tc.Choices) != 2 {
+		t.Errorf("tool call = %+v", tc)
+	}
+	if tc.Status != domain.ToolCallComplete || tc.Summary != "Vitest" {
+		t.Errorf("status/summary = %s/%q, want complete/Vitest", tc.Status, tc.Summary)
+	}
+}
@@ -0,0 +1,159 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/e-9/copilot-icq/internal/ui/queue"
+)
+
+// dispatch sends a prompt to a session and marks it busy until SessionIdle.
+func (m *Model) dispatch(id, text string) tea.Cmd {
+	m.pendingSends[id] = true
+	m.sidebar.SetPendingSends(m.pendingSends)
+	if m.selected != nil && m.selected.ID == id {
+		m.input.SetSending(true)
+		m.resize()
+	}
+	return sdkSendMessage(m.backend, id, text)
+}
+
+// sendNextQueued sends the first queued prompt once the session is idle. The
+// queue is paused while the user is editing one of its prompts, and after a
+// failed send until the user sends the session another prompt.
+func (m *Model) sendNextQueued(id string) tea.Cmd {
+	if m.pendingSends[id] || len(m.queued[id]) == 0 || m.editingQueuedIn(id) {
+		return nil
+	}
+	text := m.queued[id][0]
+	m.removeQueued(id, 0)
+	return m.dispatch(id, text)
+}
+
+// editingQueuedIn reports whether the composer holds a queued prompt of id.
+func (m Model) editingQueuedIn(id string) bool {
+	return m.editingQueued >= 0 && m.selected != nil && m.selected.ID == id
+}
+
+// removeQueued drops the i-th queued prompt of a session. If it was being
+// edited, the edit is abandoned.
+func (m *Model) removeQueued(id string, i int) {
+	q := m.queued[id]
+	if i < 0 || i >= len(q) {
+		return
+	}
+	if m.editingQueuedIn(id) {
+		switch {
+		case m.editingQueued == i:
+			m.stopEditingQueued()
+		case m.editingQueued > i:
+			m.editingQueued--
+		}
+	}
+	q = append(q[:i:i], q[i+1:]...)
+	if len(q) == 0 {
+		delete(m.queued, id)
+	} else {
+		m.queued[id] = q
+	}
+	m.syncQueue()
+}
+
+// cancelNextQueued drops the prompt that would be sent next.
+func (m *Model) cancelNextQueued() tea.Cmd {
+	if m.selected == nil || len(m.queued[m.selected.ID]) == 0 {
+		return nil
+	}
+	m.removeQueued(m.selected.ID, 0)
+	m.statusFlash = "⏭ Cancelled the next queued prompt"
+	return clearFlashAfter(3 * time.Second)
+}
+
+// editQueued loads a queued prompt into the composer. Whatever was being
+// typed is set aside and comes back once the edit is saved or abandoned.
+func (m *Model) editQueued(i int) {
+	if m.selected == nil || i < 0 || i >= len(m.queued[m.selected.ID]) {
+		return
+	}
+	if m.editingQueued < 0 {
+		m.editBackup = m.input.Value()
+	}
+	m.editingQueued = i
+	m.input.SetValue(m.queued[m.selected.ID][i])
+}
+
+// stopEditingQueued leaves the queued prompt unchanged and puts back what
+// the user was typing before.
+func (m *Model) stopEditingQueued() {
+	if m.editingQueued < 0 {
+		return
+	}
+	m.editingQueued = -1
+	m.input.SetValue(m.editBackup)
+	m.editBackup = ""
+}
+
+// moveQueued swaps the i-th queued prompt with its neighbour at i+delta.
+func (m *Model) moveQueued(i, delta int) {
+	if m.selected == nil {
+		return
+	}
+	q := m.queued[m.selected.ID]
+	j := i + delta
+	if i < 0 || j < 0 || i >= len(q) || j >= len(q) {
+		return
+	}
+	q[i], q[j] = q[j], q[i]
+	switch m.editingQueued {
+	case i:
+		m.editingQueued = j
+	case j:
+		m.editingQueued = i
+	}
+	m.syncQueue()
+	m.queueList.Select(j)
+}
+
+// applyQueueAction carries out what the user chose in the queue list.
+func (m *Model) applyQueueAction(a queue.Action) {
+	if m.selected == nil {
+		return
+	}
+	i := m.queueList.Cursor()
+	switch a {
+	case queue.Edit:
+		m.editQueued(i)
+		m.queueList.Blur()
+		m.input.Focus()
+	case queue.Delete:
+		m.removeQueued(m.selected.ID, i)
+	case queue.MoveUp:
+		m.moveQueued(i, -1)
+	case queue.MoveDown:
+		m.moveQueued(i, 1)
+	case queue.Close:
+		m.queueList.Blur()
+		m.input.Focus()
+	}
+	if !m.queueList.Focused() && m.focus == FocusInput {
+		m.input.Focus()
+	}
+	m.resize()
+}
+
+// syncQueue shows the selected session's queue above the composer and the
+// per-session counts in the sidebar.
+func (m *Model) syncQueue() {
+	var items []string
+	if m.selected != nil {
+		items = m.queued[m.selected.ID]
+	}
+	m.queueList.SetItems(items)
+
+	counts := make(map[string]int, len(m.queued))
+	for id, q := range m.queued {
+		counts[id] = len(q)
+	}
+	m.sidebar.SetQueued(counts)
+	m.resize()
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/ui/queue"
+)
+
+func busySession(t *testing.T) Model {
+	t.Helper()
+	m := NewModel("", nil, nil, nil)
+	a := &domain.Session{ID: "a"}
+	m.sessions = []domain.Session{*a}
+	m.selectSession(a, "")
+	m.sdkResumed["a"] = true
+	if m.sendPrompt("first") == nil {
+		t.Fatal("an idle session should send right away")
+	}
+	return m
+}
+
+func TestPromptsQueueWhileBusy(t *testing.T) {
+	m := busySession(t)
+
+	if cmd := m.sendPrompt("second"); cmd != nil {
+		t.Error("a busy session should queue, not send")
+	}
+	m.sendPrompt("third")
+	if got := m.queued["a"]; !reflect.DeepEqual(got, []string{"second", "third"}) {
+		t.Fatalf("queue = %q", got)
+	}
+
+	var cmds []tea.Cmd
+	m.handleSDKSessionEvent("a", sdk.SessionEvent{Type: sdk.SessionIdle}, &cmds)
+	if !m.pendingSends["a"] {
+		t.Error("the next queued prompt should be sent on idle")
+	}
+	if got := m.queued["a"]; !reflect.DeepEqual(got, []string{"third"}) {
+		t.Errorf("queue after idle = %q", got)
+	}
+}
+
+func TestCancelNextQueued(t *testing.T) {
+	m := busySession(t)
+	m.sendPrompt("second")
+	m.sendPrompt("third")
+
+	m.cancelNextQueued()
+	if got := m.queued["a"]; !reflect.DeepEqual(got, []string{"third"}) {
+		t.Errorf("queue = %q, want [third]", got)
+	}
+	m.cancelNextQueued()
+	if _, ok := m.queued["a"]; ok {
+		t.Error("an emptied queue should be dropped")
+	}
+}
+
+func TestEditQueuedPrompt(t *testing.T) {
+	m := busySession(t)
+	m.sendPrompt("second")
+	m.sendPrompt("third")
+	m.input.SetValue("half-typed")
+
+	m.editQueued(1)
+	if got := m.input.Value(); got != "third" {
+		t.Fatalf("composer = %q, want the queued prompt", got)
+	}
+
+	// The queue is paused while one of its prompts is in the composer.
+	delete(m.pendingSends, "a")
+	if m.sendNextQueued("a") != nil {
+		t.Error("queue should not drain during an edit")
+	}
+
+	m.sendPrompt("third, reworded")
+	if got := m.queued["a"]; !reflect.DeepEqual(got, []string{"third, reworded"}) {
+		t.Errorf("queue = %q", got)
+	}
+	if got := m.input.Value(); got != "half-typed" {
+		t.Errorf("composer = %q, want the text set aside", got)
+	}
+}
+
+func TestMoveQueuedPrompt(t *testing.T) {
+	m := busySession(t)
+	m.sendPrompt("second")
+	m.sendPrompt("third")
+
+	m.queueList.Focus()
+	m.queueList.Select(1)
+	m.applyQueueAction(queue.MoveUp)
+	if got := m.queued["a"]; !reflect.DeepEqual(got, []string{"third", "second"}) {
+		t.Errorf("queue = %q", got)
+	}
+	if m.queueList.Cursor() != 0 {
+		t.Error("cursor should follow the moved prompt")
+	}
+}
+
+func TestSendErrorPausesQueue(t *testing.T) {
+	m := busySession(t)
+	m.sendPrompt("second")
+	m.sendPrompt("third")
+
+	model, _ := m.Update(MessageSentMsg{SessionID: "a", Err: errors.New("connection lost")})
+	m = model.(Model)
+	if m.err != nil || !strings.Contains(m.statusFlash, "2 queued prompts paused") {
+		t.Errorf("err = %v, flash = %q; want the paused queue flashed", m.err, m.statusFlash)
+	}
+	if got := m.queued["a"]; !reflect.DeepEqual(got, []string{"second", "third"}) {
+		t.Fatalf("queue = %q, want it kept", got)
+	}
+
+	// The next prompt resumes the queue in order
+	if m.sendPrompt("fourth") == nil || !m.pendingSends["a"] {
+		t.Error("sending again should send the next queued prompt")
+	}
+	if got := m.queued["a"]; !reflect.DeepEqual(got, []string{"third", "fourth"}) {
+		t.Errorf("queue = %q", got)
+	}
+}
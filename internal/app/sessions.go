@@ -0,0 +1,118 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+// toggleArchived archives or restores the session under the sidebar cursor.
+// Archiving only hides the session locally; nothing is deleted.
+func (m *Model) toggleArchived() tea.Cmd {
+	s := m.sidebar.SelectedSession()
+	if s == nil {
+		return nil
+	}
+	archived := !m.store.IsArchived(s.ID)
+	m.store.SetArchived(s.ID, archived)
+	m.sidebar.SetArchived(m.store.Archived())
+	m.sidebar.SetDrafts(m.store.DraftIDs())
+	m.sidebar.SetItems(m.sessions)
+
+	if archived {
+		m.statusFlash = fmt.Sprintf("🗄  Archived %s (X shows archived)", s.DisplayName())
+	} else {
+		m.statusFlash = fmt.Sprintf("🗄  Restored %s", s.DisplayName())
+	}
+	return tea.Batch(saveState(m.store), clearFlashAfter(3*time.Second))
+}
+
+// toggleShowArchived lists or hides archived sessions in the sidebar.
+func (m *Model) toggleShowArchived() tea.Cmd {
+	m.sidebar.SetShowArchived(!m.sidebar.ShowArchived())
+	m.sidebar.SetItems(m.sessions)
+	if m.sidebar.ShowArchived() {
+		m.statusFlash = "🗄  Showing archived sessions"
+	} else {
+		m.statusFlash = "🗄  Hiding archived sessions"
+	}
+	return clearFlashAfter(3 * time.Second)
+}
+
+// confirmDeleteKey answers the delete confirmation: y deletes, anything else cancels.
+func (m *Model) confirmDeleteKey(msg tea.KeyMsg) tea.Cmd {
+	s := m.confirmDelete
+	m.confirmDelete = nil
+	if msg.String() != "y" && msg.String() != "Y" {
+		return nil
+	}
+	m.statusFlash = fmt.Sprintf("🗑  Deleting %s...", s.DisplayName())
+	return sdkDeleteSession(m.backend, s.ID)
+}
+
+// removeSession forgets a session that was deleted, here or elsewhere.
+// Requests it was blocked on are settled so their handlers return.
+func (m *Model) removeSession(id string) {
+	for _, p := range m.approvals[id] {
+		p.Response <- copilot.PermissionResponse{Allow: false}
+	}
+	for _, q := range m.questions[id] {
+		q.Response <- copilot.UserInputResponse{}
+	}
+	delete(m.approvals, id)
+	delete(m.questions, id)
+	delete(m.unread, id)
+	delete(m.lastSeen, id)
+	delete(m.activity, id)
+	delete(m.pendingSends, id)
+	delete(m.pendingTools, id)
+	delete(m.queued, id)
+	delete(m.lastReply, id)
+	m.broadcastFailed(id, errors.New("session deleted"))
+	delete(m.sdkResumed, id)
+	m.store.Forget(id)
+
+	var remaining []domain.Session
+	for _, s := range m.sessions {
+		if s.ID != id {
+			remaining = append(remaining, s)
+		}
+	}
+	m.sessions = remaining
+
+	if m.selected != nil && m.selected.ID == id {
+		m.editingQueued = -1
+		m.editBackup = ""
+		m.selected = nil
+		m.chat.Reset()
+		m.chat.SetMessages(nil)
+		m.chat.SetPendingTools(nil)
+		m.sidebar.SetActiveID("")
+		m.focus = FocusSidebar
+		m.input.Blur()
+		m.input.Reset()
+	}
+	m.sidebar.SetUnread(m.unread)
+	m.sidebar.SetPendingSends(m.pendingSends)
+	m.sidebar.SetApprovals(m.approvalCounts())
+	m.sidebar.SetArchived(m.store.Archived())
+	m.sidebar.SetItems(m.sessions)
+	m.syncQueue()
+	m.syncApproval()
+	m.syncQuestion()
+}
+
+// sessionDeleted handles the result of a delete the user confirmed.
+func (m *Model) sessionDeleted(msg SDKSessionDeletedMsg) tea.Cmd {
+	if msg.Err != nil {
+		m.statusFlash = fmt.Sprintf("⚠️  Delete failed: %v", msg.Err)
+		return clearFlashAfter(5 * time.Second)
+	}
+	m.removeSession(msg.SessionID)
+	m.statusFlash = "🗑  Session deleted"
+	return tea.Batch(saveState(m.store), clearFlashAfter(3*time.Second))
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+func TestRemoveSessionSettlesRequests(t *testing.T) {
+	m := NewModel("", nil, nil, nil)
+	m.sessions = []domain.Session{{ID: "s1"}, {ID: "s2"}}
+	m.selected = &domain.Session{ID: "s1"}
+	m.focus = FocusChat
+	m.store.SetArchived("s1", true)
+
+	perm := make(chan copilot.PermissionResponse, 1)
+	answer := make(chan copilot.UserInputResponse, 1)
+	m.queueApproval("s1", &copilot.PermissionEvent{ToolName: "bash", Response: perm})
+	m.queueQuestion("s1", &copilot.UserInputEvent{Question: "Which?", Response: answer})
+
+	m.removeSession("s1")
+
+	if r := <-perm; r.Allow {
+		t.Errorf("permission response = %+v, want deny", r)
+	}
+	<-answer
+	if m.selected != nil || m.focus != FocusSidebar {
+		t.Errorf("selected = %v, focus = %v; want no selection and sidebar focus", m.selected, m.focus)
+	}
+	if len(m.sessions) != 1 || m.sessions[0].ID != "s2" {
+		t.Errorf("sessions = %+v, want only s2", m.sessions)
+	}
+	if m.store.IsArchived("s1") || m.approval != nil || m.totalApprovals() != 0 {
+		t.Error("deleted session should leave no archived flag or pending requests")
+	}
+}
@@ -1,6 +1,8 @@
 package app
 
 import (
+"context"
+"errors"
 "fmt"
 "os"
 "path/filepath"
@@ -10,9 +12,14 @@ import (
 tea "github.com/charmbracelet/bubbletea"
 sdk "github.com/github/copilot-sdk/go"
 
+"github.com/e-9/copilot-icq/internal/config"
 "github.com/e-9/copilot-icq/internal/copilot"
 "github.com/e-9/copilot-icq/internal/domain"
+"github.com/e-9/copilot-icq/internal/exporter"
+"github.com/e-9/copilot-icq/internal/state"
 "github.com/e-9/copilot-icq/internal/ui/chat"
+"github.com/e-9/copilot-icq/internal/ui/modelpicker"
+"github.com/e-9/copilot-icq/internal/ui/queue"
 "github.com/e-9/copilot-icq/internal/ui/theme"
 "gopkg.in/yaml.v3"
 )
@@ -28,17 +35,87 @@ m.showHelp = false
 return m, nil
 }
 
+// Pager takes every key except Ctrl+C while open
+if m.pager != nil && msg.String() != "ctrl+c" {
+return m, m.updatePager(msg)
+}
+
+// Diff view takes every key except Ctrl+C while open
+if m.diffView != nil && msg.String() != "ctrl+c" {
+return m, m.updateDiffView(msg)
+}
+
+// Delete confirmation: y deletes, any other key cancels
+if m.confirmDelete != nil {
+return m, m.confirmDeleteKey(msg)
+}
+
+// Model picker takes every key except Ctrl+C while open
+if m.modelPicker != nil && msg.String() != "ctrl+c" {
+return m, m.updateModelPicker(msg)
+}
+
+// Finder takes every key except Ctrl+C while open
+if m.finder != nil && msg.String() != "ctrl+c" {
+return m, m.updateFinder(msg)
+}
+
+// New-session form takes every key except Ctrl+C while open
+if m.newSession != nil && msg.String() != "ctrl+c" {
+return m, m.updateNewSession(msg)
+}
+
+// Broadcast panel takes every key except Ctrl+C while showing
+if m.broadcast != nil && !m.broadcast.Closed() && msg.String() != "ctrl+c" {
+return m, m.updateBroadcast(msg)
+}
+
+// Chat search prompt takes every key except Ctrl+C while typing
+if m.focus == FocusChat && m.chat.IsSearching() && msg.String() != "ctrl+c" {
+var cmd tea.Cmd
+m.chat, cmd = m.chat.Update(msg)
+return m, cmd
+}
+
+// Queue list takes every key except Ctrl+C while it has focus
+if m.queueList.Focused() && m.focus == FocusInput && msg.String() != "ctrl+c" {
+var action queue.Action
+m.queueList, action = m.queueList.Update(msg)
+m.applyQueueAction(action)
+return m, nil
+}
+
+// Approval dialog takes the keys it understands once tabbed into
+if m.approval != nil && m.focus == FocusInput && m.approval.Handles(msg) {
+var cmd tea.Cmd
+*m.approval, cmd = m.approval.Update(msg)
+if res, ok := m.approval.Answered(); ok {
+m.answerApproval(res)
+} else {
+m.resize()
+}
+return m, cmd
+}
+if m.approval == nil && m.question != nil && m.focus == FocusInput && m.question.Handles(msg) {
+var cmd tea.Cmd
+*m.question, cmd = m.question.Update(msg)
+if res, ok := m.question.Answered(); ok {
+m.answerQuestion(res)
+}
+return m, cmd
+}
+
 switch msg.String() {
 case "ctrl+c":
 // If a message is being sent via SDK, abort it instead of quitting
 if m.selected != nil && m.pendingSends[m.selected.ID] && m.sdkResumed[m.selected.ID] {
-cmds = append(cmds, sdkAbort(m.adapter, m.selected.ID))
+cmds = append(cmds, sdkAbort(m.backend, m.selected.ID))
 return m, tea.Batch(cmds...)
 }
-return m, tea.Quit
+return m, m.quit()
 case "q":
 if m.focus != FocusInput {
-return m, tea.Quit
+return m, m.quit()
 }
 case "esc":
 if m.renaming {
@@ -49,6 +126,16 @@ m.focus = FocusSidebar
 m.input.Blur()
 return m, nil
 }
+if m.focus == FocusSidebar && len(m.sidebar.Marked()) > 0 {
+m.sidebar.ClearMarked()
+return m, nil
+}
+if m.focus == FocusInput && m.editingQueued >= 0 {
+// Abandon the edit of a queued prompt
+m.stopEditingQueued()
+m.resize()
+return m, nil
+}
 if m.focus == FocusInput {
 m.focus = FocusChat
 m.input.Blur()
@@ -63,6 +150,11 @@ case "e":
 if m.focus != FocusInput && m.selected != nil {
 return m, m.exportConversation()
 }
+case "F":
+// Shift+F: search every session's history
+if m.focus != FocusInput && !m.sidebar.IsFiltering() {
+return m, m.openFinder()
+}
 case "R":
 // Shift+R: rename session (sidebar only)
 if m.focus == FocusSidebar {
@@ -73,24 +165,94 @@ m.input.SetRenaming(s.DisplayName())
 }
 return m, nil
 }
+case "n":
+if m.focus == FocusSidebar && !m.sidebar.IsFiltering() {
+return m, m.openNewSession()
+}
+case "m":
+switch m.focus {
+case FocusSidebar:
+if !m.sidebar.IsFiltering() {
+return m, m.openModelPicker(m.sidebar.SelectedSession())
+}
+case FocusChat:
+return m, m.openModelPicker(m.selected)
+}
+case "D":
+// Shift+D: delete session (sidebar only, asks for confirmation)
+if m.focus == FocusSidebar && !m.sidebar.IsFiltering() {
+m.confirmDelete = m.sidebar.SelectedSession()
+return m, nil
+}
+case " ":
+// Space: mark or unmark for a broadcast (sidebar only)
+if m.focus == FocusSidebar && !m.sidebar.IsFiltering() {
+if s := m.sidebar.SelectedSession(); s != nil {
+m.sidebar.ToggleMarked(s.ID)
+}
+return m, nil
+}
+case "B":
+// Shift+B: broadcast to the marked sessions, or show the running broadcast
+if m.focus != FocusInput && !m.sidebar.IsFiltering() {
+return m, m.openBroadcast()
+}
+case "x":
+if m.focus == FocusSidebar && !m.sidebar.IsFiltering() {
+return m, m.toggleArchived()
+}
+case "X":
+if m.focus == FocusSidebar && !m.sidebar.IsFiltering() {
+return m, m.toggleShowArchived()
+}
+case "M":
+// Shift+M: mute or unmute desktop notifications (sidebar only)
+if m.focus == FocusSidebar && !m.sidebar.IsFiltering() {
+return m, m.toggleMuted()
+}
 case "r":
 if m.focus != FocusInput {
-cmds = append(cmds, sdkListSessions(m.adapter))
+cmds = append(cmds, sdkListSessions(m.backend))
+}
+case "ctrl+e":
+if m.focus == FocusInput && !m.renaming && m.canSend() {
+return m, openEditor(m.input.Value())
+}
+case "ctrl+o":
+if m.focus == FocusInput && !m.renaming {
+m.queueList.Focus()
+if m.queueList.Focused() {
+m.input.Blur()
+}
+return m, nil
+}
+case "ctrl+x":
+if m.focus == FocusInput && !m.renaming {
+return m, m.cancelNextQueued()
+}
+case "/":
+// Search the conversation; with a search open the chat panel re-edits it
+if m.focus == FocusChat && !m.chat.HasSearch() {
+return m, m.chat.StartSearch()
 }
 case "t":
 if m.focus == FocusChat {
 m.chat.ToggleAllToolCalls()
 return m, nil
 }
+case "d":
+// Every file changed in this session
+if m.focus == FocusChat && m.openSessionDiff() {
+return m, nil
+}
 case "tab":
 switch m.focus {
 case FocusSidebar:
 m.focus = FocusChat
 m.input.Blur()
 case FocusChat:
-if m.selected != nil && m.canSend() {
-m.focus = FocusInput
-m.input.Focus()
+if m.selected != nil && (m.canSend() || m.hasDialog()) {
+m.focusInput()
 } else {
 m.focus = FocusSidebar
 m.input.Blur()
@@ -103,9 +265,8 @@ return m, nil
 case "shift+tab":
 switch m.focus {
 case FocusSidebar:
-if m.selected != nil && m.canSend() {
-m.focus = FocusInput
-m.input.Focus()
+if m.selected != nil && (m.canSend() || m.hasDialog()) {
+m.focusInput()
 } else {
 m.focus = FocusChat
 m.input.Blur()
@@ -121,22 +282,8 @@ return m, nil
 case "enter":
 if m.focus == FocusSidebar && !m.sidebar.IsFiltering() {
 if s := m.sidebar.SelectedSession(); s != nil {
-m.selected = s
 m.focus = FocusChat
-m.unread[s.ID] = 0
-m.sidebar.SetActiveID(s.ID)
-m.sidebar.SetUnread(m.unread)
-m.sidebar.ClearFilterAndSetItems(m.sessions)
-m.input.SetSending(m.pendingSends[s.ID])
-if !m.pendingSends[s.ID] {
-m.input.Reset()
-}
-if !m.sdkResumed[s.ID] {
-cmds = append(cmds, sdkResumeSession(m.adapter, s.ID))
-} else {
-cmds = append(cmds, sdkLoadHistory(m.adapter, s.ID))
-}
-m.chat.SetPendingTools(m.pendingToolsForChat())
+cmds = append(cmds, m.selectSession(s, ""))
 }
 } else if m.focus == FocusInput {
 if m.renaming {
@@ -152,19 +299,21 @@ m.input.ClearRenaming()
 m.focus = FocusSidebar
 m.input.Blur()
 } else {
-text := m.input.Value()
-if text != "" && m.selected != nil && !m.pendingSends[m.selected.ID] && m.sdkResumed[m.selected.ID] {
-m.pendingSends[m.selected.ID] = true
-m.input.SetSending(true)
-m.sidebar.SetPendingSends(m.pendingSends)
-m.input.Reset()
-cmds = append(cmds, sdkSendMessage(m.adapter, m.selected.ID, text))
-}
+cmds = append(cmds, m.sendPrompt(m.input.Value()))
+return m, tea.Batch(cmds...)
 }
+} else if m.focus == FocusChat && (m.openToolDiff() || m.openPager()) {
+return m, nil
 }
 }
 
 case tea.MouseMsg:
+if m.pager != nil {
+return m, m.updatePager(msg)
+}
+if m.diffView != nil {
+return m, m.updateDiffView(msg)
+}
 // Determine which panel was clicked based on X coordinate
 if msg.Action == tea.MouseActionPress || msg.Action == tea.MouseActionMotion {
 if msg.Button == tea.MouseButtonLeft {
@@ -173,10 +322,9 @@ if m.focus != FocusSidebar {
 m.focus = FocusSidebar
 m.input.Blur()
 }
-} else if m.height > 0 && msg.Y >= m.height-5 && m.selected != nil {
-if m.focus != FocusInput && m.canSend() {
-m.focus = FocusInput
-m.input.Focus()
+} else if m.height > 0 && msg.Y >= m.height-4-m.bottomHeight() && m.selected != nil {
+if m.focus != FocusInput && (m.canSend() || m.hasDialog()) {
+m.focusInput()
 }
 } else {
 if m.focus != FocusChat {
@@ -191,36 +339,18 @@ case tea.WindowSizeMsg:
 m.width = msg.Width
 m.height = msg.Height
 m.ready = true
-borderH := 2
-borderW := 2
-headerH := 1
-statusBarH := 1
-
-panelHeight := m.height - headerH - statusBarH - borderH
-if panelHeight < 1 {
-panelHeight = 1
-}
-sidebarInnerW := theme.SidebarWidth
-chatInnerW := m.width - sidebarInnerW - borderW*2
-if chatInnerW < 1 {
-chatInnerW = 1
-}
-
-m.sidebar.SetSize(sidebarInnerW, panelHeight)
-
-inputHeight := 1
-inputBorderH := inputHeight + borderH
-chatInnerH := panelHeight - inputBorderH
-m.chat.SetSize(chatInnerW, chatInnerH)
-m.input.SetWidth(chatInnerW)
+m.resize()
+m.resizePager()
+m.resizeDiffView()
 
 case SessionsLoadedMsg:
 if msg.Err != nil {
 m.err = msg.Err
 return m, nil
 }
-m.sessions = msg.Sessions
-m.sidebar.SetItems(msg.Sessions)
+m.sessions = m.withResumed(msg.Sessions)
+m.sidebar.SetItems(m.sessions)
+cmds = append(cmds, m.syncIndex())
 
 case EventsLoadedMsg:
 if msg.Err != nil {
@@ -229,10 +359,18 @@ return m, nil
 }
 if m.selected != nil && m.selected.ID == msg.SessionID {
 m.chat.SetMessages(msg.Messages)
+for _, q := range m.questions[msg.SessionID] {
+m.recordQuestion(q, nil)
+}
+m.showOpenAt(msg.SessionID)
 }
 
 case TickMsg:
-cmds = append(cmds, sdkListSessions(m.adapter))
+// Persist the draft being typed so it survives a crash, not just a clean quit
+if m.syncDraft() {
+cmds = append(cmds, saveState(m.store))
+}
+cmds = append(cmds, sdkListSessions(m.backend))
 cmds = append(cmds, tickEvery(5*time.Second))
 
 case MessageSentMsg:
@@ -241,19 +379,36 @@ m.sidebar.SetPendingSends(m.pendingSends)
 if m.selected != nil && m.selected.ID == msg.SessionID {
 m.input.SetSending(false)
 }
-if msg.Err != nil {
-m.err = msg.Err
-} else {
+switch {
+case msg.Err == nil:
 m.input.Reset()
+case m.broadcastFailed(msg.SessionID, msg.Err):
+// Shown in the broadcast progress rather than taking over the screen
+case len(m.queued[msg.SessionID]) > 0:
+// Keep the queue rather than failing every prompt in it; the next
+// prompt sent to the session carries on with it
+prompts := "prompts"
+if n := len(m.queued[msg.SessionID]); n == 1 {
+prompts = "prompt"
+}
+m.statusFlash = fmt.Sprintf("⚠️  Send failed: %v · %d queued %s paused until the next send", msg.Err, len(m.queued[msg.SessionID]), prompts)
+cmds = append(cmds, clearFlashAfter(5*time.Second))
+default:
+m.err = msg.Err
 }
 
 case SessionRenamedMsg:
 if msg.Err == nil {
-cmds = append(cmds, sdkListSessions(m.adapter))
+cmds = append(cmds, sdkListSessions(m.backend))
 }
 
 case ExportCompleteMsg:
-// Nothing to do in the model
+if msg.Err != nil {
+m.statusFlash = fmt.Sprintf("⚠️  Export failed: %v", msg.Err)
+} else {
+m.statusFlash = "📄 Exported to " + msg.Path
+}
+cmds = append(cmds, tea.Tick(5*time.Second, func(_ time.Time) tea.Msg { return ClearFlashMsg{} }))
 
 case SDKConnectedMsg:
 if msg.Err != nil {
@@ -261,56 +416,105 @@ m.statusFlash = fmt.Sprintf("⚠️  SDK init failed: %v", msg.Err)
 cmds = append(cmds, tea.Tick(5*time.Second, func(_ time.Time) tea.Msg { return ClearFlashMsg{} }))
 } else {
 m.statusFlash = "🔗 SDK connected"
+if m.readOnly() {
+m.statusFlash = "📂 Reading session files (read-only)"
+}
 cmds = append(cmds, tea.Tick(5*time.Second, func(_ time.Time) tea.Msg { return ClearFlashMsg{} }))
-cmds = append(cmds, sdkListSessions(m.adapter))
+cmds = append(cmds, sdkListSessions(m.backend))
 }
 
 case SDKSessionResumedMsg:
+cmds = append(cmds, m.broadcastResumed(msg.SessionID, msg.Err))
 if msg.Err != nil {
 m.statusFlash = fmt.Sprintf("⚠️  Resume failed: %v", msg.Err)
 cmds = append(cmds, tea.Tick(5*time.Second, func(_ time.Time) tea.Msg { return ClearFlashMsg{} }))
 } else {
 m.sdkResumed[msg.SessionID] = true
-cmds = append(cmds, sdkLoadHistory(m.adapter, msg.SessionID))
+m.setSessionModel(msg.SessionID, msg.Model)
+cmds = append(cmds, sdkLoadHistory(m.backend, msg.SessionID))
+}
+
+case ModelsLoadedMsg:
+if m.modelPicker != nil {
+opts := make([]modelpicker.Option, len(msg.Models))
+for i, mi := range msg.Models {
+opts[i] = modelpicker.Option{ID: mi.ID, Name: mi.Name}
+}
+m.modelPicker.SetOptions(opts, msg.Err)
 }
 
+case ModelSwitchedMsg:
+cmds = append(cmds, m.modelSwitched(msg))
+
+case EditorFinishedMsg:
+if msg.Err != nil {
+m.statusFlash = fmt.Sprintf("⚠️  Editor: %v", msg.Err)
+cmds = append(cmds, clearFlashAfter(5*time.Second))
+} else if m.selected != nil && m.sdkResumed[m.selected.ID] {
+cmds = append(cmds, m.sendPrompt(msg.Text))
+} else {
+// Nowhere to send it yet; keep the text in the composer
+m.input.SetValue(msg.Text)
+m.resize()
+}
+
+case SDKSessionDeletedMsg:
+cmds = append(cmds, m.sessionDeleted(msg))
+
+case IndexSyncedMsg:
+m.indexSynced(msg)
+
+case StateSavedMsg:
+if msg.Err != nil {
+m.statusFlash = fmt.Sprintf("⚠️  %v", msg.Err)
+cmds = append(cmds, clearFlashAfter(5*time.Second))
+}
+
+case NotifyFailedMsg:
+m.statusFlash = fmt.Sprintf("⚠️  Notification failed: %v", msg.Err)
+cmds = append(cmds, clearFlashAfter(5*time.Second))
+
+case SDKSessionCreatedMsg:
+cmds = append(cmds, m.sessionCreated(msg))
+
 case SDKEventMsg:
 evt := msg.Event
+m.publishEvent(evt)
+cmds = append(cmds, m.notifyEvent(evt))
+m.index.Apply(evt)
 switch evt.Type {
 case copilot.EventSession:
 if evt.SessionEvent != nil {
 m.handleSDKSessionEvent(evt.SessionID, *evt.SessionEvent, &cmds)
 }
 case copilot.EventLifecycle:
-cmds = append(cmds, sdkListSessions(m.adapter))
+// Deletions are applied directly; other changes need a fresh listing
+if evt.Lifecycle != nil && evt.Lifecycle.Type == sdk.SessionLifecycleDeleted {
+m.removeSession(evt.SessionID)
+cmds = append(cmds, saveState(m.store))
+} else {
+cmds = append(cmds, sdkListSessions(m.backend))
+}
 case copilot.EventPermission:
 if evt.Permission != nil {
-m.pendingTools[evt.SessionID] = append(m.pendingTools[evt.SessionID], PendingTool{
-ToolName: evt.Permission.ToolName,
-ToolArgs: evt.Permission.Action,
-})
-if m.selected != nil && m.selected.ID == evt.SessionID {
-m.chat.SetPendingTools(m.pendingToolsForChat())
-}
-// Auto-allow for now
-evt.Permission.Response <- copilot.PermissionResponse{Allow: true}
+m.queueApproval(evt.SessionID, evt.Permission)
 }
 case copilot.EventUserInput:
 if evt.UserInput != nil {
-if m.selected != nil && m.selected.ID == evt.SessionID {
-msgs := m.chat.Messages()
-msgs = append(msgs, domain.Message{
-Role:    domain.RoleSystem,
-Content: fmt.Sprintf("🤖 Agent asks: %s", evt.UserInput.Question),
-})
-m.chat.SetMessages(msgs)
+m.queueQuestion(evt.SessionID, evt.UserInput)
 }
-// Auto-respond for now
-evt.UserInput.Response <- copilot.UserInputResponse{Answer: "", WasFreeform: true}
+case copilot.EventHook:
+if evt.Hook != nil {
+cmds = append(cmds, m.hookEvent(evt.SessionID, evt.Hook))
+}
+case copilot.EventResolved:
+if evt.Resolved != nil {
+m.approvalResolved(evt.SessionID, evt.Resolved)
+m.questionResolved(evt.SessionID, evt.Resolved)
 }
 }
 // Keep listening
-cmds = append(cmds, listenSDKEvents(m.adapter))
+cmds = append(cmds, listenSDKEvents(m.backend))
 
 case SDKDisconnectedMsg:
 m.statusFlash = "⚠️  SDK disconnected"
@@ -332,7 +536,11 @@ m.chat, cmd = m.chat.Update(msg)
 cmds = append(cmds, cmd)
 case FocusInput:
 var cmd tea.Cmd
+h := m.input.Height()
 m.input, cmd = m.input.Update(msg)
+if m.input.Height() != h {
+m.resize()
+}
 cmds = append(cmds, cmd)
 }
 
@@ -367,68 +575,83 @@ return SessionRenamedMsg{SessionID: sessionID}
 }
 }
 
-// exportConversation writes the current conversation to a markdown file.
+// exportConversation writes the selected session's whole history in the
+// configured format, falling back to what the chat has loaded.
 func (m Model) exportConversation() tea.Cmd {
-return func() tea.Msg {
 if m.selected == nil {
-return ExportCompleteMsg{Err: fmt.Errorf("no session selected")}
+return func() tea.Msg { return ExportCompleteMsg{Err: fmt.Errorf("no session selected")} }
 }
-
-var sb strings.Builder
-sb.WriteString(fmt.Sprintf("# Copilot Session: %s\n\n", m.selected.DisplayName()))
-sb.WriteString(fmt.Sprintf("- **Session ID**: `%s`\n", m.selected.ID))
-sb.WriteString(fmt.Sprintf("- **CWD**: `%s`\n", m.selected.CWD))
-sb.WriteString(fmt.Sprintf("- **Created**: %s\n", m.selected.CreatedAt.Format(time.RFC3339)))
-sb.WriteString(fmt.Sprintf("- **Updated**: %s\n\n", m.selected.UpdatedAt.Format(time.RFC3339)))
-sb.WriteString("---\n\n")
-
-msgs := m.chat.Messages()
-for _, msg := range msgs {
-ts := msg.Timestamp.Format("15:04:05")
-switch msg.Role {
-case domain.RoleUser:
-sb.WriteString(fmt.Sprintf("### 🧑 You (%s)\n\n%s\n\n", ts, msg.Content))
-case domain.RoleAssistant:
-sb.WriteString(fmt.Sprintf("### 🤖 Copilot (%s)\n\n", ts))
-if msg.Content != "" {
-sb.WriteString(msg.Content + "\n\n")
+session := *m.selected
+loaded := m.chat.Messages()
+backend := m.backend
+dir, tmpl, format := ".", "", "md"
+if m.cfg != nil {
+if m.cfg.ExportDir != "" {
+dir = m.cfg.ExportDir
 }
-for _, tc := range msg.ToolCalls {
-status := "⏳"
-if tc.Status == domain.ToolCallComplete {
-status = "✓"
-} else if tc.Status == domain.ToolCallFailed {
-status = "✗"
+tmpl = m.cfg.ExportTemplate
+if m.cfg.ExportFormat != "" {
+format = m.cfg.ExportFormat
 }
-sb.WriteString(fmt.Sprintf("- 🔧 **%s** %s", tc.Name, status))
-if tc.Summary != "" {
-summary := tc.Summary
-if len(summary) > 100 {
-summary = summary[:97] + "..."
 }
-sb.WriteString(fmt.Sprintf(": `%s`", summary))
+
+return func() tea.Msg {
+f, err := exporter.ParseFormat(format)
+if err != nil {
+return ExportCompleteMsg{Err: err}
 }
-sb.WriteString("\n")
+msgs := loaded
+if backend != nil {
+if history, err := backend.GetHistory(context.Background(), session.ID); err == nil && len(history) > 0 {
+msgs = history
 }
-sb.WriteString("\n")
-case domain.RoleSystem:
-sb.WriteString(fmt.Sprintf("*ℹ %s* (%s)\n\n", msg.Content, ts))
 }
+path, err := exporter.WriteFile(config.ExpandHome(dir), tmpl, f, exporter.Conversation{Session: session, Messages: msgs})
+return ExportCompleteMsg{Path: path, Err: err}
 }
-
-exportDir := "."
-if m.cfg != nil && m.cfg.ExportDir != "" {
-exportDir = m.cfg.ExportDir
 }
 
-filename := fmt.Sprintf("copilot-session-%s.md", m.selected.ShortID())
-outPath := filepath.Join(exportDir, filename)
-if err := os.WriteFile(outPath, []byte(sb.String()), 0644); err != nil {
-return ExportCompleteMsg{Err: err}
+// selectSession opens a session in the chat panel, resuming it first if needed.
+// A non-empty model is used when resuming; otherwise the one configured for
+// the session's directory, and failing that the session keeps its own.
+// The outgoing session's draft is stashed and the incoming one's restored.
+func (m *Model) selectSession(s *domain.Session, model string) tea.Cmd {
+m.openAt = nil
+m.stopEditingQueued()
+m.queueList.Blur()
+var save tea.Cmd
+if m.syncDraft() {
+save = saveState(m.store)
 }
-
-return ExportCompleteMsg{Path: outPath}
+watch := watchSession(m.backend, s.ID)
+if m.selected == nil || m.selected.ID != s.ID {
+// Folds and the cursor belong to the conversation on screen
+m.chat.Reset()
+}
+m.selected = s
+m.unread[s.ID] = 0
+m.sidebar.SetActiveID(s.ID)
+m.sidebar.SetUnread(m.unread)
+m.sidebar.ClearFilterAndSetItems(m.sessions)
+m.input.SetSession(s.ID)
+m.input.SetSending(m.pendingSends[s.ID])
+m.input.Reset()
+if d, ok := m.store.Draft(s.ID); ok {
+m.input.SetValue(d.Text)
+m.input.SetCursor(d.Row, d.Col)
+}
+m.sidebar.SetDrafts(m.store.DraftIDs())
+m.syncQueue()
+m.chat.SetPendingTools(m.pendingToolsForChat())
+m.syncApproval()
+m.syncQuestion()
+if !m.sdkResumed[s.ID] {
+if model == "" {
+model = m.cfg.ModelFor(s.CWD)
 }
+return tea.Batch(save, watch, sdkResumeSession(m.backend, s.ID, model))
+}
+return tea.Batch(save, watch, sdkLoadHistory(m.backend, s.ID))
 }
 
 // pendingToolsForChat returns pending tools for the currently selected session.
@@ -452,9 +675,161 @@ DenyReason: t.DenyReason,
 return result
 }
 
+// clearDeniedTools drops denied entries once the agent's turn is over.
+func (m *Model) clearDeniedTools(sessionID string) {
+var remaining []PendingTool
+for _, t := range m.pendingTools[sessionID] {
+if !t.Denied {
+remaining = append(remaining, t)
+}
+}
+m.pendingTools[sessionID] = remaining
+}
+
+// bottomHeight returns the inner height of the panel below the chat:
+// the approval or ask_user dialog when one is showing, otherwise the queue
+// and the composer.
+func (m Model) bottomHeight() int {
+if m.approval != nil {
+return m.approval.Height()
+}
+if m.question != nil {
+return m.question.Height()
+}
+return m.queueList.Height() + m.input.Height()
+}
+
+// sendPrompt sends text to the selected session and records it in the
+// session's prompt history. While the session is busy, or earlier prompts
+// are still queued, it is queued instead. If the composer holds a queued
+// prompt being edited, that prompt is replaced. Blank prompts are ignored.
+func (m *Model) sendPrompt(text string) tea.Cmd {
+if strings.TrimSpace(text) == "" || m.selected == nil || !m.sdkResumed[m.selected.ID] {
+return nil
+}
+id := m.selected.ID
+m.input.Remember(text)
+if m.editingQueued >= 0 {
+m.queued[id][m.editingQueued] = text
+m.stopEditingQueued()
+m.syncQueue()
+return m.sendNextQueued(id)
+}
+if m.store.SetDraft(id, state.Draft{}) {
+m.sidebar.SetDrafts(m.store.DraftIDs())
+}
+m.input.Reset()
+if m.pendingSends[id] || len(m.queued[id]) > 0 {
+m.queued[id] = append(m.queued[id], text)
+m.syncQueue()
+return m.sendNextQueued(id)
+}
+m.resize()
+return m.dispatch(id, text)
+}
+
+// resize lays out the panels for the current terminal size.
+func (m *Model) resize() {
+borderH := 2
+borderW := 2
+headerH := 1
+statusBarH := 1
+
+panelHeight := m.height - headerH - statusBarH - borderH
+if panelHeight < 1 {
+panelHeight = 1
+}
+sidebarInnerW := theme.SidebarWidth
+chatInnerW := m.width - sidebarInnerW - borderW*2
+if chatInnerW < 1 {
+chatInnerW = 1
+}
+
+m.sidebar.SetSize(sidebarInnerW, panelHeight)
+// The composer and queue heights depend on their width
+m.input.SetWidth(chatInnerW)
+m.queueList.SetWidth(chatInnerW)
+
+inputBorderH := m.bottomHeight() + borderH
+chatInnerH := panelHeight - inputBorderH
+if chatInnerH < 1 {
+chatInnerH = 1
+}
+m.chat.SetSize(chatInnerW, chatInnerH)
+if m.approval != nil {
+m.approval.SetWidth(chatInnerW)
+}
+if m.question != nil {
+m.question.SetWidth(chatInnerW)
+}
+if m.newSession != nil {
+m.newSession.SetWidth(chatInnerW)
+}
+if m.modelPicker != nil {
+m.modelPicker.SetSize(chatInnerW, panelHeight)
+}
+if m.finder != nil {
+m.finder.SetSize(chatInnerW, panelHeight)
+}
+if m.broadcast != nil {
+m.broadcast.SetSize(chatInnerW, panelHeight)
+}
+}
+
+// panelHeight returns the inner height of the sidebar and right panels.
+func (m Model) panelHeight() int {
+h := m.height - 4 // header, status bar and panel borders
+if h < 1 {
+h = 1
+}
+return h
+}
+
 // canSend returns true if the user can send messages to the selected session.
+// The input is unavailable while the session waits on an approval or answer,
+// and in read-only mode.
 func (m Model) canSend() bool {
-return m.selected != nil && m.sdkResumed[m.selected.ID]
+return m.selected != nil && m.sdkResumed[m.selected.ID] && m.approval == nil && m.question == nil && !m.readOnly()
+}
+
+// hasDialog reports whether an approval or question dialog has taken the
+// input box's place.
+func (m Model) hasDialog() bool {
+return m.approval != nil || m.question != nil
+}
+
+// focusInput moves focus to the input box, or to the dialog showing in its
+// place. The dialog only takes keys once focused, so its single-letter
+// choices never shadow the chat and sidebar shortcuts.
+func (m *Model) focusInput() {
+m.focus = FocusInput
+if m.hasDialog() {
+m.input.Blur()
+} else {
+m.input.Focus()
+}
+}
+
+// settleDialogFocus moves focus off the input box when its dialog closed and
+// the session cannot take a prompt.
+func (m *Model) settleDialogFocus() {
+if m.focus != FocusInput || m.renaming {
+return
+}
+switch {
+case m.hasDialog():
+m.input.Blur()
+case m.canSend():
+m.input.Focus()
+default:
+m.focus = FocusChat
+m.input.Blur()
+}
+}
+
+// readOnly reports whether the backend only reads session files.
+func (m Model) readOnly() bool {
+return m.backend != nil && m.backend.ReadOnly()
 }
 
 // handleSDKSessionEvent processes a single SDK session event.
@@ -466,16 +841,7 @@ switch event.Type {
 case sdk.AssistantMessageDelta:
 if m.selected != nil && m.selected.ID == sessionID {
 if event.Data.DeltaContent != nil {
-msgs := m.chat.Messages()
-if len(msgs) > 0 && msgs[len(msgs)-1].Role == domain.RoleAssistant {
-msgs[len(msgs)-1].Content += *event.Data.DeltaContent
-} else {
-msgs = append(msgs, domain.Message{
-Role:    domain.RoleAssistant,
-Content: *event.Data.DeltaContent,
-})
-}
-m.chat.SetMessages(msgs)
+m.chat.AppendDelta(*event.Data.DeltaContent)
 }
 } else {
 m.unread[sessionID]++
@@ -483,18 +849,12 @@ m.sidebar.SetUnread(m.unread)
 }
 
 case sdk.AssistantMessage:
+if event.Data.Content != nil && *event.Data.Content != "" {
+m.lastReply[sessionID] = *event.Data.Content
+}
 if m.selected != nil && m.selected.ID == sessionID {
 if event.Data.Content != nil {
-msgs := m.chat.Messages()
-if len(msgs) > 0 && msgs[len(msgs)-1].Role == domain.RoleAssistant {
-msgs[len(msgs)-1].Content = *event.Data.Content
-} else {
-msgs = append(msgs, domain.Message{
-Role:    domain.RoleAssistant,
-Content: *event.Data.Content,
-})
-}
-m.chat.SetMessages(msgs)
+m.chat.SetReply(*event.Data.Content)
 }
 }
 
@@ -531,12 +891,22 @@ if m.selected != nil && m.selected.ID == sessionID {
 m.chat.SetPendingTools(m.pendingToolsForChat())
 }
 
+case sdk.SessionModelChange:
+if event.Data.NewModel != nil {
+m.setSessionModel(sessionID, *event.Data.NewModel)
+}
+
 case sdk.SessionIdle:
 delete(m.pendingSends, sessionID)
 m.sidebar.SetPendingSends(m.pendingSends)
+m.clearDeniedTools(sessionID)
 if m.selected != nil && m.selected.ID == sessionID {
 m.input.SetSending(false)
+m.chat.EndReply()
+m.chat.SetPendingTools(m.pendingToolsForChat())
 }
+delete(m.lastReply, sessionID)
+*cmds = append(*cmds, m.broadcastIdle(sessionID), m.sendNextQueued(sessionID))
 
 case sdk.SessionError:
 errMsg := "unknown error"
@@ -544,6 +914,10 @@ if event.Data.Message != nil {
 errMsg = *event.Data.Message
 }
 m.statusFlash = fmt.Sprintf("⚠️  %s", errMsg)
+if m.selected != nil && m.selected.ID == sessionID {
+m.chat.EndReply()
+}
+m.broadcastFailed(sessionID, errors.New(errMsg))
 *cmds = append(*cmds, tea.Tick(5*time.Second, func(_ time.Time) tea.Msg { return ClearFlashMsg{} }))
 }
 
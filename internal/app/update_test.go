@@ -0,0 +1,276 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot/copilottest"
+	"github.com/e-9/copilot-icq/internal/diff"
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+// resumedModel returns a model backed by a fake with sessions a and b, and
+// a selected and resumed with its history loaded.
+func resumedModel(t *testing.T) (Model, *copilottest.Backend) {
+	t.Helper()
+	fake := copilottest.New(domain.Session{ID: "a"}, domain.Session{ID: "b"})
+	fake.History["a"] = []domain.Message{{Role: domain.RoleUser, Content: "hi"}}
+	m := NewModel("", nil, fake, nil)
+	m.sessions = fake.Sessions
+	m.selectSession(&m.sessions[0], "")
+
+	model, _ := m.Update(sdkResumeSession(fake, "a", "")())
+	m = model.(Model)
+	model, _ = m.Update(sdkLoadHistory(fake, "a")())
+	m = model.(Model)
+	if !m.canSend() || len(m.chat.Messages()) != 1 {
+		t.Fatalf("session a should be resumed with its history, got %d messages", len(m.chat.Messages()))
+	}
+	return m, fake
+}
+
+func str(s string) *string { return &s }
+
+func TestSwitchingSessionsResetsFolds(t *testing.T) {
+	m, fake := resumedModel(t)
+	model, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = model.(Model)
+	calls := []domain.Message{{Role: domain.RoleAssistant, Content: "ran it",
+		ToolCalls: []domain.ToolCall{{Name: "bash", Status: domain.ToolCallComplete, Summary: "ok"}}}}
+	fake.History["a"], fake.History["b"] = calls, calls
+	load := func(id string) {
+		t.Helper()
+		for i := range m.sessions {
+			if m.sessions[i].ID == id {
+				m.selectSession(&m.sessions[i], "")
+			}
+		}
+		model, _ := m.Update(sdkResumeSession(fake, id, "")())
+		m = model.(Model)
+		model, _ = m.Update(sdkLoadHistory(fake, id)())
+		m = model.(Model)
+	}
+	folded := func() bool { return strings.Contains(m.chat.View(), "▸ 🔧") }
+
+	load("a")
+	m.chat.ToggleAllToolCalls()
+	if !folded() {
+		t.Fatal("the tool call should be folded")
+	}
+	// The calls have no ID, so their folds are kept by position
+	load("a")
+	if !folded() {
+		t.Error("reloading the same session should keep its folds")
+	}
+	load("b")
+	if folded() {
+		t.Error("another session should not inherit the folds")
+	}
+}
+
+func TestStreamingIntoSelectedSession(t *testing.T) {
+	m, _ := resumedModel(t)
+	var cmds []tea.Cmd
+
+	m.handleSDKSessionEvent("a", sdk.SessionEvent{Type: sdk.AssistantMessageDelta, Data: sdk.Data{DeltaContent: str("Hel")}}, &cmds)
+	m.handleSDKSessionEvent("a", sdk.SessionEvent{Type: sdk.AssistantMessageDelta, Data: sdk.Data{DeltaContent: str("lo")}}, &cmds)
+	msgs := m.chat.Messages()
+	if len(msgs) != 2 || msgs[1].Content != "Hello" {
+		t.Fatalf("messages = %+v, want the deltas joined into one reply", msgs)
+	}
+
+	m.handleSDKSessionEvent("a", sdk.SessionEvent{Type: sdk.AssistantMessage, Data: sdk.Data{Content: str("Hello!")}}, &cmds)
+	if msgs := m.chat.Messages(); len(msgs) != 2 || msgs[1].Content != "Hello!" {
+		t.Errorf("messages = %+v, want the final message to replace the streamed one", msgs)
+	}
+	if m.unread["a"] != 0 {
+		t.Error("the selected session should not count unread messages")
+	}
+}
+
+func TestUnreadCountsOtherSessions(t *testing.T) {
+	m, _ := resumedModel(t)
+	var cmds []tea.Cmd
+
+	for i := 0; i < 3; i++ {
+		m.handleSDKSessionEvent("b", sdk.SessionEvent{Type: sdk.AssistantMessageDelta, Data: sdk.Data{DeltaContent: str("x")}}, &cmds)
+	}
+	if m.unread["b"] != 3 {
+		t.Errorf("unread[b] = %d, want 3", m.unread["b"])
+	}
+	if len(m.chat.Messages()) != 1 {
+		t.Error("another session's output should not reach the chat")
+	}
+
+	m.selectSession(&m.sessions[1], "")
+	if m.unread["b"] != 0 {
+		t.Errorf("unread[b] = %d after selecting it, want 0", m.unread["b"])
+	}
+}
+
+func TestPendingToolBookkeeping(t *testing.T) {
+	m, _ := resumedModel(t)
+	var cmds []tea.Cmd
+	start := func(name string) {
+		m.handleSDKSessionEvent("a", sdk.SessionEvent{Type: sdk.ToolExecutionStart, Data: sdk.Data{ToolName: str(name)}}, &cmds)
+	}
+	complete := func(name string) {
+		m.handleSDKSessionEvent("a", sdk.SessionEvent{Type: sdk.ToolExecutionComplete, Data: sdk.Data{ToolName: str(name)}}, &cmds)
+	}
+
+	start("bash")
+	start("view")
+	start("bash")
+	if n := len(m.pendingTools["a"]); n != 3 {
+		t.Fatalf("pending = %d, want 3", n)
+	}
+	if n := len(m.pendingToolsForChat()); n != 3 {
+		t.Errorf("chat shows %d pending tools, want 3", n)
+	}
+
+	complete("bash")
+	got := m.pendingTools["a"]
+	if len(got) != 2 || got[0].ToolName != "view" || got[1].ToolName != "bash" {
+		t.Errorf("pending after one bash completed = %+v", got)
+	}
+	complete("view")
+	complete("bash")
+	if len(m.pendingTools["a"]) != 0 {
+		t.Errorf("pending = %+v, want none", m.pendingTools["a"])
+	}
+}
+
+func TestSendAndIdleThroughBackend(t *testing.T) {
+	m, fake := resumedModel(t)
+
+	cmd := m.sendPrompt("fix the tests")
+	if cmd == nil {
+		t.Fatal("an idle session should send right away")
+	}
+	if msg := cmd(); msg != nil {
+		t.Fatalf("a successful send should wait for idle, got %#v", msg)
+	}
+	if sent := fake.Sent(); len(sent) != 1 || sent[0] != (copilottest.Sent{SessionID: "a", Text: "fix the tests"}) {
+		t.Errorf("sent = %+v", sent)
+	}
+	if !m.pendingSends["a"] || !m.input.IsSending() {
+		t.Error("the session should be busy until idle")
+	}
+
+	// Ctrl+C aborts the running turn instead of quitting
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	m = model.(Model)
+	cmd()
+	if got := fake.Aborted(); len(got) != 1 || got[0] != "a" {
+		t.Errorf("aborted = %v", got)
+	}
+
+	fake.EmitSession("a", sdk.SessionEvent{Type: sdk.SessionIdle})
+	msg := listenSDKEvents(fake)()
+	if _, ok := msg.(SDKEventMsg); !ok {
+		t.Fatalf("listener returned %#v", msg)
+	}
+	model, _ = m.Update(msg)
+	m = model.(Model)
+	if m.pendingSends["a"] || m.input.IsSending() {
+		t.Error("idle should clear the busy state")
+	}
+}
+
+func TestSendErrorIsReported(t *testing.T) {
+	m, fake := resumedModel(t)
+	fake.Err = errors.New("connection lost")
+
+	msg := m.sendPrompt("hello")()
+	sent, ok := msg.(MessageSentMsg)
+	if !ok || sent.Err == nil {
+		t.Fatalf("msg = %#v, want a failed MessageSentMsg", msg)
+	}
+	model, _ := m.Update(msg)
+	m = model.(Model)
+	if m.pendingSends["a"] || m.err == nil {
+		t.Error("a failed send should clear the busy state and show the error")
+	}
+}
+
+func TestReadOnlyBackendDisablesInput(t *testing.T) {
+	m, fake := resumedModel(t)
+	fake.ReadOnlyMode = true
+	if m.canSend() {
+		t.Error("a read-only backend should not accept prompts")
+	}
+}
+
+func TestChatSearchTakesKeys(t *testing.T) {
+	m, _ := resumedModel(t)
+	m.focus = FocusChat
+	for _, r := range "/q" {
+		model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = model.(Model)
+	}
+	if !m.chat.IsSearching() || !strings.Contains(m.chat.View(), "/q") {
+		t.Error("q typed into the chat search should go to the query, not quit")
+	}
+}
+
+func TestPagerShowsSelection(t *testing.T) {
+	m, _ := resumedModel(t)
+	model, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	m = model.(Model)
+	m.focus = FocusChat
+
+	for _, k := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("J")}, {Type: tea.KeyEnter}} {
+		model, _ = m.Update(k)
+		m = model.(Model)
+	}
+	if m.pager == nil || !strings.Contains(m.View(), "hi") {
+		t.Fatal("Enter on the selected message should show it in the pager")
+	}
+
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	m = model.(Model)
+	if m.pager != nil || cmd != nil {
+		t.Error("q should close the pager, not quit")
+	}
+}
+
+func TestDiffViewShowsEdits(t *testing.T) {
+	m, _ := resumedModel(t)
+	model, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	m = model.(Model)
+	m.focus = FocusChat
+	m.chat.SetMessages([]domain.Message{
+		{Role: domain.RoleUser, Content: "hi"},
+		{Role: domain.RoleAssistant, ToolCalls: []domain.ToolCall{
+			{ID: "1", Name: "edit", Status: domain.ToolCallFailed, FilePath: "other.go", Patch: diff.Edit("other.go", "x\n", "y\n")},
+		}},
+		{Role: domain.RoleAssistant, ToolCalls: []domain.ToolCall{
+			{ID: "2", Name: "edit", Status: domain.ToolCallComplete, FilePath: "main.go", Patch: diff.Edit("main.go", "a := 1\n", "a := 2\n")},
+		}},
+	})
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = model.(Model)
+	if m.diffView == nil {
+		t.Fatal("d should show the session's file changes")
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "1 file changed") || !strings.Contains(view, "main.go") || strings.Contains(view, "other.go") {
+		t.Errorf("only the edit that was made should be listed:\n%s", view)
+	}
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = model.(Model)
+
+	// K starts from the last tool call on screen
+	for _, k := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("K")}, {Type: tea.KeyEnter}} {
+		model, _ = m.Update(k)
+		m = model.(Model)
+	}
+	if m.diffView == nil || m.pager != nil || !strings.Contains(ansi.Strip(m.View()), "a := 2") {
+		t.Error("Enter on an edit should show its patch in the diff view")
+	}
+}
@@ -22,6 +22,14 @@ if m.showHelp {
 return m.renderHelpOverlay()
 }
 
+// Tool call or message in full
+if m.pager != nil {
+return m.pager.View()
+}
+if m.diffView != nil {
+return m.diffView.View()
+}
+
 borderH := 2
 borderW := 2
 headerH := 1
@@ -44,10 +52,13 @@ Foreground(theme.Warning).
 Bold(true).
 Render("  ⏳ sending...")
 }
+if n := m.totalApprovals(); n > 0 {
+sendingInfo += theme.ApprovalBadgeStyle.Render(fmt.Sprintf("  ⚡ %d awaiting you", n))
+}
 
 shortcuts := lipgloss.NewStyle().
 Foreground(theme.Subtle).
-Render("  ? help  e export  R rename  q quit")
+Render("  ? help  n new  e export  R rename  q quit")
 
 headerLeft := title + sessionCount + sendingInfo
 headerRight := shortcuts
@@ -79,14 +90,23 @@ sidebarView := theme.RenderTitledBorder("Sessions", sidebarContent, sidebarInner
 
 // Right panel (chat + input)
 var rightPanel string
-if m.renaming {
+if m.newSession != nil {
+rightPanel = theme.RenderTitledBorder("New Session", m.newSession.View(), chatInnerW, panelHeight, true)
+} else if m.broadcast != nil && !m.broadcast.Closed() {
+rightPanel = theme.RenderTitledBorder("Broadcast", m.broadcast.View(), chatInnerW, panelHeight, true)
+} else if m.finder != nil {
+rightPanel = theme.RenderTitledBorder("Search All Sessions", m.finder.View(), chatInnerW, panelHeight, true)
+} else if m.modelPicker != nil {
+pickerTitle := "Model · " + m.modelTarget.DisplayName()
+rightPanel = theme.RenderTitledBorder(pickerTitle, m.modelPicker.View(), chatInnerW, panelHeight, true)
+} else if m.renaming {
 renameLabel := lipgloss.NewStyle().
 Foreground(theme.Accent).Bold(true).
 Render("  ✏️  Rename session (Enter to save, Esc to cancel)")
-labelView := theme.RenderTitledBorder("Rename", renameLabel, chatInnerW, panelHeight-1-borderH, false)
 
 inputContent := m.input.View()
-inputView := theme.RenderTitledBorder("Input", inputContent, chatInnerW, 1, true)
+inputView := theme.RenderTitledBorder("Input", inputContent, chatInnerW, m.input.Height(), true)
+labelView := theme.RenderTitledBorder("Rename", renameLabel, chatInnerW, panelHeight-m.input.Height()-borderH, false)
 
 rightPanel = lipgloss.JoinVertical(lipgloss.Left, labelView, inputView)
 } else if m.selected == nil {
@@ -95,15 +115,35 @@ Foreground(theme.Subtle).
 Render("  Select a session and press Enter to view conversation")
 rightPanel = theme.RenderTitledBorder("Chat", placeholder, chatInnerW, panelHeight, false)
 } else {
-inputInnerH := 1
+inputInnerH := m.bottomHeight()
 chatInnerH := panelHeight - inputInnerH - borderH
+if chatInnerH < 1 {
+chatInnerH = 1
+}
 
 chatTitle := fmt.Sprintf("Chat · %s (%s)", m.selected.DisplayName(), m.selected.ShortID())
+if m.selected.Model != "" {
+chatTitle += " · " + m.selected.Model
+}
 chatContent := m.chat.View()
 chatView := theme.RenderTitledBorder(chatTitle, chatContent, chatInnerW, chatInnerH, m.focus == FocusChat)
 
+var inputView string
+if m.approval != nil {
+inputView = theme.RenderTitledBorder("Approval", m.approval.View(), chatInnerW, inputInnerH, m.focus == FocusInput)
+} else if m.question != nil {
+inputView = theme.RenderTitledBorder("Question", m.question.View(), chatInnerW, inputInnerH, m.focus == FocusInput)
+} else {
 inputContent := m.input.View()
-inputView := theme.RenderTitledBorder("Input", inputContent, chatInnerW, inputInnerH, m.focus == FocusInput)
+if q := m.queueList.View(); q != "" {
+inputContent = q + "\n" + inputContent
+}
+inputTitle := "Input"
+if m.editingQueued >= 0 {
+inputTitle = fmt.Sprintf("Input · editing queued #%d (Enter save, Esc discard)", m.editingQueued+1)
+}
+inputView = theme.RenderTitledBorder(inputTitle, inputContent, chatInnerW, inputInnerH, m.focus == FocusInput)
+}
 
 rightPanel = lipgloss.JoinVertical(lipgloss.Left, chatView, inputView)
 }
@@ -118,6 +158,11 @@ case FocusChat:
 focusLabel = "chat"
 case FocusInput:
 focusLabel = "input"
+if m.approval != nil {
+focusLabel = "approval"
+} else if m.question != nil {
+focusLabel = "question"
+}
 }
 
 sessionInfo := ""
@@ -126,12 +171,25 @@ sessionInfo = fmt.Sprintf(" · 💬 %s (%s)", m.selected.DisplayName(), m.select
 }
 
 modeLabel := " · 🔗 SDK"
+if m.readOnly() {
+modeLabel = " · 📂 read-only"
+}
 if m.renaming {
 modeLabel = " · ✏️ renaming"
 }
+if m.broadcast != nil && m.broadcast.Closed() {
+modeLabel += " · " + m.broadcast.Summary()
+}
+if n := len(m.sidebar.Marked()); n > 0 {
+modeLabel += fmt.Sprintf(" · ✔ %d marked (B broadcast, Esc clear)", n)
+}
 if m.statusFlash != "" {
 modeLabel = " · " + m.statusFlash
 }
+if m.confirmDelete != nil {
+modeLabel = " · " + lipgloss.NewStyle().Foreground(theme.Error).Bold(true).
+Render(fmt.Sprintf("🗑  Delete %s (%s)? This cannot be undone. y/n", m.confirmDelete.DisplayName(), m.confirmDelete.ShortID()))
+}
 
 statusBar := theme.StatusBarStyle.
 Width(m.width).
@@ -149,14 +207,35 @@ bindings := []struct{ key, desc string }{
 {"Tab", "Switch panel forward (sidebar → chat → input)"},
 {"Shift+Tab", "Switch panel backward (input → chat → sidebar)"},
 {"Enter", "Open session (sidebar) / Send message (input)"},
+{"Alt+Enter", "Insert a newline in the message (Ctrl+J also works)"},
+{"↑ ↓ (input)", "Previous / next prompt sent to this session"},
+{"Ctrl+E", "Write the message in $EDITOR, sent when you save and quit"},
+{"Ctrl+O", "Edit the queued prompts (e edit · d delete · K/J move)"},
+{"Ctrl+X", "Cancel the next queued prompt"},
 {"Esc", "Go back (input → chat, cancel rename)"},
 {"↑ ↓", "Navigate sessions / scroll chat"},
 {"/ (sidebar)", "Filter sessions by name"},
+{"/ (chat)", "Search the conversation, including tool output"},
+{"n / N (chat)", "Next / previous search match"},
+{"F (Shift+F)", "Search every session's history and open a match"},
 {"?", "Toggle this help overlay"},
 {"t", "Toggle tool call details (expand/collapse)"},
+{"J / K (chat)", "Select the next / previous message or tool call"},
+{"o (chat)", "Fold or unfold the selected tool call (all of a message's)"},
+{"Enter (chat)", "Show the selected tool call or message in full (edits as a diff)"},
+{"d (chat)", "Files changed in this session, as diffs (v unified / side by side)"},
+{"a s d r", "Approval: allow once / for session / deny / deny with reason"},
+{"1-9 ↑ ↓", "Question: pick a choice (or type a free-form answer)"},
+{"n (sidebar)", "Start a new session (directory, model, system prompt)"},
+{"m", "Pick the model for the highlighted or open session"},
+{"Space (sidebar)", "Mark or unmark a session for a broadcast"},
+{"B (Shift+B)", "Broadcast a prompt to the marked sessions / show its progress"},
 {"r", "Refresh session list"},
 {"R (Shift+R)", "Rename selected session"},
-{"e", "Export conversation to markdown"},
+{"x / X", "Archive or restore session / show archived sessions"},
+{"M", "Mute or unmute notifications for session"},
+{"D (Shift+D)", "Delete selected session (asks to confirm)"},
+{"e", "Export conversation (format and filename from the config)"},
 {"Ctrl+C", "Abort in-flight request / Force quit"},
 {"q", "Quit (not active in input mode)"},
 }
@@ -7,11 +7,12 @@ import (
 
 tea "github.com/charmbracelet/bubbletea"
 "github.com/charmbracelet/lipgloss"
+"github.com/e-9/copilot-icq/internal/copilot/copilottest"
 "github.com/e-9/copilot-icq/internal/domain"
 )
 
 func TestViewFitsTerminalHeight(t *testing.T) {
-m := NewModel("", nil, nil)
+m := NewModel("", nil, copilottest.New(), nil)
 
 sizes := []struct {
 w, h int
@@ -38,7 +39,7 @@ sz.w, sz.h, renderedH, sz.h, renderedH-sz.h)
 }
 
 func TestViewFitsWithManySessions(t *testing.T) {
-m := NewModel("", nil, nil)
+m := NewModel("", nil, copilottest.New(), nil)
 
 model, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
 m = model.(Model)
@@ -67,7 +68,7 @@ renderedH, renderedH-24)
 }
 
 func TestViewFitsWithSessions(t *testing.T) {
-m := NewModel("", nil, nil)
+m := NewModel("", nil, copilottest.New(), nil)
 
 model, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
 m = model.(Model)
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/infra/webhook"
+)
+
+// SetWebhooks sets where session events are published; nil publishes
+// nothing.
+func (m *Model) SetWebhooks(d *webhook.Dispatcher) {
+	m.webhooks = d
+}
+
+// publishEvent forwards a backend event to the webhooks. It runs before the
+// event is applied, so the turn's reply is still known on idle. Publishing
+// never blocks the update loop.
+func (m Model) publishEvent(evt copilot.Event) {
+	if m.webhooks == nil {
+		return
+	}
+	env, ok := webhook.EnvelopeFor(evt, m.lastReply[evt.SessionID])
+	if !ok {
+		return
+	}
+	for _, s := range m.sessions {
+		if s.ID == evt.SessionID {
+			env.SessionName, env.CWD = s.DisplayName(), s.CWD
+		}
+	}
+	m.webhooks.Publish(env)
+}
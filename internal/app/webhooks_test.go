@@ -0,0 +1,51 @@
+package app
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/infra/webhook"
+)
+
+func TestIdlePublishesTheReply(t *testing.T) {
+	got := make(chan webhook.Envelope, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var env webhook.Envelope
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &env)
+		got <- env
+	}))
+	defer srv.Close()
+
+	m, _ := resumedModel(t)
+	d := webhook.New([]webhook.Endpoint{{URL: srv.URL, Events: map[string]bool{webhook.SessionIdle: true}}}, nil, time.Millisecond)
+	defer d.Close(time.Second)
+	m.SetWebhooks(d)
+
+	reply := sdk.SessionEvent{Type: sdk.AssistantMessage, Data: sdk.Data{Content: str("All green")}}
+	for _, ev := range []sdk.SessionEvent{reply, {Type: sdk.SessionIdle}} {
+		model, _ := m.Update(SDKEventMsg{Event: copilot.Event{Type: copilot.EventSession, SessionID: "b", SessionEvent: &ev}})
+		m = model.(Model)
+	}
+
+	select {
+	case env := <-got:
+		if env.Event != webhook.SessionIdle || env.SessionID != "b" || env.Data.Reply != "All green" {
+			t.Errorf("envelope = %+v", env)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook delivery")
+	}
+	select {
+	case env := <-got:
+		t.Errorf("unexpected delivery %+v; the reply is filtered out", env)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
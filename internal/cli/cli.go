@@ -0,0 +1,395 @@
+// Package cli implements the non-interactive subcommands, list, send, tail
+// and export, on top of a copilot.Backend so they work against the SDK
+// adapter or a daemon alike.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/exporter"
+)
+
+// Runner runs subcommands against a started backend.
+type Runner struct {
+	Backend copilot.Backend
+	Out     io.Writer
+	// AnswerPrompts denies permission requests and questions in the
+	// session a command works on, since nobody is there to answer them.
+	// Leave it unset when attached to a daemon, whose TUIs can answer.
+	AnswerPrompts bool
+	// OwnsBackend is set when the command started the backend itself, so
+	// closing it stops the session's turn. Send then waits for the turn to
+	// finish even without wait.
+	OwnsBackend bool
+}
+
+// promptReason answers the agent's questions. Permission requests are
+// denied without it, as the SDK cannot tell the agent why.
+const promptReason = "copilot-icq cannot ask for approval from the command line; allow the tool in the config or answer from the TUI"
+
+// listed is a session as List prints it in JSON.
+type listed struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CWD       string    `json:"cwd,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// List prints the sessions as a table, or as a JSON array with asJSON.
+func (r *Runner) List(ctx context.Context, asJSON bool) error {
+	sessions, err := r.Backend.ListSessions(ctx)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		out := make([]listed, 0, len(sessions))
+		for _, s := range sessions {
+			out = append(out, listed{
+				ID:        s.ID,
+				Name:      s.DisplayName(),
+				CWD:       s.CWD,
+				Model:     s.Model,
+				CreatedAt: s.CreatedAt,
+				UpdatedAt: s.UpdatedAt,
+			})
+		}
+		enc := json.NewEncoder(r.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	tw := tabwriter.NewWriter(r.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tUPDATED\tMODEL\tNAME\tCWD")
+	for _, s := range sessions {
+		updated := "-"
+		if !s.UpdatedAt.IsZero() {
+			updated = s.UpdatedAt.Local().Format("2006-01-02 15:04")
+		}
+		model := s.Model
+		if model == "" {
+			model = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.ShortID(), updated, model, s.DisplayName(), s.CWD)
+	}
+	return tw.Flush()
+}
+
+// Send sends text to the session ref names. With wait it blocks until the
+// session is idle and prints the reply. Without a daemon it always waits,
+// printing nothing, since the turn ends with the command.
+func (r *Runner) Send(ctx context.Context, ref, text string, wait bool) error {
+	if r.Backend.ReadOnly() {
+		return copilot.ErrReadOnly
+	}
+	s, err := r.resolve(ctx, ref)
+	if err != nil {
+		return err
+	}
+	if _, err := r.Backend.ResumeSession(ctx, s.ID, ""); err != nil {
+		return err
+	}
+	if _, err := r.Backend.Send(ctx, s.ID, text); err != nil {
+		return err
+	}
+	if !wait && !r.OwnsBackend {
+		return nil
+	}
+
+	reply := ""
+	for {
+		var ev copilot.Event
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-r.Backend.Events():
+			if !ok {
+				return errors.New("backend closed before the session was idle")
+			}
+			ev = e
+		}
+		if ev.SessionID != s.ID {
+			continue
+		}
+		r.answer(ev)
+		if ev.Type != copilot.EventSession || ev.SessionEvent == nil {
+			continue
+		}
+		e := ev.SessionEvent
+		switch e.Type {
+		case sdk.AssistantMessage:
+			if e.Data.Content != nil && *e.Data.Content != "" {
+				reply = *e.Data.Content
+			}
+		case sdk.SessionError:
+			msg := "session error"
+			if e.Data.Message != nil {
+				msg = *e.Data.Message
+			}
+			return errors.New(msg)
+		case sdk.SessionIdle:
+			if wait && reply != "" {
+				fmt.Fprintln(r.Out, reply)
+			}
+			return nil
+		}
+	}
+}
+
+// Tail streams the replies and tool activity of the session ref names until
+// ctx is cancelled, the session is deleted or the backend goes away.
+func (r *Runner) Tail(ctx context.Context, ref string) error {
+	s, err := r.resolve(ctx, ref)
+	if err != nil {
+		return err
+	}
+	if _, err := r.Backend.ResumeSession(ctx, s.ID, ""); err != nil {
+		return err
+	}
+
+	// streaming is set while a reply is being printed delta by delta
+	streaming := false
+	endLine := func() {
+		if streaming {
+			fmt.Fprintln(r.Out)
+			streaming = false
+		}
+	}
+	for {
+		var ev copilot.Event
+		select {
+		case <-ctx.Done():
+			endLine()
+			return nil
+		case e, ok := <-r.Backend.Events():
+			if !ok {
+				endLine()
+				return nil
+			}
+			ev = e
+		}
+		if ev.SessionID != s.ID {
+			continue
+		}
+
+		switch {
+		case ev.Type == copilot.EventLifecycle && ev.Lifecycle != nil:
+			if ev.Lifecycle.Type == sdk.SessionLifecycleDeleted {
+				endLine()
+				fmt.Fprintln(r.Out, "— session deleted")
+				return nil
+			}
+
+		case ev.Type == copilot.EventPermission && ev.Permission != nil:
+			p := ev.Permission
+			endLine()
+			if p.Denied {
+				fmt.Fprintf(r.Out, "⛔ %s denied: %s\n", p.ToolName, p.DenyReason)
+			} else {
+				fmt.Fprintf(r.Out, "? %s wants permission: %s\n", p.ToolName, oneLine(p.Args))
+			}
+			r.answer(ev)
+
+		case ev.Type == copilot.EventUserInput && ev.UserInput != nil:
+			endLine()
+			fmt.Fprintf(r.Out, "? %s\n", oneLine(ev.UserInput.Question))
+			r.answer(ev)
+
+		case ev.Type == copilot.EventSession && ev.SessionEvent != nil:
+			e := ev.SessionEvent
+			switch e.Type {
+			case sdk.AssistantMessageDelta:
+				if e.Data.DeltaContent != nil {
+					io.WriteString(r.Out, *e.Data.DeltaContent)
+					streaming = true
+				}
+			case sdk.AssistantMessage:
+				// Backends without deltas only deliver the whole message
+				if !streaming && e.Data.Content != nil && *e.Data.Content != "" {
+					io.WriteString(r.Out, *e.Data.Content)
+					streaming = true
+				}
+				endLine()
+			case sdk.ToolExecutionStart:
+				endLine()
+				fmt.Fprintf(r.Out, "→ %s %s\n", str(e.Data.ToolName), oneLine(args(e.Data.Arguments)))
+			case sdk.ToolExecutionComplete:
+				endLine()
+				mark := "✓"
+				if e.Data.Success != nil && !*e.Data.Success {
+					mark = "✗"
+				}
+				fmt.Fprintf(r.Out, "%s %s\n", mark, str(e.Data.ToolName))
+			case sdk.SessionError:
+				endLine()
+				fmt.Fprintf(r.Out, "! %s\n", str(e.Data.Message))
+			case sdk.SessionIdle:
+				endLine()
+				fmt.Fprintln(r.Out, "— idle")
+			}
+		}
+	}
+}
+
+// ExportOptions selects what Export writes and where.
+type ExportOptions struct {
+	// Session names one session, as for Send. When empty, Filter selects
+	// the sessions; an empty Filter exports them all.
+	Session  string
+	Filter   string
+	Format   exporter.Format
+	Dir      string // directory the files are written to
+	Template string // filename template; empty uses exporter.DefaultTemplate
+	// Stdout writes to Out instead of files. JSON and HTML allow only one
+	// session this way.
+	Stdout bool
+}
+
+// Export writes the history of the selected sessions and prints the path
+// of each file it writes.
+func (r *Runner) Export(ctx context.Context, opts ExportOptions) error {
+	var sessions []domain.Session
+	if opts.Session != "" {
+		s, err := r.resolve(ctx, opts.Session)
+		if err != nil {
+			return err
+		}
+		sessions = []domain.Session{s}
+	} else {
+		filter, err := exporter.ParseFilter(opts.Filter, time.Now())
+		if err != nil {
+			return err
+		}
+		all, err := r.Backend.ListSessions(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range all {
+			if filter.Match(s) {
+				sessions = append(sessions, s)
+			}
+		}
+		if len(sessions) == 0 {
+			return errors.New("no sessions match")
+		}
+	}
+
+	if opts.Stdout {
+		if len(sessions) > 1 && (opts.Format == exporter.JSON || opts.Format == exporter.HTML) {
+			return fmt.Errorf("%d sessions match; %s can only be written to stdout one session at a time", len(sessions), opts.Format)
+		}
+	} else {
+		// Refuse before writing anything if the template would overwrite
+		seen := make(map[string]string)
+		for _, s := range sessions {
+			name, err := exporter.Filename(opts.Template, s, opts.Format)
+			if err != nil {
+				return err
+			}
+			if other, ok := seen[name]; ok {
+				return fmt.Errorf("sessions %s and %s would both be exported to %s; add {{.ShortID}} to the template", other, s.ShortID(), name)
+			}
+			seen[name] = s.ShortID()
+		}
+	}
+
+	for _, s := range sessions {
+		msgs, err := r.Backend.GetHistory(ctx, s.ID)
+		if err != nil {
+			return err
+		}
+		c := exporter.Conversation{Session: s, Messages: msgs}
+		if opts.Stdout {
+			if err := exporter.Write(r.Out, opts.Format, c); err != nil {
+				return err
+			}
+			continue
+		}
+		path, err := exporter.WriteFile(opts.Dir, opts.Template, opts.Format, c)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(r.Out, path)
+	}
+	return nil
+}
+
+// answer denies a prompt nobody else can answer.
+func (r *Runner) answer(ev copilot.Event) {
+	if !r.AnswerPrompts {
+		return
+	}
+	switch {
+	case ev.Permission != nil && ev.Permission.Response != nil:
+		ev.Permission.Response <- copilot.PermissionResponse{}
+	case ev.UserInput != nil && ev.UserInput.Response != nil:
+		ev.UserInput.Response <- copilot.UserInputResponse{Answer: promptReason, WasFreeform: true}
+	}
+}
+
+// resolve finds the session ref names: its ID or an unambiguous prefix of it.
+func (r *Runner) resolve(ctx context.Context, ref string) (domain.Session, error) {
+	sessions, err := r.Backend.ListSessions(ctx)
+	if err != nil {
+		return domain.Session{}, err
+	}
+	var matches []domain.Session
+	for _, s := range sessions {
+		if s.ID == ref {
+			return s, nil
+		}
+		if ref != "" && strings.HasPrefix(s.ID, ref) {
+			matches = append(matches, s)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return domain.Session{}, fmt.Errorf("no session %q", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return domain.Session{}, fmt.Errorf("%q matches %d sessions; use more of the ID", ref, len(matches))
+	}
+}
+
+// maxArgs caps how much of a tool's arguments Tail prints.
+const maxArgs = 120
+
+func args(v any) string {
+	if v == nil {
+		return ""
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(raw)
+}
+
+func oneLine(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > maxArgs {
+		s = string(r[:maxArgs-1]) + "…"
+	}
+	return s
+}
+
+func str(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
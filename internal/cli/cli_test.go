@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/copilot/copilottest"
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/exporter"
+)
+
+func ptr(s string) *string { return &s }
+
+func newRunner() (*Runner, *copilottest.Backend, *strings.Builder) {
+	fake := copilottest.New(
+		domain.Session{ID: "abc123", CWD: "/repo", Summary: "Fix tests", UpdatedAt: time.Now()},
+		domain.Session{ID: "abd456", CWD: "/other"},
+	)
+	out := &strings.Builder{}
+	return &Runner{Backend: fake, Out: out, AnswerPrompts: true}, fake, out
+}
+
+func TestList(t *testing.T) {
+	r, _, out := newRunner()
+	if err := r.List(context.Background(), false); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "Fix tests") {
+		t.Errorf("table =\n%s", out)
+	}
+
+	out.Reset()
+	if err := r.List(context.Background(), true); err != nil {
+		t.Fatal(err)
+	}
+	var got []listed
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].ID != "abd456" || got[1].Name != "other" {
+		t.Errorf("json = %+v", got)
+	}
+}
+
+func TestSendWait(t *testing.T) {
+	r, fake, out := newRunner()
+	answer := make(chan copilot.PermissionResponse, 1)
+	fake.EmitSession("abd456", sdk.SessionEvent{Type: sdk.AssistantMessage, Data: sdk.Data{Content: ptr("not mine")}})
+	fake.Emit(copilot.Event{Type: copilot.EventPermission, SessionID: "abc123",
+		Permission: &copilot.PermissionEvent{ToolName: "bash", Response: answer}})
+	fake.EmitSession("abc123", sdk.SessionEvent{Type: sdk.AssistantMessage, Data: sdk.Data{Content: ptr("done")}})
+	fake.EmitSession("abc123", sdk.SessionEvent{Type: sdk.SessionIdle})
+
+	if err := r.Send(context.Background(), "abc", "run it", true); err != nil {
+		t.Fatal(err)
+	}
+	if sent := fake.Sent(); len(sent) != 1 || sent[0].SessionID != "abc123" || sent[0].Text != "run it" {
+		t.Errorf("sent %+v", sent)
+	}
+	if out.String() != "done\n" {
+		t.Errorf("output = %q", out)
+	}
+	if resp := <-answer; resp.Allow {
+		t.Error("prompts should be denied without a TUI")
+	}
+
+	fake.EmitSession("abc123", sdk.SessionEvent{Type: sdk.SessionError, Data: sdk.Data{Message: ptr("quota")}})
+	if err := r.Send(context.Background(), "abc123", "again", true); err == nil || err.Error() != "quota" {
+		t.Errorf("err = %v, want the session error", err)
+	}
+}
+
+func TestSendWithoutDaemonWaitsForIdle(t *testing.T) {
+	r, fake, out := newRunner()
+	r.OwnsBackend = true
+	done := make(chan error, 1)
+	go func() {
+		// As runCLI does: the backend is closed once Send returns
+		err := r.Send(context.Background(), "abc", "run it", false)
+		fake.Close()
+		done <- err
+	}()
+
+	fake.EmitSession("abc123", sdk.SessionEvent{Type: sdk.AssistantMessage, Data: sdk.Data{Content: ptr("working")}})
+	select {
+	case <-done:
+		t.Fatal("Send returned before the turn finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if fake.Closed() {
+		t.Fatal("the backend was closed mid-turn")
+	}
+
+	fake.EmitSession("abc123", sdk.SessionEvent{Type: sdk.SessionIdle})
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send did not return once idle")
+	}
+	if out.String() != "" {
+		t.Errorf("output = %q, want nothing without --wait", out)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	r, _, _ := newRunner()
+	if err := r.Send(context.Background(), "ab", "x", false); err == nil {
+		t.Error("an ambiguous prefix should fail")
+	}
+	if err := r.Send(context.Background(), "zzz", "x", false); err == nil {
+		t.Error("an unknown session should fail")
+	}
+}
+
+func TestTail(t *testing.T) {
+	r, fake, out := newRunner()
+	ok := false
+	fake.EmitSession("abc123", sdk.SessionEvent{Type: sdk.AssistantMessageDelta, Data: sdk.Data{DeltaContent: ptr("Hel")}})
+	fake.EmitSession("abc123", sdk.SessionEvent{Type: sdk.AssistantMessageDelta, Data: sdk.Data{DeltaContent: ptr("lo")}})
+	fake.EmitSession("abc123", sdk.SessionEvent{Type: sdk.AssistantMessage, Data: sdk.Data{Content: ptr("Hello")}})
+	fake.EmitSession("abc123", sdk.SessionEvent{Type: sdk.ToolExecutionStart, Data: sdk.Data{ToolName: ptr("bash"), Arguments: map[string]any{"command": "ls"}}})
+	fake.EmitSession("abc123", sdk.SessionEvent{Type: sdk.ToolExecutionComplete, Data: sdk.Data{ToolName: ptr("bash"), Success: &ok}})
+	fake.EmitSession("abc123", sdk.SessionEvent{Type: sdk.SessionIdle})
+	fake.Emit(copilot.Event{Type: copilot.EventLifecycle, SessionID: "abc123",
+		Lifecycle: &sdk.SessionLifecycleEvent{Type: sdk.SessionLifecycleDeleted, SessionID: "abc123"}})
+
+	if err := r.Tail(context.Background(), "abc"); err != nil {
+		t.Fatal(err)
+	}
+	want := "Hello\n→ bash {\"command\":\"ls\"}\n✗ bash\n— idle\n— session deleted\n"
+	if out.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestSendReadOnly(t *testing.T) {
+	r, fake, _ := newRunner()
+	fake.ReadOnlyMode = true
+	if err := r.Send(context.Background(), "abc123", "x", false); err != copilot.ErrReadOnly {
+		t.Errorf("err = %v, want ErrReadOnly", err)
+	}
+}
+
+func TestExport(t *testing.T) {
+	r, fake, out := newRunner()
+	fake.History["abc123"] = []domain.Message{{Role: domain.RoleUser, Content: "hi"}}
+	dir := t.TempDir()
+
+	err := r.Export(context.Background(), ExportOptions{Filter: "cwd:/repo", Format: exporter.JSONL, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(dir, "copilot-session-abc123.jsonl")
+	if strings.TrimSpace(out.String()) != want {
+		t.Errorf("printed %q, want %s", out, want)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Error(err)
+	}
+
+	err = r.Export(context.Background(), ExportOptions{Format: exporter.Markdown, Dir: dir, Template: "all.{{.Ext}}"})
+	if err == nil || !strings.Contains(err.Error(), "would both be exported") {
+		t.Errorf("err = %v, want a clash", err)
+	}
+	err = r.Export(context.Background(), ExportOptions{Format: exporter.HTML, Stdout: true})
+	if err == nil {
+		t.Error("several HTML pages on stdout should fail")
+	}
+
+	out.Reset()
+	err = r.Export(context.Background(), ExportOptions{Session: "abc", Format: exporter.Markdown, Stdout: true})
+	if err != nil || !strings.Contains(out.String(), "### 🧑 You") {
+		t.Errorf("stdout export = %q, %v", out, err)
+	}
+}
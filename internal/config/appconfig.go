@@ -3,6 +3,7 @@ package config
 import (
 "os"
 "path/filepath"
+"strings"
 
 "gopkg.in/yaml.v3"
 )
@@ -10,18 +11,100 @@ import (
 // AppConfig holds user-configurable settings loaded from ~/.copilot-icq/config.yaml.
 type AppConfig struct {
 ExportDir     string `yaml:"export_dir"`       // directory for conversation exports
+ExportFormat   string `yaml:"export_format"`   // md, json, jsonl or html; the e key and the export subcommand default to it
+ExportTemplate string `yaml:"export_template"` // export filename template, e.g. "{{.Project}}/{{.Date}}-{{.ShortID}}.{{.Ext}}"
+DefaultModel  string `yaml:"default_model"`    // model for new and resumed sessions; empty uses the CLI default
+UseSDK        bool   `yaml:"use_sdk"`          // drive Copilot CLI through the SDK; false reads session files read-only
+
+// Tool permission policy (see internal/policy)
+SecurityMode   string          `yaml:"security_mode"`   // "scoped" or "full-auto"
+AllowedTools   []string        `yaml:"allowed_tools"`   // auto-approved in scoped mode
+DeniedTools    []string        `yaml:"denied_tools"`    // always denied
+DeniedPatterns []string        `yaml:"denied_patterns"` // argument patterns that are always denied
+Projects       []ProjectConfig `yaml:"projects"`        // per-CWD overrides
+
 Notifications struct {
-OS    bool   `yaml:"os"`    // enable OS desktop notifications
-Push  bool   `yaml:"push"`  // enable ntfy.sh push notifications
-Topic string `yaml:"topic"` // ntfy.sh topic
+OS     bool     `yaml:"os"`     // enable OS desktop notifications
+Push   bool     `yaml:"push"`   // enable ntfy push notifications
+Server string   `yaml:"server"` // ntfy server URL; empty uses https://ntfy.sh
+Topic  string   `yaml:"topic"`  // ntfy topic
+Token  string   `yaml:"token"`  // optional ntfy access token
+Click  string   `yaml:"click"`  // optional URL opened when a push notification is tapped
+Events []string `yaml:"events"` // pushed events: reply, permission, question, error; empty pushes all
 } `yaml:"notifications"`
+
+Webhooks []WebhookConfig `yaml:"webhooks"` // endpoints that receive session events
+}
+
+// WebhookConfig is an HTTP endpoint that session events are POSTed to.
+type WebhookConfig struct {
+URL    string   `yaml:"url"`
+Events []string `yaml:"events,omitempty"` // session.idle, session.error, permission.requested, tool.completed; empty sends all
+Secret string   `yaml:"secret,omitempty"` // signs each body with HMAC-SHA256 when set
+}
+
+// ProjectConfig overrides settings for sessions whose CWD is Path or below it.
+type ProjectConfig struct {
+Path           string   `yaml:"path"`
+Model          string   `yaml:"model,omitempty"`
+SecurityMode   string   `yaml:"security_mode,omitempty"`
+AllowedTools   []string `yaml:"allowed_tools,omitempty"`
+DeniedTools    []string `yaml:"denied_tools,omitempty"`
+DeniedPatterns []string `yaml:"denied_patterns,omitempty"`
 }
 
 // DefaultAppConfig returns the default configuration.
 func DefaultAppConfig() *AppConfig {
 return &AppConfig{
-ExportDir: ".",
+ExportDir:    ".",
+ExportFormat: "md",
+SecurityMode: "scoped",
+UseSDK:       true,
+}
+}
+
+// ExpandHome replaces a leading ~ in path with the user's home directory.
+func ExpandHome(path string) string {
+if path != "~" && !strings.HasPrefix(path, "~/") {
+return path
+}
+home, err := os.UserHomeDir()
+if err != nil {
+return path
+}
+return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// WithinDir reports whether path is dir or inside it.
+func WithinDir(path, dir string) bool {
+path = filepath.Clean(path)
+dir = filepath.Clean(dir)
+if path == dir {
+return true
+}
+rel, err := filepath.Rel(dir, path)
+return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ModelFor returns the model to use for sessions in cwd: the model of the
+// most specific project containing cwd, else DefaultModel.
+func (c *AppConfig) ModelFor(cwd string) string {
+if c == nil {
+return ""
+}
+model := c.DefaultModel
+best := -1
+for _, p := range c.Projects {
+dir := filepath.Clean(ExpandHome(p.Path))
+if p.Model == "" || cwd == "" || !WithinDir(cwd, dir) {
+continue
+}
+if len(dir) > best {
+best = len(dir)
+model = p.Model
+}
 }
+return model
 }
 
 // AppConfigPath returns the default config file path.
@@ -0,0 +1,35 @@
+package config
+
+import "testing"
+
+func TestModelFor(t *testing.T) {
+	cfg := &AppConfig{
+		DefaultModel: "gpt-5",
+		Projects: []ProjectConfig{
+			{Path: "/src", Model: "claude-sonnet-4.5"},
+			{Path: "/src/infra", Model: "claude-opus-4.1"},
+			{Path: "/src/docs", SecurityMode: "full-auto"},
+		},
+	}
+
+	tests := []struct {
+		cwd  string
+		want string
+	}{
+		{"/home/me", "gpt-5"},
+		{"/src/app", "claude-sonnet-4.5"},
+		{"/src/infra/k8s", "claude-opus-4.1"},
+		{"/src/docs", "claude-sonnet-4.5"}, // project without a model inherits
+		{"/srcfoo", "gpt-5"},
+	}
+	for _, tt := range tests {
+		if got := cfg.ModelFor(tt.cwd); got != tt.want {
+			t.Errorf("ModelFor(%q) = %q, want %q", tt.cwd, got, tt.want)
+		}
+	}
+
+	var none *AppConfig
+	if got := none.ModelFor("/src"); got != "" {
+		t.Errorf("nil config ModelFor = %q, want empty", got)
+	}
+}
@@ -2,9 +2,13 @@ package config
 
 import (
 "fmt"
+"net"
 "os"
 "os/exec"
 "strings"
+"time"
+
+"github.com/e-9/copilot-icq/internal/infra/hookserver"
 )
 
 // CheckResult represents a single prerequisite check.
@@ -103,5 +107,33 @@ Detail: fmt.Sprintf("%s not found — start a Copilot CLI session first", sessio
 }
 }
 
+// 5. Check the hook companion binary
+if hookBin, err := exec.LookPath("copilot-icq-hook"); err == nil {
+results = append(results, CheckResult{
+Name:   "Hook companion",
+OK:     true,
+Detail: hookBin,
+})
+} else {
+results = append(results, CheckResult{
+Name:   "Hook companion",
+OK:     false,
+Detail: "copilot-icq-hook not found in PATH — build it with: make build-hook",
+})
+}
+
+// 6. Check the hook server socket
+sockPath := hookserver.SocketPath()
+detail := sockPath + " (created when copilot-icq starts)"
+if conn, err := net.DialTimeout("unix", sockPath, 200*time.Millisecond); err == nil {
+conn.Close()
+detail = sockPath + " (copilot-icq is listening)"
+}
+results = append(results, CheckResult{
+Name:   "Hook socket",
+OK:     true,
+Detail: detail,
+})
+
 return results
 }
@@ -5,25 +5,39 @@ package copilot
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/json"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	sdk "github.com/github/copilot-sdk/go"
 
+	"github.com/e-9/copilot-icq/internal/config"
+	"github.com/e-9/copilot-icq/internal/diff"
 	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/policy"
+	sdkrpc "github.com/github/copilot-sdk/go/rpc"
 )
 
 // Adapter wraps the official Copilot SDK client and provides a simplified API
 // for the TUI app layer.
 type Adapter struct {
-	client   *sdk.Client
-	sessions map[string]*sdk.Session // sessionID → active SDK session
-	mu       sync.Mutex
-
-	// Events is a buffered channel that receives session events.
-	// The app layer reads from this channel to convert events into tea.Msg.
-	Events chan Event
+	client    *sdk.Client
+	sessions  map[string]*sdk.Session    // sessionID → active SDK session
+	approved  map[string]map[string]bool // sessionID → permission keys allowed for the session
+	toolCalls map[string]toolCallInfo    // toolCallID → tool seen in tool.execution_start
+	cwds      map[string]string          // sessionID → working directory, for policy overrides
+	models    map[string]string          // sessionID → model last reported by the session
+	policy    *policy.Policy
+	mu        sync.Mutex
+
+	// events is a buffered channel that receives session events.
+	// The app layer reads from it to convert events into tea.Msg.
+	events chan Event
 }
 
 // New creates a new Adapter. Call Start() to connect to the Copilot CLI.
@@ -35,12 +49,41 @@ func New() *Adapter {
 		LogLevel:    "error",
 	})
 	return &Adapter{
-		client:   client,
-		sessions: make(map[string]*sdk.Session),
-		Events:   make(chan Event, 64),
+		client:    client,
+		sessions:  make(map[string]*sdk.Session),
+		approved:  make(map[string]map[string]bool),
+		toolCalls: make(map[string]toolCallInfo),
+		cwds:      make(map[string]string),
+		models:    make(map[string]string),
+		events:    make(chan Event, 64),
 	}
 }
 
+// toolCallInfo remembers the name and arguments of a started tool call so that
+// permission requests, which only carry a tool call ID, can be described.
+type toolCallInfo struct {
+	Name string
+	Args string
+}
+
+// Events returns the channel session events are delivered on.
+func (a *Adapter) Events() <-chan Event {
+	return a.events
+}
+
+// ReadOnly reports false: the SDK can send, abort and manage sessions.
+func (a *Adapter) ReadOnly() bool {
+	return false
+}
+
+// SetPolicy sets the security policy consulted before asking the user about
+// a tool. A nil policy asks about every tool.
+func (a *Adapter) SetPolicy(p *policy.Policy) {
+	a.mu.Lock()
+	a.policy = p
+	a.mu.Unlock()
+}
+
 // Start connects to the Copilot CLI subprocess.
 func (a *Adapter) Start(ctx context.Context) error {
 	if err := a.client.Start(ctx); err != nil {
@@ -49,7 +92,7 @@ func (a *Adapter) Start(ctx context.Context) error {
 
 	// Subscribe to session lifecycle events (created, deleted, updated)
 	a.client.On(func(event sdk.SessionLifecycleEvent) {
-		a.Events <- Event{
+		a.events <- Event{
 			Type:      EventLifecycle,
 			SessionID: event.SessionID,
 			Lifecycle: &event,
@@ -80,44 +123,217 @@ func (a *Adapter) ListSessions(ctx context.Context) ([]domain.Session, error) {
 	}
 
 	sessions := make([]domain.Session, 0, len(metas))
+	a.mu.Lock()
 	for _, m := range metas {
-		sessions = append(sessions, metadataToSession(m))
+		s := metadataToSession(m)
+		s.Model = a.models[s.ID]
+		a.cwds[s.ID] = s.CWD
+		sessions = append(sessions, s)
 	}
+	a.mu.Unlock()
 	return sessions, nil
 }
 
 // ResumeSession resumes an existing session and subscribes to its events.
-// Events are forwarded to the Events channel for the app layer.
-func (a *Adapter) ResumeSession(ctx context.Context, sessionID string) error {
+// Events are forwarded to the Events channel for the app layer. A non-empty
+// model switches the session to that model; an empty one keeps its current model.
+// It returns the model the session is using, when the CLI reports it.
+func (a *Adapter) ResumeSession(ctx context.Context, sessionID, model string) (string, error) {
 	a.mu.Lock()
-	if _, ok := a.sessions[sessionID]; ok {
-		a.mu.Unlock()
-		return nil // already resumed
-	}
+	_, ok := a.sessions[sessionID]
 	a.mu.Unlock()
+	if ok {
+		// Already resumed
+		if model != "" {
+			return model, a.SwitchModel(ctx, sessionID, model)
+		}
+		return a.Model(sessionID), nil
+	}
 
 	session, err := a.client.ResumeSessionWithOptions(ctx, sessionID, &sdk.ResumeSessionConfig{
-		Streaming: true,
+		Model:               model,
+		Streaming:           true,
 		OnPermissionRequest: a.makePermissionHandler(sessionID),
 		OnUserInputRequest:  a.makeUserInputHandler(sessionID),
 	})
 	if err != nil {
-		return fmt.Errorf("resume session %s: %w", sessionID, err)
+		return "", fmt.Errorf("resume session %s: %w", sessionID, err)
 	}
 
+	a.subscribe(sessionID, session)
+
+	if cur, err := session.RPC.Model.GetCurrent(ctx); err == nil && cur.ModelID != nil {
+		model = *cur.ModelID
+	}
+	if model != "" {
+		a.setModel(sessionID, model)
+	}
+	return a.Model(sessionID), nil
+}
+
+// ModelInfo describes a model the CLI can use.
+type ModelInfo struct {
+	ID   string
+	Name string
+}
+
+// ListModels returns the models available to new and resumed sessions.
+func (a *Adapter) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	infos, err := a.client.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list models: %w", err)
+	}
+	models := make([]ModelInfo, 0, len(infos))
+	for _, m := range infos {
+		models = append(models, ModelInfo{ID: m.ID, Name: m.Name})
+	}
+	return models, nil
+}
+
+// SwitchModel changes the model of a resumed session. The switch applies
+// from the next message on.
+func (a *Adapter) SwitchModel(ctx context.Context, sessionID, model string) error {
+	a.mu.Lock()
+	session, ok := a.sessions[sessionID]
+	a.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("session %s not resumed", sessionID)
+	}
+
+	res, err := session.RPC.Model.SwitchTo(ctx, &sdkrpc.SessionModelSwitchToParams{ModelID: model})
+	if err != nil {
+		return fmt.Errorf("switch %s to %s: %w", sessionID, model, err)
+	}
+	if res != nil && res.ModelID != nil {
+		model = *res.ModelID
+	}
+	a.setModel(sessionID, model)
+	return nil
+}
+
+// Model returns the model a session last reported, or "" if unknown.
+func (a *Adapter) Model(sessionID string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.models[sessionID]
+}
+
+func (a *Adapter) setModel(sessionID, model string) {
+	a.mu.Lock()
+	a.models[sessionID] = model
+	a.mu.Unlock()
+}
+
+// CreateSessionOptions configures a brand-new session.
+type CreateSessionOptions struct {
+	CWD          string // working directory; "~" is expanded
+	Model        string // optional; empty uses the CLI default
+	SystemPrompt string // optional; appended to the default system message
+}
+
+// CreateSession starts a new session and subscribes to its events, like
+// ResumeSession does for existing ones.
+func (a *Adapter) CreateSession(ctx context.Context, opts CreateSessionOptions) (domain.Session, error) {
+	cwd := config.ExpandHome(strings.TrimSpace(opts.CWD))
+	if cwd == "" {
+		return domain.Session{}, fmt.Errorf("working directory is required")
+	}
+	info, err := os.Stat(cwd)
+	if err != nil {
+		return domain.Session{}, fmt.Errorf("working directory: %w", err)
+	}
+	if !info.IsDir() {
+		return domain.Session{}, fmt.Errorf("working directory: %s is not a directory", cwd)
+	}
+
+	// The ID is chosen up front so the permission and input handlers can be
+	// bound to it before the CLI starts the session.
+	sessionID := newSessionID()
+	cfg := &sdk.SessionConfig{
+		SessionID:           sessionID,
+		Model:               strings.TrimSpace(opts.Model),
+		WorkingDirectory:    cwd,
+		Streaming:           true,
+		OnPermissionRequest: a.makePermissionHandler(sessionID),
+		OnUserInputRequest:  a.makeUserInputHandler(sessionID),
+	}
+	if prompt := strings.TrimSpace(opts.SystemPrompt); prompt != "" {
+		cfg.SystemMessage = &sdk.SystemMessageConfig{Mode: "append", Content: prompt}
+	}
+
+	a.mu.Lock()
+	a.cwds[sessionID] = cwd
+	a.mu.Unlock()
+
+	session, err := a.client.CreateSession(ctx, cfg)
+	if err != nil {
+		a.mu.Lock()
+		delete(a.cwds, sessionID)
+		a.mu.Unlock()
+		return domain.Session{}, fmt.Errorf("create session: %w", err)
+	}
+	if session.SessionID != "" {
+		sessionID = session.SessionID
+	}
+
+	a.subscribe(sessionID, session)
+
+	model := cfg.Model
+	if cur, err := session.RPC.Model.GetCurrent(ctx); err == nil && cur.ModelID != nil {
+		model = *cur.ModelID
+	}
+	if model != "" {
+		a.setModel(sessionID, model)
+	}
+
+	now := time.Now()
+	return domain.Session{ID: sessionID, CWD: cwd, Model: model, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+// subscribe stores an active session and forwards its events to the Events channel.
+func (a *Adapter) subscribe(sessionID string, session *sdk.Session) {
 	a.mu.Lock()
 	a.sessions[sessionID] = session
 	a.mu.Unlock()
 
-	// Subscribe to session events and forward to Events channel
 	session.On(func(event sdk.SessionEvent) {
-		a.Events <- Event{
+		a.trackToolCall(event)
+		if model := modelFromEvent(event); model != "" {
+			a.setModel(sessionID, model)
+		}
+		a.events <- Event{
 			Type:         EventSession,
 			SessionID:    sessionID,
 			SessionEvent: &event,
 		}
 	})
+}
+
+// newSessionID returns a random RFC 4122 version 4 UUID, the format the CLI uses.
+func newSessionID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// DeleteSession permanently deletes a session and its history.
+func (a *Adapter) DeleteSession(ctx context.Context, sessionID string) error {
+	a.mu.Lock()
+	session, ok := a.sessions[sessionID]
+	delete(a.sessions, sessionID)
+	delete(a.approved, sessionID)
+	delete(a.cwds, sessionID)
+	a.mu.Unlock()
 
+	if ok {
+		session.Destroy()
+	}
+	if err := a.client.DeleteSession(ctx, sessionID); err != nil {
+		return fmt.Errorf("delete session %s: %w", sessionID, err)
+	}
 	return nil
 }
 
@@ -136,6 +352,15 @@ func (a *Adapter) GetHistory(ctx context.Context, sessionID string) ([]domain.Me
 		return nil, fmt.Errorf("get messages for %s: %w", sessionID, err)
 	}
 
+	// Fall back to the history for sessions whose model is not known yet
+	if a.Model(sessionID) == "" {
+		for _, e := range events {
+			if model := modelFromEvent(e); model != "" {
+				a.setModel(sessionID, model)
+			}
+		}
+	}
+
 	return eventsToMessages(events), nil
 }
 
@@ -181,41 +406,201 @@ func (a *Adapter) IsResumed(sessionID string) bool {
 
 
 // makePermissionHandler creates a permission handler that routes requests through the Events channel.
+// The security policy is consulted first: denials are reported to the app without asking, and
+// allowed tools, like tools the user already approved for the session, run without asking.
 func (a *Adapter) makePermissionHandler(sessionID string) sdk.PermissionHandler {
 	return func(req sdk.PermissionRequest, inv sdk.PermissionInvocation) (sdk.PermissionRequestResult, error) {
-		respCh := make(chan PermissionResponse, 1)
+		toolName, args := a.describePermission(req)
+		key := permissionKey(toolName, req.Kind)
 
-		toolName := ""
-		if name, ok := req.Extra["toolName"]; ok {
-			toolName, _ = name.(string)
+		a.mu.Lock()
+		pol := a.policy
+		cwd := a.cwds[sessionID]
+		preapproved := a.approved[sessionID][key]
+		a.mu.Unlock()
+
+		if pol != nil {
+			switch res := pol.Evaluate(cwd, toolName, args); res.Decision {
+			case policy.Deny:
+				a.events <- Event{
+					Type:      EventPermission,
+					SessionID: sessionID,
+					Permission: &PermissionEvent{
+						ToolName:   toolName,
+						Kind:       req.Kind,
+						ToolCallID: req.ToolCallID,
+						Args:       args,
+						Denied:     true,
+						DenyReason: res.Reason,
+					},
+				}
+				return sdk.PermissionRequestResult{Kind: "denied-by-rules"}, nil
+			case policy.Allow:
+				return sdk.PermissionRequestResult{Kind: "approved"}, nil
+			}
+		}
+		if preapproved {
+			return sdk.PermissionRequestResult{Kind: "approved"}, nil
 		}
-		action := req.Kind
 
-		a.Events <- Event{
+		respCh := make(chan PermissionResponse, 1)
+		a.events <- Event{
 			Type:      EventPermission,
 			SessionID: sessionID,
 			Permission: &PermissionEvent{
-				ToolName: toolName,
-				Action:   action,
-				Response: respCh,
+				ToolName:   toolName,
+				Kind:       req.Kind,
+				ToolCallID: req.ToolCallID,
+				Args:       args,
+				Response:   respCh,
 			},
 		}
 
 		// Block until the TUI user responds
 		resp := <-respCh
-		if resp.Allow {
-			return sdk.PermissionRequestResult{Kind: "allow"}, nil
+		if !resp.Allow {
+			return sdk.PermissionRequestResult{Kind: "denied-interactively-by-user"}, nil
 		}
-		return sdk.PermissionRequestResult{Kind: "deny"}, nil
+		if resp.ForSession {
+			a.mu.Lock()
+			if a.approved[sessionID] == nil {
+				a.approved[sessionID] = make(map[string]bool)
+			}
+			a.approved[sessionID][key] = true
+			a.mu.Unlock()
+		}
+		return sdk.PermissionRequestResult{Kind: "approved"}, nil
 	}
 }
 
+// describePermission resolves the tool name and a readable argument string for a
+// permission request, falling back to the matching tool.execution_start event.
+func (a *Adapter) describePermission(req sdk.PermissionRequest) (string, string) {
+	toolName := ""
+	if name, ok := req.Extra["toolName"]; ok {
+		toolName, _ = name.(string)
+	}
+	args := formatArgs(req.Extra, "toolName")
+
+	if req.ToolCallID != "" && (toolName == "" || args == "") {
+		a.mu.Lock()
+		info, ok := a.toolCalls[req.ToolCallID]
+		a.mu.Unlock()
+		if ok {
+			if toolName == "" {
+				toolName = info.Name
+			}
+			if args == "" {
+				args = info.Args
+			}
+		}
+	}
+	if toolName == "" {
+		toolName = req.Kind
+	}
+	return toolName, args
+}
+
+// trackToolCall records started tool calls for later permission lookups and
+// forgets them once they complete.
+func (a *Adapter) trackToolCall(event sdk.SessionEvent) {
+	if event.Data.ToolCallID == nil {
+		return
+	}
+	id := *event.Data.ToolCallID
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch event.Type {
+	case sdk.ToolExecutionStart:
+		if event.Data.ToolName != nil {
+			a.toolCalls[id] = toolCallInfo{
+				Name: *event.Data.ToolName,
+				Args: formatArgs(event.Data.Arguments),
+			}
+		}
+	case sdk.ToolExecutionComplete:
+		delete(a.toolCalls, id)
+	}
+}
+
+// modelFromEvent returns the model a session event reports, if any.
+func modelFromEvent(event sdk.SessionEvent) string {
+	var model *string
+	switch event.Type {
+	case sdk.SessionStart:
+		model = event.Data.SelectedModel
+	case sdk.SessionModelChange:
+		model = event.Data.NewModel
+	}
+	if model == nil {
+		return ""
+	}
+	return *model
+}
+
+// permissionKey identifies a tool for session-wide approvals.
+func permissionKey(toolName, kind string) string {
+	if toolName != "" {
+		return toolName
+	}
+	return kind
+}
+
+// formatArgs renders tool arguments for display. Well-known fields such as a
+// shell command or file path are shown verbatim; anything else becomes JSON.
+func formatArgs(v interface{}, skip ...string) string {
+	switch args := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return args
+	case map[string]any:
+		for _, k := range []string{"fullCommandText", "command", "path", "url"} {
+			if s, ok := args[k].(string); ok && s != "" {
+				return s
+			}
+		}
+		keys := make([]string, 0, len(args))
+		for k := range args {
+			if !contains(skip, k) {
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) == 0 {
+			return ""
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			b, _ := json.Marshal(args[k])
+			parts = append(parts, k+"="+string(b))
+		}
+		return strings.Join(parts, " ")
+	default:
+		b, err := json.Marshal(args)
+		if err != nil {
+			return fmt.Sprint(args)
+		}
+		return string(b)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // makeUserInputHandler creates a user input handler that routes requests through the Events channel.
 func (a *Adapter) makeUserInputHandler(sessionID string) sdk.UserInputHandler {
 	return func(req sdk.UserInputRequest, inv sdk.UserInputInvocation) (sdk.UserInputResponse, error) {
 		respCh := make(chan UserInputResponse, 1)
 
-		a.Events <- Event{
+		a.events <- Event{
 			Type:      EventUserInput,
 			SessionID: sessionID,
 			UserInput: &UserInputEvent{
@@ -260,17 +645,96 @@ func metadataToSession(m sdk.SessionMetadata) domain.Session {
 }
 
 // eventsToMessages converts SDK SessionEvents to domain.Messages.
+// Tool call arguments only appear on tool.execution_start, so they are
+// matched to the completion event by tool call ID.
 func eventsToMessages(events []sdk.SessionEvent) []domain.Message {
 	var messages []domain.Message
+	starts := make(map[string]sdk.SessionEvent)
 	for _, e := range events {
+		if e.Type == sdk.ToolExecutionStart && e.Data.ToolCallID != nil {
+			starts[*e.Data.ToolCallID] = e
+		}
 		msg, ok := sessionEventToMessage(e)
-		if ok {
-			messages = append(messages, msg)
+		if !ok {
+			continue
+		}
+		if e.Type == sdk.ToolExecutionComplete && e.Data.ToolCallID != nil {
+			if start, ok := starts[*e.Data.ToolCallID]; ok {
+				for i := range msg.ToolCalls {
+					applyToolArgs(&msg.ToolCalls[i], start.Data.Arguments)
+					msg.ToolCalls[i].StartedAt = localTime(start.Timestamp)
+				}
+			}
 		}
+		messages = append(messages, msg)
 	}
 	return messages
 }
 
+// EventMessage converts a live session event to the message it adds to the
+// history, if any. Every such event adds exactly one message, so counting
+// them gives a message's position in what GetHistory returns.
+func EventMessage(e sdk.SessionEvent) (domain.Message, bool) {
+	return sessionEventToMessage(e)
+}
+
+// applyToolArgs fills the tool-specific ToolCall fields from its arguments.
+func applyToolArgs(tc *domain.ToolCall, args interface{}) {
+	if args != nil {
+		if raw, err := json.Marshal(args); err == nil {
+			tc.Args = string(raw)
+		}
+	}
+	if patch, ok := args.(string); ok && tc.Name == "apply_patch" {
+		setPatch(tc, patch)
+		return
+	}
+	m, ok := args.(map[string]any)
+	if !ok {
+		return
+	}
+	switch tc.Name {
+	case "edit":
+		path, _ := m["path"].(string)
+		oldStr, _ := m["old_str"].(string)
+		newStr, _ := m["new_str"].(string)
+		tc.FilePath = path
+		if oldStr != "" || newStr != "" {
+			tc.Patch = diff.Edit(path, oldStr, newStr)
+		}
+	case "create":
+		path, _ := m["path"].(string)
+		text, _ := m["file_text"].(string)
+		tc.FilePath, tc.Patch = path, diff.Create(path, text)
+	case "apply_patch":
+		for _, k := range []string{"input", "patch"} {
+			if patch, ok := m[k].(string); ok && patch != "" {
+				setPatch(tc, patch)
+				break
+			}
+		}
+	case "ask_user":
+		tc.Question, _ = m["question"].(string)
+		if choices, ok := m["choices"].([]any); ok {
+			for _, c := range choices {
+				if s, ok := c.(string); ok {
+					tc.Choices = append(tc.Choices, s)
+				}
+			}
+		}
+	}
+}
+
+// setPatch records an apply_patch patch and the files it touches.
+func setPatch(tc *domain.ToolCall, patch string) {
+	tc.Patch = patch
+	var paths []string
+	for _, f := range diff.Parse(patch) {
+		paths = append(paths, f.Path)
+	}
+	tc.FilePath = strings.Join(paths, ", ")
+}
+
 // sessionEventToMessage converts a single SDK SessionEvent to a domain.Message.
 // Returns false if the event type doesn't map to a displayable message.
 func sessionEventToMessage(e sdk.SessionEvent) (domain.Message, bool) {
@@ -281,8 +745,9 @@ func sessionEventToMessage(e sdk.SessionEvent) (domain.Message, bool) {
 			content = *e.Data.Content
 		}
 		return domain.Message{
-			Role:    domain.RoleUser,
-			Content: content,
+			Role:      domain.RoleUser,
+			Content:   content,
+			Timestamp: localTime(e.Timestamp),
 		}, true
 
 	case sdk.AssistantMessage:
@@ -291,8 +756,9 @@ func sessionEventToMessage(e sdk.SessionEvent) (domain.Message, bool) {
 			content = *e.Data.Content
 		}
 		return domain.Message{
-			Role:    domain.RoleAssistant,
-			Content: content,
+			Role:      domain.RoleAssistant,
+			Content:   content,
+			Timestamp: localTime(e.Timestamp),
 		}, true
 
 	case sdk.ToolExecutionComplete:
@@ -309,8 +775,15 @@ func sessionEventToMessage(e sdk.SessionEvent) (domain.Message, bool) {
 			Summary: resultContent,
 			Status:  domain.ToolCallComplete,
 		}
+		if e.Data.ToolCallID != nil {
+			tc.ID = *e.Data.ToolCallID
+		}
+		if e.Data.Success != nil && !*e.Data.Success {
+			tc.Status = domain.ToolCallFailed
+		}
 		return domain.Message{
 			Role:      domain.RoleAssistant,
+			Timestamp: localTime(e.Timestamp),
 			ToolCalls: []domain.ToolCall{tc},
 		}, true
 
@@ -319,6 +792,14 @@ func sessionEventToMessage(e sdk.SessionEvent) (domain.Message, bool) {
 	}
 }
 
+// localTime converts an event time for display, keeping zero times zero.
+func localTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.Local()
+}
+
 func parseTime(s string) time.Time {
 	t, err := time.Parse(time.RFC3339, s)
 	if err != nil {
@@ -1,6 +1,7 @@
 package copilot
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -155,3 +156,107 @@ func TestParseTime(t *testing.T) {
 		t.Error("invalid time should return zero")
 	}
 }
+
+func TestEventsToMessagesAskUser(t *testing.T) {
+	callID := "call-1"
+	toolName := "ask_user"
+
+	events := []sdk.SessionEvent{
+		{Type: sdk.ToolExecutionStart, Data: sdk.Data{
+			ToolCallID: &callID,
+			ToolName:   &toolName,
+			Arguments:  map[string]any{"question": "Which framework?", "choices": []any{"Jest", "Vitest"}},
+		}},
+		{Type: sdk.ToolExecutionComplete, Data: sdk.Data{
+			ToolCallID: &callID,
+			ToolName:   &toolName,
+			Result:     &sdk.Result{Content: "Jest"},
+		}},
+	}
+
+	msgs := eventsToMessages(events)
+	if len(msgs) != 1 || len(msgs[0].ToolCalls) != 1 {
+		t.Fatalf("got %+v, want one ask_user tool call", msgs)
+	}
+	tc := msgs[0].ToolCalls[0]
+	if tc.Question != "Which framework?" {
+		t.Errorf("Question = %q, want %q", tc.Question, "Which framework?")
+	}
+	if len(tc.Choices) != 2 || tc.Choices[1] != "Vitest" {
+		t.Errorf("Choices = %v, want [Jest Vitest]", tc.Choices)
+	}
+	if tc.Summary != "Jest" {
+		t.Errorf("Summary = %q, want %q", tc.Summary, "Jest")
+	}
+}
+
+func TestApplyToolArgsPatches(t *testing.T) {
+	edit := domain.ToolCall{Name: "edit"}
+	applyToolArgs(&edit, map[string]any{"path": "main.go", "old_str": "a\nb", "new_str": "a\nc"})
+	if edit.FilePath != "main.go" || !strings.Contains(edit.Patch, "-b\n+c") {
+		t.Errorf("edit = %q, %q", edit.FilePath, edit.Patch)
+	}
+
+	patch := "*** Begin Patch\n*** Update File: a.go\n@@\n-x\n+y\n*** Add File: b.go\n+z\n*** End Patch"
+	raw := domain.ToolCall{Name: "apply_patch"}
+	applyToolArgs(&raw, patch)
+	if raw.FilePath != "a.go, b.go" || raw.Patch != patch {
+		t.Errorf("apply_patch = %q, %q", raw.FilePath, raw.Patch)
+	}
+	wrapped := domain.ToolCall{Name: "apply_patch"}
+	applyToolArgs(&wrapped, map[string]any{"input": patch})
+	if wrapped.FilePath != raw.FilePath || wrapped.Patch != patch {
+		t.Errorf("apply_patch with input = %q, %q", wrapped.FilePath, wrapped.Patch)
+	}
+}
+
+func TestEventsToMessagesToolDetails(t *testing.T) {
+	callID := "call-2"
+	toolName := "bash"
+	failed := false
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	msgs := eventsToMessages([]sdk.SessionEvent{
+		{Type: sdk.ToolExecutionStart, Timestamp: start, Data: sdk.Data{
+			ToolCallID: &callID,
+			ToolName:   &toolName,
+			Arguments:  map[string]any{"command": "make"},
+		}},
+		{Type: sdk.ToolExecutionComplete, Timestamp: start.Add(time.Second), Data: sdk.Data{
+			ToolCallID: &callID,
+			ToolName:   &toolName,
+			Success:    &failed,
+			Result:     &sdk.Result{Content: "make: *** no rule"},
+		}},
+	})
+	if len(msgs) != 1 || len(msgs[0].ToolCalls) != 1 {
+		t.Fatalf("got %+v", msgs)
+	}
+	tc := msgs[0].ToolCalls[0]
+	if tc.ID != callID || tc.Args != `{"command":"make"}` || tc.Status != domain.ToolCallFailed {
+		t.Errorf("tool call = %+v", tc)
+	}
+	if !tc.StartedAt.Equal(start) || !msgs[0].Timestamp.Equal(start.Add(time.Second)) {
+		t.Errorf("times = %v, %v", tc.StartedAt, msgs[0].Timestamp)
+	}
+}
+
+func TestModelFromEvent(t *testing.T) {
+	selected := "gpt-5"
+	switched := "claude-sonnet-4.5"
+
+	tests := []struct {
+		event sdk.SessionEvent
+		want  string
+	}{
+		{sdk.SessionEvent{Type: sdk.SessionStart, Data: sdk.Data{SelectedModel: &selected}}, "gpt-5"},
+		{sdk.SessionEvent{Type: sdk.SessionModelChange, Data: sdk.Data{NewModel: &switched}}, "claude-sonnet-4.5"},
+		{sdk.SessionEvent{Type: sdk.SessionModelChange}, ""},
+		{sdk.SessionEvent{Type: sdk.SessionIdle}, ""},
+	}
+	for _, tt := range tests {
+		if got := modelFromEvent(tt.event); got != tt.want {
+			t.Errorf("modelFromEvent(%s) = %q, want %q", tt.event.Type, got, tt.want)
+		}
+	}
+}
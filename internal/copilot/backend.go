@@ -0,0 +1,38 @@
+package copilot
+
+import (
+	"context"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/infra/hookserver"
+	"github.com/e-9/copilot-icq/internal/policy"
+)
+
+// Backend is where the app gets its sessions from: the SDK Adapter, which
+// drives Copilot CLI, or the read-only FileBackend, which reads the files
+// Copilot CLI leaves in its session-state directory.
+type Backend interface {
+	Start(ctx context.Context) error
+	Close() error
+	ListSessions(ctx context.Context) ([]domain.Session, error)
+	ResumeSession(ctx context.Context, sessionID, model string) (string, error)
+	GetHistory(ctx context.Context, sessionID string) ([]domain.Message, error)
+	Send(ctx context.Context, sessionID, text string) (string, error)
+	Abort(ctx context.Context, sessionID string) error
+	CreateSession(ctx context.Context, opts CreateSessionOptions) (domain.Session, error)
+	DeleteSession(ctx context.Context, sessionID string) error
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+	SwitchModel(ctx context.Context, sessionID, model string) error
+	SetPolicy(p *policy.Policy)
+	HandleHook(ev hookserver.Event) hookserver.Decision
+
+	// Events returns the channel session events are delivered on.
+	Events() <-chan Event
+	// ReadOnly reports whether sending and managing sessions is unsupported.
+	ReadOnly() bool
+}
+
+var (
+	_ Backend = (*Adapter)(nil)
+	_ Backend = (*FileBackend)(nil)
+)
@@ -0,0 +1,222 @@
+// Package copilottest provides an in-memory copilot.Backend for tests. The
+// test scripts sessions, history and events; the fake records what the app
+// asked it to do.
+package copilottest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/infra/hookserver"
+	"github.com/e-9/copilot-icq/internal/policy"
+)
+
+// Sent is a prompt the app sent.
+type Sent struct {
+	SessionID string
+	Text      string
+}
+
+// Backend is a scriptable fake of copilot.Backend. Set its fields before
+// handing it to the app; read the recorded calls through the methods, which
+// are safe while commands run.
+type Backend struct {
+	// Sessions grows as CreateSession is called; once the app runs, read it
+	// through ListSessions.
+	Sessions []domain.Session
+	History  map[string][]domain.Message // sessionID → messages GetHistory returns
+	Models   []copilot.ModelInfo
+	// Model is what ResumeSession reports when no model is requested.
+	Model string
+	// Err, when set, is returned by every call that can fail.
+	Err error
+	// ReadOnlyMode makes ReadOnly report true.
+	ReadOnlyMode bool
+
+	mu      sync.Mutex
+	sent    []Sent
+	aborted []string
+	resumed []string
+	deleted []string
+	closed  bool
+	policy  *policy.Policy
+	events  chan copilot.Event
+}
+
+var _ copilot.Backend = (*Backend)(nil)
+
+// New creates a fake backend with the given sessions.
+func New(sessions ...domain.Session) *Backend {
+	return &Backend{
+		Sessions: sessions,
+		History:  make(map[string][]domain.Message),
+		events:   make(chan copilot.Event, 64),
+	}
+}
+
+// Emit queues an event for the app's listener.
+func (b *Backend) Emit(ev copilot.Event) {
+	b.events <- ev
+}
+
+// EmitSession queues a session event.
+func (b *Backend) EmitSession(sessionID string, ev sdk.SessionEvent) {
+	b.Emit(copilot.Event{Type: copilot.EventSession, SessionID: sessionID, SessionEvent: &ev})
+}
+
+// Sent returns the prompts sent so far.
+func (b *Backend) Sent() []Sent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Sent(nil), b.sent...)
+}
+
+// Aborted returns the sessions aborted so far.
+func (b *Backend) Aborted() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.aborted...)
+}
+
+// Resumed returns the sessions resumed so far.
+func (b *Backend) Resumed() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.resumed...)
+}
+
+// Deleted returns the sessions deleted so far.
+func (b *Backend) Deleted() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.deleted...)
+}
+
+// Policy returns the policy the app set.
+func (b *Backend) Policy() *policy.Policy {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.policy
+}
+
+func (b *Backend) Start(ctx context.Context) error { return b.Err }
+
+func (b *Backend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+// Closed reports whether Close was called.
+func (b *Backend) Closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+func (b *Backend) Events() <-chan copilot.Event { return b.events }
+
+func (b *Backend) ReadOnly() bool { return b.ReadOnlyMode }
+
+func (b *Backend) SetPolicy(p *policy.Policy) {
+	b.mu.Lock()
+	b.policy = p
+	b.mu.Unlock()
+}
+
+// HandleHook passes the hook on as an event and allows it.
+func (b *Backend) HandleHook(ev hookserver.Event) hookserver.Decision {
+	b.Emit(copilot.Event{
+		Type:      copilot.EventHook,
+		SessionID: ev.SessionID,
+		Hook:      &copilot.HookEvent{Name: ev.Name, CWD: ev.CWD, ToolName: ev.ToolName},
+	})
+	return hookserver.Decision{}
+}
+
+func (b *Backend) ListSessions(ctx context.Context) ([]domain.Session, error) {
+	if b.Err != nil {
+		return nil, b.Err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]domain.Session(nil), b.Sessions...), nil
+}
+
+func (b *Backend) ResumeSession(ctx context.Context, sessionID, model string) (string, error) {
+	if b.Err != nil {
+		return "", b.Err
+	}
+	b.mu.Lock()
+	b.resumed = append(b.resumed, sessionID)
+	b.mu.Unlock()
+	if model == "" {
+		model = b.Model
+	}
+	return model, nil
+}
+
+func (b *Backend) GetHistory(ctx context.Context, sessionID string) ([]domain.Message, error) {
+	if b.Err != nil {
+		return nil, b.Err
+	}
+	return append([]domain.Message(nil), b.History[sessionID]...), nil
+}
+
+func (b *Backend) Send(ctx context.Context, sessionID, text string) (string, error) {
+	if b.Err != nil {
+		return "", b.Err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sent = append(b.sent, Sent{SessionID: sessionID, Text: text})
+	return fmt.Sprintf("msg-%d", len(b.sent)), nil
+}
+
+func (b *Backend) Abort(ctx context.Context, sessionID string) error {
+	if b.Err != nil {
+		return b.Err
+	}
+	b.mu.Lock()
+	b.aborted = append(b.aborted, sessionID)
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *Backend) CreateSession(ctx context.Context, opts copilot.CreateSessionOptions) (domain.Session, error) {
+	if b.Err != nil {
+		return domain.Session{}, b.Err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := domain.Session{ID: fmt.Sprintf("new-%d", len(b.Sessions)+1), CWD: opts.CWD, Model: opts.Model}
+	b.Sessions = append(b.Sessions, s)
+	return s, nil
+}
+
+func (b *Backend) DeleteSession(ctx context.Context, sessionID string) error {
+	if b.Err != nil {
+		return b.Err
+	}
+	b.mu.Lock()
+	b.deleted = append(b.deleted, sessionID)
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *Backend) ListModels(ctx context.Context) ([]copilot.ModelInfo, error) {
+	if b.Err != nil {
+		return nil, b.Err
+	}
+	return b.Models, nil
+}
+
+func (b *Backend) SwitchModel(ctx context.Context, sessionID, model string) error {
+	return b.Err
+}
@@ -14,6 +14,12 @@ const (
 	EventPermission
 	// EventUserInput is a user input request from the agent (ask_user)
 	EventUserInput
+	// EventHook is a Copilot CLI hook forwarded by copilot-icq-hook. Hooks
+	// fire in every session, including ones running in other terminals.
+	EventHook
+	// EventResolved reports that a permission request or question was
+	// answered elsewhere, e.g. in another TUI attached to the same daemon
+	EventResolved
 )
 
 // Event is emitted by the Adapter to the app layer via the Events channel.
@@ -25,18 +31,32 @@ type Event struct {
 	Lifecycle    *sdk.SessionLifecycleEvent
 	Permission   *PermissionEvent
 	UserInput    *UserInputEvent
+	Hook         *HookEvent
+	Resolved     *ResolvedEvent
 }
 
 // PermissionEvent wraps a tool permission request with a response channel.
 type PermissionEvent struct {
-	ToolName string
-	Action   string
-	Response chan<- PermissionResponse
+	ToolName   string
+	Kind       string // permission kind: shell, write, read, url, mcp
+	ToolCallID string
+	Args       string // human-readable tool arguments
+	// Denied is set when the security policy rejected the request on its own;
+	// Response is nil and DenyReason explains which rule matched.
+	Denied     bool
+	DenyReason string
+	Response   chan<- PermissionResponse
+	// RequestID identifies the request to a later EventResolved. Only
+	// requests that can be answered elsewhere have one.
+	RequestID string
 }
 
-// PermissionResponse is the user's decision on a permission request.
+// PermissionResponse is the user's decision on a permission request. The
+// agent is only told whether the tool was denied, never why.
 type PermissionResponse struct {
 	Allow bool
+	// ForSession approves every later request for the same tool in this session.
+	ForSession bool
 }
 
 // UserInputEvent wraps an ask_user request with a response channel.
@@ -45,6 +65,15 @@ type UserInputEvent struct {
 	Choices       []string
 	AllowFreeform bool
 	Response      chan<- UserInputResponse
+	RequestID     string // see PermissionEvent.RequestID
+}
+
+// ResolvedEvent is the answer given elsewhere to the permission request or
+// question with RequestID.
+type ResolvedEvent struct {
+	RequestID string
+	Allow     bool   // permission requests
+	Answer    string // questions
 }
 
 // UserInputResponse is the user's answer to an ask_user question.
@@ -52,3 +81,18 @@ type UserInputResponse struct {
 	Answer      string
 	WasFreeform bool
 }
+
+// HookEvent is a Copilot CLI hook. SessionID on the Event is empty when the
+// CLI did not report one; CWD then tells the sessions apart.
+type HookEvent struct {
+	Name     string // hookserver event name, e.g. preToolUse
+	CWD      string
+	ToolName string
+	Args     string // human-readable tool arguments
+	Result   string // postToolUse result type, e.g. success or failure
+	Prompt   string
+	Error    string
+	// Denied is set when the security policy rejected a preToolUse.
+	Denied     bool
+	DenyReason string
+}
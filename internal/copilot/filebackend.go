@@ -0,0 +1,265 @@
+package copilot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/infra/eventparser"
+	"github.com/e-9/copilot-icq/internal/infra/hookserver"
+	"github.com/e-9/copilot-icq/internal/infra/sessionrepo"
+	"github.com/e-9/copilot-icq/internal/infra/watcher"
+	"github.com/e-9/copilot-icq/internal/policy"
+)
+
+// pollInterval is how often the FileBackend checks the session files.
+const pollInterval = 500 * time.Millisecond
+
+// ErrReadOnly is returned by the FileBackend for anything that needs Copilot CLI.
+var ErrReadOnly = errors.New("read-only mode: set use_sdk: true to send messages and manage sessions")
+
+// FileBackend reads sessions straight from Copilot CLI's session-state
+// directory, for when the copilot binary is not available. It discovers
+// sessions from workspace.yaml, reads history from events.jsonl and follows
+// both for live updates. It cannot send messages or manage sessions.
+type FileBackend struct {
+	base    string
+	watcher *watcher.Watcher
+	parsers map[string]*eventparser.Parser // sessionID → reader following its events.jsonl
+	policy  *policy.Policy
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+
+	models   map[string]*sessionModel // sessionID → model read so far
+	modelsMu sync.Mutex
+
+	events chan Event
+}
+
+// sessionModel follows the model events in a session's events.jsonl.
+type sessionModel struct {
+	events *eventparser.Parser
+	model  string
+}
+
+// NewFileBackend creates a backend for the sessions under base, usually
+// ~/.copilot/session-state. Call Start to begin following them.
+func NewFileBackend(base string) *FileBackend {
+	return &FileBackend{
+		base:    base,
+		watcher: watcher.New(base, pollInterval),
+		parsers: make(map[string]*eventparser.Parser),
+		models:  make(map[string]*sessionModel),
+		events:  make(chan Event, 64),
+	}
+}
+
+// Events returns the channel session events are delivered on.
+func (b *FileBackend) Events() <-chan Event {
+	return b.events
+}
+
+// ReadOnly reports true: the session files can only be read.
+func (b *FileBackend) ReadOnly() bool {
+	return true
+}
+
+// SetPolicy sets the security policy applied to forwarded hooks.
+func (b *FileBackend) SetPolicy(p *policy.Policy) {
+	b.mu.Lock()
+	b.policy = p
+	b.mu.Unlock()
+}
+
+// HandleHook applies the security policy to a hook forwarded by
+// copilot-icq-hook and passes the hook on to the app.
+func (b *FileBackend) HandleHook(ev hookserver.Event) hookserver.Decision {
+	b.mu.Lock()
+	pol := b.policy
+	b.mu.Unlock()
+	return relayHook(pol, b.events, ev)
+}
+
+// Start begins following the session files. Events already on disk are not
+// replayed; GetHistory reads them.
+func (b *FileBackend) Start(ctx context.Context) error {
+	sessions, err := b.list()
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	for _, s := range sessions {
+		p := eventparser.New(sessionrepo.EventsPath(b.base, s.ID))
+		if err := p.SkipToEnd(); err != nil {
+			b.mu.Unlock()
+			return fmt.Errorf("read session %s: %w", s.ID, err)
+		}
+		b.parsers[s.ID] = p
+	}
+	b.mu.Unlock()
+	b.watcher.Scan()
+
+	// ctx only bounds startup; following lasts until Close
+	run, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	go b.watcher.Run(run, b.apply)
+	return nil
+}
+
+// Close stops following the session files.
+func (b *FileBackend) Close() error {
+	if b.cancel != nil {
+		b.cancel()
+	}
+	return nil
+}
+
+// apply turns file changes into lifecycle and session events.
+func (b *FileBackend) apply(changes []watcher.Change) {
+	for _, c := range changes {
+		switch c.File {
+		case sessionrepo.WorkspaceFile:
+			typ := sdk.SessionLifecycleUpdated
+			switch c.Op {
+			case watcher.Created:
+				typ = sdk.SessionLifecycleCreated
+			case watcher.Removed:
+				typ = sdk.SessionLifecycleDeleted
+				b.mu.Lock()
+				delete(b.parsers, c.SessionID)
+				b.mu.Unlock()
+				b.modelsMu.Lock()
+				delete(b.models, c.SessionID)
+				b.modelsMu.Unlock()
+			}
+			b.events <- Event{
+				Type:      EventLifecycle,
+				SessionID: c.SessionID,
+				Lifecycle: &sdk.SessionLifecycleEvent{Type: typ, SessionID: c.SessionID},
+			}
+
+		case sessionrepo.EventsFile:
+			if c.Op == watcher.Removed {
+				continue
+			}
+			b.mu.Lock()
+			p, ok := b.parsers[c.SessionID]
+			if !ok {
+				// A new session: everything in it is new
+				p = eventparser.New(sessionrepo.EventsPath(b.base, c.SessionID))
+				b.parsers[c.SessionID] = p
+			}
+			b.mu.Unlock()
+
+			events, _ := p.Next()
+			for i := range events {
+				b.events <- Event{
+					Type:         EventSession,
+					SessionID:    c.SessionID,
+					SessionEvent: &events[i],
+				}
+			}
+		}
+	}
+}
+
+// ListSessions returns the sessions found on disk, with the model each one
+// last reported.
+func (b *FileBackend) ListSessions(ctx context.Context) ([]domain.Session, error) {
+	sessions, err := b.list()
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		if sessions[i].Model == "" {
+			sessions[i].Model, _ = b.model(sessions[i].ID)
+		}
+	}
+	return sessions, nil
+}
+
+// list reads the sessions on disk. Copilot CLI creates the session-state
+// directory on first use, so until then there are simply no sessions; the
+// watcher picks them up once it appears.
+func (b *FileBackend) list() ([]domain.Session, error) {
+	sessions, err := sessionrepo.List(b.base)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return sessions, err
+}
+
+// ResumeSession only reports the session's model: there is no process to
+// attach to, and the session is followed from Start anyway. A model other
+// than the current one cannot be applied.
+func (b *FileBackend) ResumeSession(ctx context.Context, sessionID, model string) (string, error) {
+	if _, err := sessionrepo.Load(b.base, sessionID); err != nil {
+		return "", err
+	}
+	return b.model(sessionID)
+}
+
+// model returns the model a session last reported in its events. Only the
+// events that report a model are decoded, and only those appended since the
+// last call, so listing sessions on every refresh stays cheap.
+func (b *FileBackend) model(sessionID string) (string, error) {
+	b.modelsMu.Lock()
+	defer b.modelsMu.Unlock()
+	sm, ok := b.models[sessionID]
+	if !ok {
+		sm = &sessionModel{events: eventparser.NewFiltered(sessionrepo.EventsPath(b.base, sessionID), sdk.SessionStart, sdk.SessionModelChange)}
+		b.models[sessionID] = sm
+	}
+	events, err := sm.events.Next()
+	for _, e := range events {
+		if m := modelFromEvent(e); m != "" {
+			sm.model = m
+		}
+	}
+	return sm.model, err
+}
+
+// GetHistory parses a session's events.jsonl into messages.
+func (b *FileBackend) GetHistory(ctx context.Context, sessionID string) ([]domain.Message, error) {
+	events, err := eventparser.ParseFile(sessionrepo.EventsPath(b.base, sessionID))
+	if err != nil {
+		return nil, fmt.Errorf("get messages for %s: %w", sessionID, err)
+	}
+	return eventsToMessages(events), nil
+}
+
+// Send is not supported in read-only mode.
+func (b *FileBackend) Send(ctx context.Context, sessionID, text string) (string, error) {
+	return "", ErrReadOnly
+}
+
+// Abort is not supported in read-only mode.
+func (b *FileBackend) Abort(ctx context.Context, sessionID string) error {
+	return ErrReadOnly
+}
+
+// CreateSession is not supported in read-only mode.
+func (b *FileBackend) CreateSession(ctx context.Context, opts CreateSessionOptions) (domain.Session, error) {
+	return domain.Session{}, ErrReadOnly
+}
+
+// DeleteSession is not supported in read-only mode.
+func (b *FileBackend) DeleteSession(ctx context.Context, sessionID string) error {
+	return ErrReadOnly
+}
+
+// ListModels is not supported in read-only mode.
+func (b *FileBackend) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	return nil, ErrReadOnly
+}
+
+// SwitchModel is not supported in read-only mode.
+func (b *FileBackend) SwitchModel(ctx context.Context, sessionID, model string) error {
+	return ErrReadOnly
+}
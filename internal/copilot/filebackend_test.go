@@ -0,0 +1,114 @@
+package copilot
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sdk "github.com/github/copilot-sdk/go"
+)
+
+func TestFileBackend(t *testing.T) {
+	base := t.TempDir()
+	dir := filepath.Join(base, "s1")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "workspace.yaml"), []byte("id: s1\ncwd: /repo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	events := filepath.Join(dir, "events.jsonl")
+	history := `{"type":"session.model_change","id":"1","timestamp":"2025-01-01T00:00:00Z","data":{"newModel":"gpt-5"}}
+{"type":"user.message","id":"2","timestamp":"2025-01-01T00:00:01Z","data":{"content":"hi"}}
+{"type":"assistant.message","id":"3","timestamp":"2025-01-01T00:00:02Z","data":{"content":"hello"}}
+`
+	if err := os.WriteFile(events, []byte(history), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	b := NewFileBackend(base)
+	if err := b.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	sessions, err := b.ListSessions(ctx)
+	if err != nil || len(sessions) != 1 || sessions[0].CWD != "/repo" {
+		t.Fatalf("sessions = %+v, %v", sessions, err)
+	}
+	if sessions[0].Model != "gpt-5" {
+		t.Errorf("model = %q, want the one from the events", sessions[0].Model)
+	}
+	if model, err := b.ResumeSession(ctx, "s1", ""); err != nil || model != "gpt-5" {
+		t.Errorf("resume = %q, %v", model, err)
+	}
+
+	msgs, err := b.GetHistory(ctx, "s1")
+	if err != nil || len(msgs) != 2 || msgs[1].Content != "hello" {
+		t.Fatalf("history = %+v, %v", msgs, err)
+	}
+
+	if _, err := b.Send(ctx, "s1", "more"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Send = %v, want ErrReadOnly", err)
+	}
+
+	f, err := os.OpenFile(events, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"type":"session.idle","id":"4","timestamp":"2025-01-01T00:00:03Z","data":{}}` + "\n")
+	f.Close()
+
+	select {
+	case ev := <-b.Events():
+		if ev.Type != EventSession || ev.SessionID != "s1" || ev.SessionEvent.Type != sdk.SessionIdle {
+			t.Errorf("event = %+v, want only the appended session.idle", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event for the appended line")
+	}
+
+	// Later listings pick up model changes appended since the last one
+	f, err = os.OpenFile(events, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"type":"session.model_change","id":"5","timestamp":"2025-01-01T00:00:04Z","data":{"newModel":"claude-sonnet-4.5"}}` + "\n")
+	f.Close()
+	if sessions, err := b.ListSessions(ctx); err != nil || sessions[0].Model != "claude-sonnet-4.5" {
+		t.Errorf("sessions = %+v, %v; want the new model", sessions, err)
+	}
+}
+
+func TestFileBackendWithoutSessionState(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "session-state")
+	ctx := context.Background()
+	b := NewFileBackend(base)
+	if err := b.Start(ctx); err != nil {
+		t.Fatalf("Start = %v, want a missing directory to mean no sessions yet", err)
+	}
+	defer b.Close()
+	if sessions, err := b.ListSessions(ctx); err != nil || len(sessions) != 0 {
+		t.Errorf("sessions = %+v, %v", sessions, err)
+	}
+
+	// Sessions show up once Copilot CLI creates the directory
+	if err := os.MkdirAll(filepath.Join(base, "s1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "s1", "workspace.yaml"), []byte("id: s1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-b.Events():
+		if ev.Type != EventLifecycle || ev.SessionID != "s1" || ev.Lifecycle.Type != sdk.SessionLifecycleCreated {
+			t.Errorf("event = %+v, want s1 created", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event for the new session")
+	}
+}
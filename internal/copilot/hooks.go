@@ -0,0 +1,69 @@
+package copilot
+
+import (
+	"encoding/json"
+
+	"github.com/e-9/copilot-icq/internal/infra/hookserver"
+	"github.com/e-9/copilot-icq/internal/policy"
+)
+
+// HandleHook applies the security policy to a hook forwarded by
+// copilot-icq-hook and passes the hook on to the app. It does not wait for
+// the app: Copilot CLI is blocked until it returns.
+func (a *Adapter) HandleHook(ev hookserver.Event) hookserver.Decision {
+	a.mu.Lock()
+	pol := a.policy
+	a.mu.Unlock()
+	return relayHook(pol, a.events, ev)
+}
+
+// relayHook applies pol to a hook and passes it on to events, dropping it
+// when the app is not keeping up.
+func relayHook(pol *policy.Policy, events chan<- Event, ev hookserver.Event) hookserver.Decision {
+	h := hookEvent(ev)
+	dec := HookDecision(pol, ev)
+	h.Denied, h.DenyReason = dec.Deny, dec.Reason
+
+	select {
+	case events <- Event{Type: EventHook, SessionID: ev.SessionID, Hook: &h}:
+	default:
+		// Activity from other terminals is best effort; never stall the CLI
+	}
+	return dec
+}
+
+// HookDecision evaluates a preToolUse hook against the security policy.
+// Other hooks, and tools the policy does not deny, are left to Copilot CLI.
+func HookDecision(pol *policy.Policy, ev hookserver.Event) hookserver.Decision {
+	if pol == nil || ev.Name != hookserver.PreToolUse {
+		return hookserver.Decision{}
+	}
+	h := hookEvent(ev)
+	res := pol.Evaluate(h.CWD, h.ToolName, h.Args)
+	if res.Decision != policy.Deny {
+		return hookserver.Decision{}
+	}
+	return hookserver.Decision{Deny: true, Reason: res.Reason}
+}
+
+// hookEvent converts a forwarded hook, rendering its tool arguments the way
+// permission requests are rendered so policy patterns match both alike.
+func hookEvent(ev hookserver.Event) HookEvent {
+	h := HookEvent{
+		Name:     ev.Name,
+		CWD:      ev.CWD,
+		ToolName: ev.ToolName,
+		Result:   ev.ToolResult,
+		Prompt:   ev.Prompt,
+		Error:    ev.Error,
+	}
+	if len(ev.ToolArgs) > 0 {
+		var args any
+		if err := json.Unmarshal(ev.ToolArgs, &args); err == nil {
+			h.Args = formatArgs(args)
+		} else {
+			h.Args = string(ev.ToolArgs)
+		}
+	}
+	return h
+}
@@ -0,0 +1,42 @@
+package copilot
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/e-9/copilot-icq/internal/infra/hookserver"
+	"github.com/e-9/copilot-icq/internal/policy"
+)
+
+func TestHandleHook(t *testing.T) {
+	pol, err := policy.New(policy.Rules{DeniedPatterns: []string{"rm -rf /"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Adapter{events: make(chan Event, 1)}
+	a.SetPolicy(pol)
+
+	dec := a.HandleHook(hookserver.Event{
+		Name:      hookserver.PreToolUse,
+		SessionID: "s1",
+		ToolName:  "bash",
+		ToolArgs:  json.RawMessage(`{"command":"rm -rf /","description":"clean up"}`),
+	})
+	if !dec.Deny || dec.Reason == "" {
+		t.Errorf("decision = %+v, want a denial with a reason", dec)
+	}
+
+	ev := <-a.events
+	if ev.Type != EventHook || ev.SessionID != "s1" {
+		t.Fatalf("event = %+v", ev)
+	}
+	if h := ev.Hook; !h.Denied || h.Args != "rm -rf /" {
+		t.Errorf("hook = %+v", h)
+	}
+
+	// A full channel drops the event rather than blocking the CLI.
+	a.events <- Event{}
+	if dec := a.HandleHook(hookserver.Event{Name: hookserver.PostToolUse}); dec.Deny {
+		t.Error("postToolUse is never denied")
+	}
+}
@@ -0,0 +1,144 @@
+package daemon
+
+import (
+	"context"
+	"sync"
+
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/infra/notifier"
+	"github.com/e-9/copilot-icq/internal/infra/webhook"
+)
+
+// announceBuffer is how many events the announcer may fall behind before
+// it drops them rather than hold up the relay.
+const announceBuffer = 256
+
+// Announcer sends the desktop notifications and webhooks for the daemon's
+// sessions, once however many TUIs are attached. It works through events
+// on its own goroutine, so a slow notifier never holds up the clients.
+type Announcer struct {
+	backend  copilot.Backend
+	notifier notifier.Notifier // nil sends no notifications
+	webhooks *webhook.Dispatcher
+
+	// Muted reports whether the user muted a session's notifications;
+	// nil mutes none.
+	Muted func(id string) bool
+	// Watched reports whether a TUI is showing the session, which needs
+	// no notification; nil watches none.
+	Watched func(id string) bool
+	// OnError is told when a notification fails; nil ignores failures.
+	OnError func(error)
+
+	events chan copilot.Event
+	done   chan struct{}
+	once   sync.Once
+
+	replies  map[string]string // sessionID → reply of the running turn
+	sessions map[string]domain.Session
+}
+
+// NewAnnouncer creates an announcer for backend's events. Run starts it.
+func NewAnnouncer(backend copilot.Backend, n notifier.Notifier, hooks *webhook.Dispatcher) *Announcer {
+	return &Announcer{
+		backend:  backend,
+		notifier: n,
+		webhooks: hooks,
+		events:   make(chan copilot.Event, announceBuffer),
+		done:     make(chan struct{}),
+		replies:  make(map[string]string),
+		sessions: make(map[string]domain.Session),
+	}
+}
+
+// Handle queues an event. It never blocks; when the announcer has fallen
+// too far behind, the event is dropped.
+func (a *Announcer) Handle(evt copilot.Event) {
+	select {
+	case a.events <- evt:
+	default:
+	}
+}
+
+// Run announces queued events until Close is called.
+func (a *Announcer) Run() {
+	for {
+		select {
+		case evt := <-a.events:
+			a.announce(evt)
+		case <-a.done:
+			return
+		}
+	}
+}
+
+// Close stops Run. Events still queued are dropped.
+func (a *Announcer) Close() {
+	a.once.Do(func() { close(a.done) })
+}
+
+// announce publishes an event to the webhooks and notifies about it, as a
+// TUI does on its own. Both run before the event is applied.
+func (a *Announcer) announce(evt copilot.Event) {
+	id := evt.SessionID
+	if evt.Type == copilot.EventLifecycle {
+		// The session was created, renamed or deleted; look it up again
+		delete(a.sessions, id)
+	}
+	if env, ok := webhook.EnvelopeFor(evt, a.replies[id]); ok && a.webhooks != nil {
+		s := a.session(id)
+		env.SessionName, env.CWD = s.DisplayName(), s.CWD
+		a.webhooks.Publish(env)
+	}
+
+	a.notify(evt)
+
+	// Keep the turn's reply for the idle notification and webhook
+	if e := evt.SessionEvent; evt.Type == copilot.EventSession && e != nil {
+		switch e.Type {
+		case sdk.AssistantMessage:
+			if e.Data.Content != nil && *e.Data.Content != "" {
+				a.replies[id] = *e.Data.Content
+			}
+		case sdk.SessionIdle:
+			delete(a.replies, id)
+		}
+	}
+}
+
+// notify tells the user when an event means a session needs attention,
+// unless it is muted or a TUI is showing it.
+func (a *Announcer) notify(evt copilot.Event) {
+	id := evt.SessionID
+	if a.notifier == nil || (a.Muted != nil && a.Muted(id)) || (a.Watched != nil && a.Watched(id)) {
+		return
+	}
+	n, ok := notifier.For(evt, a.replies[id])
+	if !ok {
+		return
+	}
+	n.Title = a.session(id).DisplayName()
+	if err := a.notifier.Notify(n); err != nil && a.OnError != nil {
+		a.OnError(err)
+	}
+}
+
+// session looks a session up, listing the sessions again when it is new.
+// An unknown session is named by its ID.
+func (a *Announcer) session(id string) domain.Session {
+	if s, ok := a.sessions[id]; ok {
+		return s
+	}
+	if sessions, err := a.backend.ListSessions(context.Background()); err == nil {
+		for _, s := range sessions {
+			a.sessions[s.ID] = s
+		}
+	}
+	if s, ok := a.sessions[id]; ok {
+		return s
+	}
+	return domain.Session{ID: id, Summary: id}
+}
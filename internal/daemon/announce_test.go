@@ -0,0 +1,106 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/copilot/copilottest"
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/infra/notifier"
+	"github.com/e-9/copilot-icq/internal/infra/webhook"
+)
+
+type recordNotifier struct{ got []notifier.Notification }
+
+func (r *recordNotifier) Notify(n notifier.Notification) error {
+	r.got = append(r.got, n)
+	return nil
+}
+
+func TestAnnouncerNotifiesOnce(t *testing.T) {
+	bodies := make(chan []byte, 10)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies <- b
+	}))
+	defer hook.Close()
+	hooks := webhook.New([]webhook.Endpoint{{URL: hook.URL, Events: map[string]bool{webhook.SessionIdle: true}}}, nil, time.Millisecond)
+	defer hooks.Close(time.Second)
+
+	fake := copilottest.New(
+		domain.Session{ID: "a", Summary: "Fix the build", CWD: "/repo"},
+		domain.Session{ID: "b", Summary: "Watched"},
+		domain.Session{ID: "c", Summary: "Muted"},
+	)
+	rec := &recordNotifier{}
+	a := NewAnnouncer(fake, rec, hooks)
+	a.Watched = func(id string) bool { return id == "b" }
+	a.Muted = func(id string) bool { return id == "c" }
+
+	reply := "All   green\nnow"
+	for _, id := range []string{"a", "b", "c"} {
+		a.announce(copilot.Event{Type: copilot.EventSession, SessionID: id, SessionEvent: &sdk.SessionEvent{Type: sdk.AssistantMessage, Data: sdk.Data{Content: &reply}}})
+		a.announce(copilot.Event{Type: copilot.EventSession, SessionID: id, SessionEvent: &sdk.SessionEvent{Type: sdk.SessionIdle}})
+	}
+	a.announce(copilot.Event{Type: copilot.EventPermission, SessionID: "a", Permission: &copilot.PermissionEvent{ToolName: "rm", Denied: true}})
+	a.announce(copilot.Event{Type: copilot.EventUserInput, SessionID: "a", UserInput: &copilot.UserInputEvent{Question: "Which branch?"}})
+
+	want := []notifier.Notification{
+		{SessionID: "a", Kind: notifier.Reply, Title: "Fix the build", Body: "All green now"},
+		{SessionID: "a", Kind: notifier.Question, Title: "Fix the build", Body: "Which branch?"},
+	}
+	if !reflect.DeepEqual(rec.got, want) {
+		t.Errorf("notifications = %+v, want %+v", rec.got, want)
+	}
+
+	// Webhooks go out for every session, watched or muted
+	for range 3 {
+		select {
+		case b := <-bodies:
+			var env webhook.Envelope
+			if err := json.Unmarshal(b, &env); err != nil {
+				t.Fatal(err)
+			}
+			if env.Event != webhook.SessionIdle || env.Data.Reply != reply {
+				t.Errorf("envelope = %+v", env)
+			}
+			if env.SessionID == "a" && (env.SessionName != "Fix the build" || env.CWD != "/repo") {
+				t.Errorf("envelope = %+v, want a's name and directory", env)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhook not delivered")
+		}
+	}
+}
+
+func TestWatchedSessions(t *testing.T) {
+	fake := copilottest.New(domain.Session{ID: "a"})
+	srv, path := startServer(t, fake)
+	c := attach(t, path)
+	if srv.Watched("a") {
+		t.Fatal("no client shows a yet")
+	}
+	if err := c.Watch(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	if !srv.Watched("a") {
+		t.Error("a should be watched")
+	}
+	c.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.Watched("a") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if srv.Watched("a") {
+		t.Error("a detached client watches nothing")
+	}
+}
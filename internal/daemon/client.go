@@ -0,0 +1,306 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/infra/hookserver"
+	"github.com/e-9/copilot-icq/internal/policy"
+)
+
+// ErrDetached is returned for calls made after the connection to the
+// daemon was lost.
+var ErrDetached = errors.New("not connected to the copilot-icq daemon")
+
+// Client is a copilot.Backend served by a daemon. Closing it detaches; the
+// daemon and its sessions keep running.
+type Client struct {
+	path     string
+	conn     net.Conn
+	readOnly atomic.Bool
+
+	wmu sync.Mutex
+	enc *json.Encoder
+
+	mu     sync.Mutex
+	calls  map[uint64]chan message
+	nextID uint64
+	err    error // set once the connection is gone
+
+	events chan copilot.Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+var _ copilot.Backend = (*Client)(nil)
+
+// NewClient creates a client for the daemon at path. Start connects.
+func NewClient(path string) *Client {
+	return &Client{
+		path:   path,
+		calls:  make(map[uint64]chan message),
+		events: make(chan copilot.Event, 64),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start attaches to the daemon and subscribes to its events.
+func (c *Client) Start(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", c.path)
+	if err != nil {
+		return fmt.Errorf("attach to daemon: %w", err)
+	}
+	c.conn = conn
+	c.enc = json.NewEncoder(conn)
+	go c.read()
+
+	var info infoResult
+	if err := c.call(ctx, methodInfo, nil, &info); err != nil {
+		return err
+	}
+	c.readOnly.Store(info.ReadOnly)
+	return c.call(ctx, methodSubscribe, nil, nil)
+}
+
+// Close detaches from the daemon.
+func (c *Client) Close() error {
+	c.once.Do(func() {
+		close(c.done)
+		if c.conn != nil {
+			c.conn.Close()
+		}
+	})
+	return nil
+}
+
+func (c *Client) Events() <-chan copilot.Event { return c.events }
+
+// ReadOnly reports whether the daemon's backend is read-only.
+func (c *Client) ReadOnly() bool { return c.readOnly.Load() }
+
+// SetPolicy does nothing: the daemon applies its own policy.
+func (c *Client) SetPolicy(*policy.Policy) {}
+
+// HandleHook does nothing: hooks go to the daemon, which relays them.
+func (c *Client) HandleHook(hookserver.Event) hookserver.Decision { return hookserver.Decision{} }
+
+func (c *Client) ListSessions(ctx context.Context) ([]domain.Session, error) {
+	var sessions []domain.Session
+	err := c.call(ctx, methodList, nil, &sessions)
+	return sessions, err
+}
+
+func (c *Client) ResumeSession(ctx context.Context, sessionID, model string) (string, error) {
+	var res resumeResult
+	err := c.call(ctx, methodResume, sessionParams{SessionID: sessionID, Model: model}, &res)
+	return res.Model, err
+}
+
+func (c *Client) GetHistory(ctx context.Context, sessionID string) ([]domain.Message, error) {
+	var msgs []domain.Message
+	err := c.call(ctx, methodHistory, sessionParams{SessionID: sessionID}, &msgs)
+	return msgs, err
+}
+
+func (c *Client) Send(ctx context.Context, sessionID, text string) (string, error) {
+	var res sendResult
+	err := c.call(ctx, methodSend, sessionParams{SessionID: sessionID, Text: text}, &res)
+	return res.MessageID, err
+}
+
+func (c *Client) Abort(ctx context.Context, sessionID string) error {
+	return c.call(ctx, methodAbort, sessionParams{SessionID: sessionID}, nil)
+}
+
+func (c *Client) CreateSession(ctx context.Context, opts copilot.CreateSessionOptions) (domain.Session, error) {
+	var s domain.Session
+	err := c.call(ctx, methodCreate, opts, &s)
+	return s, err
+}
+
+func (c *Client) DeleteSession(ctx context.Context, sessionID string) error {
+	return c.call(ctx, methodDelete, sessionParams{SessionID: sessionID}, nil)
+}
+
+func (c *Client) ListModels(ctx context.Context) ([]copilot.ModelInfo, error) {
+	var models []copilot.ModelInfo
+	err := c.call(ctx, methodModels, nil, &models)
+	return models, err
+}
+
+// Watch tells the daemon which session this client shows, so it does not
+// notify about that one.
+func (c *Client) Watch(ctx context.Context, sessionID string) error {
+	return c.call(ctx, methodWatch, sessionParams{SessionID: sessionID}, nil)
+}
+
+func (c *Client) SwitchModel(ctx context.Context, sessionID, model string) error {
+	return c.call(ctx, methodSwitchModel, sessionParams{SessionID: sessionID, Model: model}, nil)
+}
+
+// call sends a request and waits for its response.
+func (c *Client) call(ctx context.Context, method string, params, result any) error {
+	req := request{Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = raw
+	}
+
+	ch := make(chan message, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	req.ID = c.nextID
+	c.calls[req.ID] = ch
+	c.mu.Unlock()
+
+	c.wmu.Lock()
+	err := c.enc.Encode(req)
+	c.wmu.Unlock()
+	if err != nil {
+		c.forget(req.ID)
+		return fmt.Errorf("%s: %w", method, err)
+	}
+
+	select {
+	case m := <-ch:
+		if m.Error != "" {
+			return remoteError(m.Error)
+		}
+		if result != nil && len(m.Result) > 0 {
+			return json.Unmarshal(m.Result, result)
+		}
+		return nil
+	case <-ctx.Done():
+		c.forget(req.ID)
+		return ctx.Err()
+	}
+}
+
+func (c *Client) forget(id uint64) {
+	c.mu.Lock()
+	delete(c.calls, id)
+	c.mu.Unlock()
+}
+
+// remoteError restores sentinel errors the app checks for.
+func remoteError(msg string) error {
+	if msg == copilot.ErrReadOnly.Error() {
+		return copilot.ErrReadOnly
+	}
+	return errors.New(msg)
+}
+
+// read dispatches responses and events until the connection closes; then
+// pending calls fail and the events channel is closed.
+func (c *Client) read() {
+	sc := bufio.NewScanner(c.conn)
+	sc.Buffer(make([]byte, 64<<10), maxRequest)
+	for sc.Scan() {
+		var m message
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+			continue
+		}
+		if m.Event != nil {
+			select {
+			case c.events <- c.event(*m.Event):
+			case <-c.done:
+			}
+			continue
+		}
+		c.mu.Lock()
+		ch, ok := c.calls[m.ID]
+		delete(c.calls, m.ID)
+		c.mu.Unlock()
+		if ok {
+			ch <- m
+		}
+	}
+
+	c.mu.Lock()
+	c.err = ErrDetached
+	for id, ch := range c.calls {
+		ch <- message{ID: id, Error: ErrDetached.Error()}
+		delete(c.calls, id)
+	}
+	c.mu.Unlock()
+	close(c.events)
+}
+
+// event turns a pushed event back into a copilot.Event. Prompts get a
+// response channel whose answer is sent back to the daemon.
+func (c *Client) event(w wireEvent) copilot.Event {
+	ev := copilot.Event{
+		Type:         w.Type,
+		SessionID:    w.SessionID,
+		SessionEvent: w.SessionEvent,
+		Lifecycle:    w.Lifecycle,
+		Hook:         w.Hook,
+	}
+	if p := w.Permission; p != nil {
+		ev.Permission = &copilot.PermissionEvent{
+			ToolName:   p.ToolName,
+			Kind:       p.Kind,
+			ToolCallID: p.ToolCallID,
+			Args:       p.Args,
+			Denied:     p.Denied,
+			DenyReason: p.DenyReason,
+			RequestID:  p.RequestID,
+		}
+		if p.RequestID != "" {
+			ch := make(chan copilot.PermissionResponse, 1)
+			ev.Permission.Response = ch
+			go func() {
+				select {
+				case r := <-ch:
+					c.call(context.Background(), methodAnswerPermission, answerParams{
+						RequestID:  p.RequestID,
+						Allow:      r.Allow,
+						ForSession: r.ForSession,
+					}, nil)
+				case <-c.done:
+				}
+			}()
+		}
+	}
+	if r := w.Resolved; r != nil {
+		ev.Resolved = &copilot.ResolvedEvent{RequestID: r.RequestID, Allow: r.Allow, Answer: r.Answer}
+	}
+	if q := w.UserInput; q != nil {
+		ch := make(chan copilot.UserInputResponse, 1)
+		ev.UserInput = &copilot.UserInputEvent{
+			Question:      q.Question,
+			Choices:       q.Choices,
+			AllowFreeform: q.AllowFreeform,
+			Response:      ch,
+			RequestID:     q.RequestID,
+		}
+		go func() {
+			select {
+			case r := <-ch:
+				c.call(context.Background(), methodAnswerInput, answerParams{
+					RequestID:   q.RequestID,
+					Answer:      r.Answer,
+					WasFreeform: r.WasFreeform,
+				}, nil)
+			case <-c.done:
+			}
+		}()
+	}
+	return ev
+}
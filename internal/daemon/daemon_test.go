@@ -0,0 +1,209 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/copilot/copilottest"
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+func startDaemon(t *testing.T, backend copilot.Backend) string {
+	t.Helper()
+	_, path := startServer(t, backend)
+	return path
+}
+
+func startServer(t *testing.T, backend copilot.Backend) (*Server, string) {
+	t.Helper()
+	// Unix socket paths are short; t.TempDir can exceed the limit on macOS.
+	dir, err := os.MkdirTemp("", "icq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "d.sock")
+
+	srv := NewServer(path, backend)
+	if err := srv.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve()
+	t.Cleanup(func() { srv.Close() })
+	return srv, path
+}
+
+func attach(t *testing.T, path string) *Client {
+	t.Helper()
+	c := NewClient(path)
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func nextEvent(t *testing.T, c *Client) copilot.Event {
+	t.Helper()
+	select {
+	case ev := <-c.Events():
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event from the daemon")
+		return copilot.Event{}
+	}
+}
+
+func TestClientCallsBackend(t *testing.T) {
+	fake := copilottest.New(domain.Session{ID: "a", CWD: "/repo"})
+	fake.History["a"] = []domain.Message{{Role: domain.RoleUser, Content: "hi"}}
+	c := attach(t, startDaemon(t, fake))
+	ctx := context.Background()
+
+	sessions, err := c.ListSessions(ctx)
+	if err != nil || len(sessions) != 1 || sessions[0].CWD != "/repo" {
+		t.Fatalf("sessions = %+v, %v", sessions, err)
+	}
+	msgs, err := c.GetHistory(ctx, "a")
+	if err != nil || len(msgs) != 1 || msgs[0].Content != "hi" {
+		t.Fatalf("history = %+v, %v", msgs, err)
+	}
+	if _, err := c.Send(ctx, "a", "go"); err != nil {
+		t.Fatal(err)
+	}
+	if sent := fake.Sent(); len(sent) != 1 || sent[0].Text != "go" {
+		t.Errorf("backend got %+v", sent)
+	}
+
+	fake.Err = copilot.ErrReadOnly
+	if err := c.Abort(ctx, "a"); !errors.Is(err, copilot.ErrReadOnly) {
+		t.Errorf("Abort = %v, want ErrReadOnly across the socket", err)
+	}
+
+	fake.EmitSession("a", sdk.SessionEvent{Type: sdk.SessionIdle})
+	if ev := nextEvent(t, c); ev.SessionEvent == nil || ev.SessionEvent.Type != sdk.SessionIdle {
+		t.Errorf("event = %+v", ev)
+	}
+}
+
+func TestPromptsReplayInOrder(t *testing.T) {
+	fake := copilottest.New(domain.Session{ID: "a"}, domain.Session{ID: "b"})
+	path := startDaemon(t, fake)
+	first := attach(t, path)
+
+	dropped := make(chan copilot.PermissionResponse, 1)
+	fake.Emit(copilot.Event{Type: copilot.EventPermission, SessionID: "a",
+		Permission: &copilot.PermissionEvent{ToolName: "rm", Response: dropped}})
+	for i := range 5 {
+		fake.Emit(copilot.Event{Type: copilot.EventPermission, SessionID: "b",
+			Permission: &copilot.PermissionEvent{ToolName: fmt.Sprintf("tool%d", i), Response: make(chan copilot.PermissionResponse, 1)}})
+		fake.Emit(copilot.Event{Type: copilot.EventUserInput, SessionID: "b",
+			UserInput: &copilot.UserInputEvent{Question: fmt.Sprintf("question%d", i), Response: make(chan copilot.UserInputResponse, 1)}})
+	}
+	for range 11 {
+		nextEvent(t, first)
+	}
+
+	// Deleting a session settles its prompts
+	if err := first.DeleteSession(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case r := <-dropped:
+		if r.Allow {
+			t.Error("a deleted session's prompt should be denied")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the deleted session's prompt was never settled")
+	}
+
+	second := attach(t, path)
+	for i := range 5 {
+		if ev := nextEvent(t, second); ev.Permission == nil || ev.Permission.ToolName != fmt.Sprintf("tool%d", i) {
+			t.Fatalf("replayed event %d = %+v, want tool%d", 2*i, ev, i)
+		}
+		if ev := nextEvent(t, second); ev.UserInput == nil || ev.UserInput.Question != fmt.Sprintf("question%d", i) {
+			t.Fatalf("replayed event %d = %+v, want question%d", 2*i+1, ev, i)
+		}
+	}
+	fake.EmitSession("b", sdk.SessionEvent{Type: sdk.SessionIdle})
+	if ev := nextEvent(t, second); ev.SessionEvent == nil {
+		t.Errorf("event = %+v, want nothing replayed past b's prompts", ev)
+	}
+}
+
+func TestPromptsOutliveClients(t *testing.T) {
+	fake := copilottest.New(domain.Session{ID: "a"})
+	path := startDaemon(t, fake)
+
+	first := attach(t, path)
+	answer := make(chan copilot.PermissionResponse, 1)
+	fake.Emit(copilot.Event{
+		Type:       copilot.EventPermission,
+		SessionID:  "a",
+		Permission: &copilot.PermissionEvent{ToolName: "bash", Args: "make", Response: answer},
+	})
+	if ev := nextEvent(t, first); ev.Permission == nil || ev.Permission.Args != "make" {
+		t.Fatalf("event = %+v", ev)
+	}
+
+	// Detach without answering; a later TUI gets the prompt on attach
+	first.Close()
+	second := attach(t, path)
+	ev := nextEvent(t, second)
+	if ev.Permission == nil || ev.Permission.ToolName != "bash" {
+		t.Fatalf("replayed event = %+v", ev)
+	}
+	ev.Permission.Response <- copilot.PermissionResponse{Allow: true}
+
+	select {
+	case r := <-answer:
+		if !r.Allow {
+			t.Error("the answer should reach the backend")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the backend never got an answer")
+	}
+
+	third := attach(t, path)
+	fake.EmitSession("a", sdk.SessionEvent{Type: sdk.SessionIdle})
+	if ev := nextEvent(t, third); ev.Permission != nil {
+		t.Error("an answered prompt should not be replayed")
+	}
+}
+
+func TestAnswersCloseOtherClientsPrompts(t *testing.T) {
+	fake := copilottest.New(domain.Session{ID: "a"})
+	path := startDaemon(t, fake)
+	first, second := attach(t, path), attach(t, path)
+
+	answer := make(chan copilot.UserInputResponse, 1)
+	fake.Emit(copilot.Event{
+		Type:      copilot.EventUserInput,
+		SessionID: "a",
+		UserInput: &copilot.UserInputEvent{Question: "Which?", Choices: []string{"x", "y"}, Response: answer},
+	})
+	asked := nextEvent(t, first)
+	shown := nextEvent(t, second)
+	if asked.UserInput == nil || shown.UserInput == nil || shown.UserInput.RequestID == "" {
+		t.Fatalf("events = %+v, %+v; want the question with its request ID", asked, shown)
+	}
+
+	asked.UserInput.Response <- copilot.UserInputResponse{Answer: "y"}
+	ev := nextEvent(t, second)
+	if ev.Type != copilot.EventResolved || ev.SessionID != "a" || ev.Resolved == nil ||
+		ev.Resolved.RequestID != shown.UserInput.RequestID || ev.Resolved.Answer != "y" {
+		t.Errorf("event = %+v, want the question resolved with y", ev)
+	}
+	if r := <-answer; r.Answer != "y" {
+		t.Errorf("backend got %+v", r)
+	}
+}
@@ -0,0 +1,133 @@
+// Package daemon runs a copilot.Backend in the background and shares it with
+// TUIs over a Unix socket, so sessions, running turns and pending prompts
+// outlive any one terminal.
+//
+// The protocol is JSON lines. A client sends requests; the daemon answers
+// each with a message carrying the same ID. After "subscribe" the daemon
+// also pushes backend events, starting with the permission requests and
+// questions that are still waiting for an answer. Once one is answered,
+// every client is told, so the others can close it.
+//
+// The daemon sends the desktop notifications and webhooks itself, once for
+// all its TUIs. Each client says which session it shows with "watch", and
+// the daemon does not notify about those.
+package daemon
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+)
+
+// Methods.
+const (
+	methodInfo             = "info"
+	methodSubscribe        = "subscribe"
+	methodList             = "list"
+	methodResume           = "resume"
+	methodHistory          = "history"
+	methodSend             = "send"
+	methodAbort            = "abort"
+	methodCreate           = "create"
+	methodDelete           = "delete"
+	methodModels           = "models"
+	methodSwitchModel      = "switchModel"
+	methodAnswerPermission = "answerPermission"
+	methodAnswerInput      = "answerInput"
+	methodWatch            = "watch"
+)
+
+// SocketPath returns the socket the daemon listens on:
+// $COPILOT_ICQ_DAEMON_SOCKET, or ~/.copilot/copilot-icq-daemon.sock.
+func SocketPath() string {
+	if p := os.Getenv("COPILOT_ICQ_DAEMON_SOCKET"); p != "" {
+		return p
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".copilot", "copilot-icq-daemon.sock")
+}
+
+type request struct {
+	ID     uint64          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// message is a response (ID set) or a pushed event (Event set).
+type message struct {
+	ID     uint64          `json:"id,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Event  *wireEvent      `json:"event,omitempty"`
+}
+
+// wireEvent is a copilot.Event without its response channels; prompts are
+// answered by request ID instead.
+type wireEvent struct {
+	Type         copilot.EventType          `json:"type"`
+	SessionID    string                     `json:"sessionId,omitempty"`
+	SessionEvent *sdk.SessionEvent          `json:"sessionEvent,omitempty"`
+	Lifecycle    *sdk.SessionLifecycleEvent `json:"lifecycle,omitempty"`
+	Permission   *wirePermission            `json:"permission,omitempty"`
+	UserInput    *wireUserInput             `json:"userInput,omitempty"`
+	Hook         *copilot.HookEvent         `json:"hook,omitempty"`
+	Resolved     *wireResolved              `json:"resolved,omitempty"`
+}
+
+type wirePermission struct {
+	RequestID  string `json:"requestId,omitempty"` // empty for policy denials, which need no answer
+	ToolName   string `json:"toolName"`
+	Kind       string `json:"kind,omitempty"`
+	ToolCallID string `json:"toolCallId,omitempty"`
+	Args       string `json:"args,omitempty"`
+	Denied     bool   `json:"denied,omitempty"`
+	DenyReason string `json:"denyReason,omitempty"`
+}
+
+type wireUserInput struct {
+	RequestID     string   `json:"requestId"`
+	Question      string   `json:"question"`
+	Choices       []string `json:"choices,omitempty"`
+	AllowFreeform bool     `json:"allowFreeform,omitempty"`
+}
+
+// wireResolved tells clients a prompt was answered, so they can close it.
+type wireResolved struct {
+	RequestID string `json:"requestId"`
+	Allow     bool   `json:"allow,omitempty"`
+	Answer    string `json:"answer,omitempty"`
+}
+
+// Params and results.
+
+type infoResult struct {
+	ReadOnly bool `json:"readOnly"`
+}
+
+type sessionParams struct {
+	SessionID string `json:"sessionId"`
+	Model     string `json:"model,omitempty"`
+	Text      string `json:"text,omitempty"`
+}
+
+type resumeResult struct {
+	Model string `json:"model"`
+}
+
+type sendResult struct {
+	MessageID string `json:"messageId"`
+}
+
+type answerParams struct {
+	RequestID string `json:"requestId"`
+	// Permission answers
+	Allow      bool `json:"allow,omitempty"`
+	ForSession bool `json:"forSession,omitempty"`
+	// Input answers
+	Answer      string `json:"answer,omitempty"`
+	WasFreeform bool   `json:"wasFreeform,omitempty"`
+}
@@ -0,0 +1,448 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+)
+
+// eventBuffer is how many events a client may fall behind before the
+// daemon disconnects it.
+const eventBuffer = 256
+
+// maxRequest caps one request line; prompts can be long.
+const maxRequest = 8 << 20
+
+// Server shares a backend with the clients attached to its socket.
+type Server struct {
+	path    string
+	backend copilot.Backend
+	ln      net.Listener
+	wg      sync.WaitGroup
+
+	mu      sync.Mutex
+	clients map[*client]bool
+	prompts []*prompt // permission requests and questions awaiting an answer, oldest first
+	nextID  int
+	observe func(copilot.Event)
+}
+
+// prompt is a request the backend is blocked on until some client answers.
+type prompt struct {
+	id         string
+	event      wireEvent
+	permission chan<- copilot.PermissionResponse
+	input      chan<- copilot.UserInputResponse
+}
+
+type client struct {
+	conn   net.Conn
+	wmu    sync.Mutex
+	enc    *json.Encoder
+	events chan wireEvent // nil until the client subscribes
+	done   chan struct{}
+	once   sync.Once
+
+	watching string // session the client is showing, guarded by Server.mu
+}
+
+// NewServer creates a daemon for backend on the socket at path. The backend
+// must already be started.
+func NewServer(path string, backend copilot.Backend) *Server {
+	return &Server{
+		path:    path,
+		backend: backend,
+		clients: make(map[*client]bool),
+	}
+}
+
+// Running reports whether a daemon is listening at path.
+func Running(path string) bool {
+	conn, err := net.DialTimeout("unix", path, 200*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Observe has f called with every backend event before it reaches the
+// clients, e.g. to notify about it. f must not block. Call it before Serve.
+func (s *Server) Observe(f func(copilot.Event)) {
+	s.observe = f
+}
+
+// Watched reports whether an attached client is showing the session.
+func (s *Server) Watched(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		if c.watching == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Listen creates the socket, replacing a stale one left by a crashed
+// daemon. It fails if another daemon is already listening.
+func (s *Server) Listen() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("daemon socket: %w", err)
+	}
+	if Running(s.path) {
+		return fmt.Errorf("daemon socket %s is in use by another copilot-icq daemon", s.path)
+	}
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("daemon socket: %w", err)
+	}
+	ln, err := net.Listen("unix", s.path)
+	if err != nil {
+		return fmt.Errorf("daemon socket: %w", err)
+	}
+	if err := os.Chmod(s.path, 0o600); err != nil {
+		ln.Close()
+		return fmt.Errorf("daemon socket: %w", err)
+	}
+	s.ln = ln
+	return nil
+}
+
+// Serve relays backend events and handles clients until Close is called.
+func (s *Server) Serve() {
+	go s.relay()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		c := &client{conn: conn, enc: json.NewEncoder(conn), done: make(chan struct{})}
+		s.mu.Lock()
+		s.clients[c] = true
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(c)
+		}()
+	}
+}
+
+// Close disconnects every client and removes the socket. Prompts still
+// waiting are left to the backend, which is closed by the caller.
+func (s *Server) Close() error {
+	if s.ln == nil {
+		return nil
+	}
+	err := s.ln.Close()
+	s.mu.Lock()
+	for c := range s.clients {
+		c.close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	os.Remove(s.path)
+	return err
+}
+
+// relay forwards backend events to subscribed clients, keeping prompts
+// until one of them answers.
+func (s *Server) relay() {
+	for ev := range s.backend.Events() {
+		if s.observe != nil {
+			s.observe(ev)
+		}
+		w := wireEvent{
+			Type:         ev.Type,
+			SessionID:    ev.SessionID,
+			SessionEvent: ev.SessionEvent,
+			Lifecycle:    ev.Lifecycle,
+			Hook:         ev.Hook,
+		}
+		s.mu.Lock()
+		switch {
+		case ev.Permission != nil:
+			p := ev.Permission
+			w.Permission = &wirePermission{
+				ToolName:   p.ToolName,
+				Kind:       p.Kind,
+				ToolCallID: p.ToolCallID,
+				Args:       p.Args,
+				Denied:     p.Denied,
+				DenyReason: p.DenyReason,
+			}
+			if p.Response != nil {
+				w.Permission.RequestID = s.newRequestID()
+				s.prompts = append(s.prompts, &prompt{id: w.Permission.RequestID, event: w, permission: p.Response})
+			}
+		case ev.UserInput != nil:
+			q := ev.UserInput
+			w.UserInput = &wireUserInput{
+				RequestID:     s.newRequestID(),
+				Question:      q.Question,
+				Choices:       q.Choices,
+				AllowFreeform: q.AllowFreeform,
+			}
+			s.prompts = append(s.prompts, &prompt{id: w.UserInput.RequestID, event: w, input: q.Response})
+		case ev.Lifecycle != nil && ev.Lifecycle.Type == sdk.SessionLifecycleDeleted:
+			s.dropPrompts(ev.SessionID)
+		}
+		for c := range s.clients {
+			c.push(w)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) newRequestID() string {
+	s.nextID++
+	return strconv.Itoa(s.nextID)
+}
+
+// push queues an event for a subscribed client, disconnecting it when it
+// has fallen too far behind. Called with s.mu held.
+func (c *client) push(w wireEvent) {
+	if c.events == nil {
+		return
+	}
+	select {
+	case c.events <- w:
+	default:
+		c.close()
+	}
+}
+
+func (c *client) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+func (c *client) write(m message) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return c.enc.Encode(m)
+}
+
+// writeEvents sends queued events until the client goes away.
+func (c *client) writeEvents(events <-chan wireEvent) {
+	for {
+		select {
+		case w := <-events:
+			if c.write(message{Event: &w}) != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (s *Server) handle(c *client) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, c)
+		s.mu.Unlock()
+		c.close()
+	}()
+
+	sc := bufio.NewScanner(c.conn)
+	sc.Buffer(make([]byte, 64<<10), maxRequest)
+	for sc.Scan() {
+		var req request
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			c.write(message{Error: fmt.Sprintf("bad request: %v", err)})
+			continue
+		}
+		switch req.Method {
+		case methodSubscribe:
+			s.subscribe(c, req.ID)
+			continue
+		case methodWatch:
+			var p sessionParams
+			err := decode(req, &p)
+			if err == nil {
+				s.mu.Lock()
+				c.watching = p.SessionID
+				s.mu.Unlock()
+			}
+			m := message{ID: req.ID}
+			if err != nil {
+				m.Error = err.Error()
+			}
+			c.write(m)
+			continue
+		}
+		// Requests such as resume can take a while; answer them in any order
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			result, err := s.call(req)
+			m := message{ID: req.ID, Result: result}
+			if err != nil {
+				m.Error = err.Error()
+			}
+			c.write(m)
+		}()
+	}
+}
+
+// subscribe starts pushing events to c, beginning with the open prompts in
+// the order they arrived, so a newly attached TUI can answer what an
+// earlier one left waiting.
+func (s *Server) subscribe(c *client, id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.events != nil {
+		c.write(message{ID: id})
+		return
+	}
+	c.events = make(chan wireEvent, eventBuffer)
+	c.write(message{ID: id})
+	for _, p := range s.prompts {
+		c.push(p.event)
+	}
+	go c.writeEvents(c.events)
+}
+
+func (s *Server) call(req request) (json.RawMessage, error) {
+	ctx := context.Background()
+	var p sessionParams
+	var result any
+	var err error
+	switch req.Method {
+	case methodInfo:
+		result = infoResult{ReadOnly: s.backend.ReadOnly()}
+	case methodList:
+		result, err = s.backend.ListSessions(ctx)
+	case methodResume:
+		if err = decode(req, &p); err == nil {
+			var model string
+			model, err = s.backend.ResumeSession(ctx, p.SessionID, p.Model)
+			result = resumeResult{Model: model}
+		}
+	case methodHistory:
+		if err = decode(req, &p); err == nil {
+			result, err = s.backend.GetHistory(ctx, p.SessionID)
+		}
+	case methodSend:
+		if err = decode(req, &p); err == nil {
+			var id string
+			id, err = s.backend.Send(ctx, p.SessionID, p.Text)
+			result = sendResult{MessageID: id}
+		}
+	case methodAbort:
+		if err = decode(req, &p); err == nil {
+			err = s.backend.Abort(ctx, p.SessionID)
+		}
+	case methodCreate:
+		var opts copilot.CreateSessionOptions
+		if err = decode(req, &opts); err == nil {
+			result, err = s.backend.CreateSession(ctx, opts)
+		}
+	case methodDelete:
+		if err = decode(req, &p); err == nil {
+			if err = s.backend.DeleteSession(ctx, p.SessionID); err == nil {
+				s.mu.Lock()
+				s.dropPrompts(p.SessionID)
+				s.mu.Unlock()
+			}
+		}
+	case methodModels:
+		result, err = s.backend.ListModels(ctx)
+	case methodSwitchModel:
+		if err = decode(req, &p); err == nil {
+			err = s.backend.SwitchModel(ctx, p.SessionID, p.Model)
+		}
+	case methodAnswerPermission, methodAnswerInput:
+		var a answerParams
+		if err = decode(req, &a); err == nil {
+			s.answer(req.Method, a)
+		}
+	default:
+		return nil, fmt.Errorf("unknown method %q", req.Method)
+	}
+	if err != nil || result == nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+func decode(req request, v any) error {
+	if err := json.Unmarshal(req.Params, v); err != nil {
+		return fmt.Errorf("%s: bad params: %w", req.Method, err)
+	}
+	return nil
+}
+
+// answer settles a prompt and tells every client, so TUIs still showing it
+// close it. The first answer wins; later ones, e.g. from a second TUI
+// showing the same prompt, are ignored.
+func (s *Server) answer(method string, a answerParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.prompts, func(p *prompt) bool { return p.id == a.RequestID })
+	if i < 0 {
+		return
+	}
+	p := s.prompts[i]
+	resolved := &wireResolved{RequestID: a.RequestID}
+	switch {
+	case method == methodAnswerPermission && p.permission != nil:
+		p.permission <- copilot.PermissionResponse{Allow: a.Allow, ForSession: a.ForSession}
+		resolved.Allow = a.Allow
+	case method == methodAnswerInput && p.input != nil:
+		p.input <- copilot.UserInputResponse{Answer: a.Answer, WasFreeform: a.WasFreeform}
+		resolved.Answer = a.Answer
+	default:
+		return
+	}
+	s.prompts = slices.Delete(s.prompts, i, i+1)
+	s.resolved(p, resolved)
+}
+
+// dropPrompts denies the prompts of a deleted session, so nothing waits on
+// them and later subscribers are not shown them. Called with s.mu held.
+func (s *Server) dropPrompts(sessionID string) {
+	s.prompts = slices.DeleteFunc(s.prompts, func(p *prompt) bool {
+		if p.event.SessionID != sessionID {
+			return false
+		}
+		// Nothing may be left to read the answer; never block on it
+		if p.permission != nil {
+			select {
+			case p.permission <- copilot.PermissionResponse{}:
+			default:
+			}
+		} else {
+			select {
+			case p.input <- copilot.UserInputResponse{}:
+			default:
+			}
+		}
+		s.resolved(p, &wireResolved{RequestID: p.id})
+		return true
+	})
+}
+
+// resolved tells every client a prompt was settled. Called with s.mu held.
+func (s *Server) resolved(p *prompt, r *wireResolved) {
+	w := wireEvent{Type: copilot.EventResolved, SessionID: p.event.SessionID, Resolved: r}
+	for c := range s.clients {
+		c.push(w)
+	}
+}
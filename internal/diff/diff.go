@@ -0,0 +1,288 @@
+// Package diff reads the patches the agent's edit tools make, as unified
+// diffs or in apply_patch form, into files and hunks.
+package diff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Op is what a patch does to a file.
+type Op string
+
+const (
+	Modified Op = "modified"
+	Added    Op = "added"
+	Deleted  Op = "deleted"
+	Renamed  Op = "renamed"
+)
+
+// Kind is whether a line is kept, added or removed.
+type Kind int
+
+const (
+	Context Kind = iota
+	Add
+	Del
+)
+
+// Line is one line of a hunk, without its +, - or space prefix.
+type Line struct {
+	Kind Kind
+	Text string
+	Old  int // line number in the old file; 0 for added lines or when unknown
+	New  int // line number in the new file; 0 for removed lines or when unknown
+}
+
+// Hunk is a run of changed lines with their context.
+type Hunk struct {
+	Header string // what follows the @@ marker, often the enclosing function
+	Lines  []Line
+}
+
+// File is the changes a patch makes to one file.
+type File struct {
+	Path    string
+	OldPath string // the path before a rename
+	Op      Op
+	Hunks   []Hunk
+}
+
+// Stats counts the lines a hunk adds and removes.
+func (h Hunk) Stats() (added, removed int) {
+	for _, l := range h.Lines {
+		switch l.Kind {
+		case Add:
+			added++
+		case Del:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// Stats counts the lines a file's hunks add and remove.
+func (f File) Stats() (added, removed int) {
+	for _, h := range f.Hunks {
+		a, r := h.Stats()
+		added += a
+		removed += r
+	}
+	return added, removed
+}
+
+// Parse reads a patch in apply_patch form ("*** Begin Patch") or as a
+// unified diff, with or without git's headers. Lines it does not
+// understand are skipped, so a truncated patch still shows what it has.
+func Parse(patch string) []File {
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	// A final newline ends the last line; it does not start an empty one
+	if n := len(lines); n > 0 && lines[n-1] == "" {
+		lines = lines[:n-1]
+	}
+	for _, l := range lines {
+		if strings.HasPrefix(l, "*** Begin Patch") || strings.HasPrefix(l, "*** Update File: ") ||
+			strings.HasPrefix(l, "*** Add File: ") || strings.HasPrefix(l, "*** Delete File: ") {
+			return parseApplyPatch(lines)
+		}
+	}
+	return parseUnified(lines)
+}
+
+// parseApplyPatch reads the apply_patch format. It has no line numbers,
+// except for added files, which are numbered from the top.
+func parseApplyPatch(lines []string) []File {
+	var files []File
+	var f *File
+	var h *Hunk
+	start := func(path string, op Op) {
+		files = append(files, File{Path: path, Op: op})
+		f, h = &files[len(files)-1], nil
+	}
+	for _, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "*** Update File: "):
+			start(strings.TrimSpace(strings.TrimPrefix(l, "*** Update File: ")), Modified)
+		case strings.HasPrefix(l, "*** Add File: "):
+			start(strings.TrimSpace(strings.TrimPrefix(l, "*** Add File: ")), Added)
+		case strings.HasPrefix(l, "*** Delete File: "):
+			start(strings.TrimSpace(strings.TrimPrefix(l, "*** Delete File: ")), Deleted)
+		case strings.HasPrefix(l, "*** Move to: "):
+			if f != nil {
+				f.OldPath, f.Path, f.Op = f.Path, strings.TrimSpace(strings.TrimPrefix(l, "*** Move to: ")), Renamed
+			}
+		case strings.HasPrefix(l, "***"):
+			// Begin Patch, End Patch, End of File
+		case f == nil:
+		case strings.HasPrefix(l, "@@"):
+			f.Hunks = append(f.Hunks, Hunk{Header: strings.TrimSpace(strings.TrimPrefix(l, "@@"))})
+			h = &f.Hunks[len(f.Hunks)-1]
+		default:
+			line, ok := patchLine(l)
+			if !ok {
+				continue
+			}
+			if h == nil {
+				f.Hunks = append(f.Hunks, Hunk{})
+				h = &f.Hunks[len(f.Hunks)-1]
+			}
+			if f.Op == Added {
+				line.New = len(h.Lines) + 1
+			}
+			h.Lines = append(h.Lines, line)
+		}
+	}
+	return files
+}
+
+// parseUnified reads a unified diff, numbering lines from the hunk headers.
+func parseUnified(lines []string) []File {
+	var files []File
+	var f *File
+	var h *Hunk
+	var oldLine, newLine, oldLeft, newLeft int
+	numbered := false
+	start := func() {
+		files = append(files, File{Op: Modified})
+		f, h = &files[len(files)-1], nil
+	}
+	// Counts from the hunk header end the hunk, so a removed line that
+	// starts with "--" is not taken for a file header
+	inHunk := func() bool { return h != nil && (oldLeft != 0 || newLeft != 0) }
+
+	for _, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "diff --git "):
+			start()
+			if i := strings.LastIndex(l, " b/"); i >= 0 {
+				f.Path = l[i+3:]
+			}
+		case strings.HasPrefix(l, "@@"):
+			if f == nil {
+				start()
+			}
+			var header string
+			oldLine, oldLeft, newLine, newLeft, header = hunkHeader(l)
+			numbered = oldLine > 0 || newLine > 0
+			f.Hunks = append(f.Hunks, Hunk{Header: header})
+			h = &f.Hunks[len(f.Hunks)-1]
+		case inHunk():
+			line, ok := patchLine(l)
+			if !ok {
+				continue
+			}
+			switch line.Kind {
+			case Context:
+				line.Old, line.New = oldLine, newLine
+				oldLine, newLine, oldLeft, newLeft = oldLine+1, newLine+1, oldLeft-1, newLeft-1
+			case Add:
+				line.New = newLine
+				newLine, newLeft = newLine+1, newLeft-1
+			case Del:
+				line.Old = oldLine
+				oldLine, oldLeft = oldLine+1, oldLeft-1
+			}
+			if !numbered {
+				line.Old, line.New = 0, 0
+			}
+			h.Lines = append(h.Lines, line)
+		case strings.HasPrefix(l, "--- "):
+			if f == nil || len(f.Hunks) > 0 {
+				start()
+			}
+			if p := diffPath(l[4:]); p != "" {
+				f.OldPath = p
+			} else {
+				f.Op = Added
+			}
+		case strings.HasPrefix(l, "+++ ") && f != nil:
+			if p := diffPath(l[4:]); p != "" {
+				f.Path = p
+			} else {
+				f.Op, f.Path = Deleted, f.OldPath
+			}
+		case strings.HasPrefix(l, "new file mode") && f != nil:
+			f.Op = Added
+		case strings.HasPrefix(l, "deleted file mode") && f != nil:
+			f.Op = Deleted
+		case strings.HasPrefix(l, "rename from ") && f != nil:
+			f.OldPath, f.Op = strings.TrimPrefix(l, "rename from "), Renamed
+		case strings.HasPrefix(l, "rename to ") && f != nil:
+			f.Path, f.Op = strings.TrimPrefix(l, "rename to "), Renamed
+		}
+	}
+
+	for i := range files {
+		if files[i].Op == Modified && files[i].OldPath != "" && files[i].OldPath != files[i].Path {
+			files[i].Op = Renamed
+		}
+		if files[i].OldPath == files[i].Path || files[i].Op != Renamed {
+			files[i].OldPath = ""
+		}
+	}
+	return files
+}
+
+// hunkHeader reads "@@ -12,3 +12,4 @@ func main()". Counts default to one
+// line; a header without ranges gives counts of -1, which never run out,
+// so the hunk runs to the next header.
+func hunkHeader(l string) (oldStart, oldCount, newStart, newCount int, header string) {
+	rest := strings.TrimPrefix(l, "@@")
+	end := strings.Index(rest, "@@")
+	if end < 0 {
+		return 0, -1, 0, -1, strings.TrimSpace(rest)
+	}
+	header = strings.TrimSpace(rest[end+2:])
+	for _, r := range strings.Fields(rest[:end]) {
+		start, count := r[1:], "1"
+		if i := strings.IndexByte(start, ','); i >= 0 {
+			start, count = start[:i], start[i+1:]
+		}
+		s, _ := strconv.Atoi(start)
+		c, _ := strconv.Atoi(count)
+		switch r[0] {
+		case '-':
+			oldStart, oldCount = s, c
+		case '+':
+			newStart, newCount = s, c
+		}
+	}
+	if oldCount == 0 && newCount == 0 {
+		// An empty range, or none at all: read up to the next header
+		oldCount, newCount = -1, -1
+	}
+	return oldStart, oldCount, newStart, newCount, header
+}
+
+// diffPath returns the path in a ---/+++ header, without git's a/ and b/
+// prefixes or a trailing timestamp, or "" for /dev/null.
+func diffPath(s string) string {
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if s == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/") {
+		s = s[2:]
+	}
+	return s
+}
+
+// patchLine reads a +, - or context line. A blank line counts as empty
+// context, as some tools strip trailing spaces.
+func patchLine(l string) (Line, bool) {
+	if l == "" {
+		return Line{Kind: Context}, true
+	}
+	switch l[0] {
+	case ' ':
+		return Line{Kind: Context, Text: l[1:]}, true
+	case '+':
+		return Line{Kind: Add, Text: l[1:]}, true
+	case '-':
+		return Line{Kind: Del, Text: l[1:]}, true
+	}
+	return Line{}, false
+}
@@ -0,0 +1,129 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseUnified(t *testing.T) {
+	patch := `diff --git a/main.go b/main.go
+index 83db48f..bf269f4 100644
+--- a/main.go
++++ b/main.go
+@@ -3,4 +3,4 @@ import "fmt"
+ func main() {
+-	fmt.Println("hi")
++	fmt.Println("hello")
+ }
+--- old
+@@ -10,2 +10,3 @@
+ a
++b
+ c
+diff --git a/NOTES.md b/NOTES.md
+new file mode 100644
+--- /dev/null
++++ b/NOTES.md
+@@ -0,0 +1,2 @@
++# Notes
++
+`
+	files := Parse(patch)
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2: %+v", len(files), files)
+	}
+	f := files[0]
+	if f.Path != "main.go" || f.Op != Modified || len(f.Hunks) != 2 || f.Hunks[0].Header != `import "fmt"` {
+		t.Fatalf("main.go = %+v", f)
+	}
+	want := []Line{
+		{Kind: Context, Text: "func main() {", Old: 3, New: 3},
+		{Kind: Del, Text: `	fmt.Println("hi")`, Old: 4},
+		{Kind: Add, Text: `	fmt.Println("hello")`, New: 4},
+		{Kind: Context, Text: "}", Old: 5, New: 5},
+		// The hunk's counts take "--- old" as a removed line, not a header
+		{Kind: Del, Text: "-- old", Old: 6},
+	}
+	if !reflect.DeepEqual(f.Hunks[0].Lines, want) {
+		t.Errorf("hunk lines = %+v", f.Hunks[0].Lines)
+	}
+	if a, r := f.Stats(); a != 2 || r != 2 {
+		t.Errorf("main.go stats = +%d -%d, want +2 -2", a, r)
+	}
+
+	if n := files[1]; n.Path != "NOTES.md" || n.Op != Added || len(n.Hunks[0].Lines) != 2 || n.Hunks[0].Lines[1].New != 2 {
+		t.Errorf("NOTES.md = %+v", n)
+	}
+}
+
+func TestParseApplyPatch(t *testing.T) {
+	patch := `*** Begin Patch
+*** Update File: src/app.ts
+*** Move to: src/main.ts
+@@ function start()
+-  run()
++  run(config)
+*** Add File: README.md
++# App
+*** Delete File: old.ts
+*** End Patch`
+	files := Parse(patch)
+	if len(files) != 3 {
+		t.Fatalf("got %d files, want 3: %+v", len(files), files)
+	}
+	if f := files[0]; f.Path != "src/main.ts" || f.OldPath != "src/app.ts" || f.Op != Renamed || f.Hunks[0].Header != "function start()" {
+		t.Errorf("renamed file = %+v", f)
+	}
+	if f := files[1]; f.Op != Added || f.Hunks[0].Lines[0] != (Line{Kind: Add, Text: "# App", New: 1}) {
+		t.Errorf("added file = %+v", f)
+	}
+	if f := files[2]; f.Path != "old.ts" || f.Op != Deleted {
+		t.Errorf("deleted file = %+v", f)
+	}
+}
+
+func TestParseApplyPatchTrailingNewline(t *testing.T) {
+	// Without an End Patch, the last hunk runs to the end of the patch
+	patch := "*** Update File: a.go\n@@\n x := 1\n-y := 2\n+y := 3\n\n z := 4\n"
+	files := Parse(patch)
+	if len(files) != 1 || len(files[0].Hunks) != 1 {
+		t.Fatalf("files = %+v", files)
+	}
+	want := []Line{
+		{Kind: Context, Text: "x := 1"},
+		{Kind: Del, Text: "y := 2"},
+		{Kind: Add, Text: "y := 3"},
+		{Kind: Context}, // an empty line inside the hunk is kept
+		{Kind: Context, Text: "z := 4"},
+	}
+	if got := files[0].Hunks[0].Lines; !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %+v", got)
+	}
+
+	added := Parse("*** Add File: b.txt\n+one\n+two\n")
+	if lines := added[0].Hunks[0].Lines; len(lines) != 2 || lines[1] != (Line{Kind: Add, Text: "two", New: 2}) {
+		t.Errorf("added lines = %+v", lines)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	files := Parse(Edit("a.go", "x := 1\ny := 2\nz := 3\n", "x := 1\ny := 20\nz := 3\nw := 4\n"))
+	if len(files) != 1 || files[0].Path != "a.go" || len(files[0].Hunks) != 1 {
+		t.Fatalf("files = %+v", files)
+	}
+	want := []Line{
+		{Kind: Context, Text: "x := 1"},
+		{Kind: Del, Text: "y := 2"},
+		{Kind: Add, Text: "y := 20"},
+		{Kind: Context, Text: "z := 3"},
+		{Kind: Add, Text: "w := 4"},
+	}
+	if got := files[0].Hunks[0].Lines; !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %+v", got)
+	}
+
+	created := Parse(Create("b.go", "package b\n"))
+	if len(created) != 1 || created[0].Op != Added || created[0].Hunks[0].Lines[0].Text != "package b" {
+		t.Errorf("created = %+v", created)
+	}
+}
@@ -0,0 +1,98 @@
+package diff
+
+import "strings"
+
+// maxCells caps the table matchLines builds to match two texts; bigger edits
+// show as all of the old text removed and all of the new added.
+const maxCells = 1 << 20
+
+// Edit returns an apply_patch patch for an edit that replaces old with new
+// in path, as the edit tool's arguments describe it. The tool does not say
+// where in the file the text is, so the patch has no line numbers.
+func Edit(path, old, new string) string {
+	var sb strings.Builder
+	sb.WriteString("*** Begin Patch\n*** Update File: " + path + "\n@@\n")
+	for _, l := range matchLines(splitLines(old), splitLines(new)) {
+		switch l.Kind {
+		case Add:
+			sb.WriteString("+")
+		case Del:
+			sb.WriteString("-")
+		default:
+			sb.WriteString(" ")
+		}
+		sb.WriteString(l.Text + "\n")
+	}
+	sb.WriteString("*** End Patch")
+	return sb.String()
+}
+
+// Create returns an apply_patch patch that adds path with text.
+func Create(path, text string) string {
+	var sb strings.Builder
+	sb.WriteString("*** Begin Patch\n*** Add File: " + path + "\n")
+	for _, l := range splitLines(text) {
+		sb.WriteString("+" + l + "\n")
+	}
+	sb.WriteString("*** End Patch")
+	return sb.String()
+}
+
+// matchLines matches the lines of two texts, keeping their longest common
+// subsequence as context, with removals before additions in each change.
+func matchLines(a, b []string) []Line {
+	if len(a)*len(b) > maxCells {
+		return replaceAll(a, b)
+	}
+	// lcs[i][j] is the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []Line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out = append(out, Line{Kind: Context, Text: a[i]})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, Line{Kind: Del, Text: a[i]})
+			i++
+		default:
+			out = append(out, Line{Kind: Add, Text: b[j]})
+			j++
+		}
+	}
+	return out
+}
+
+func replaceAll(a, b []string) []Line {
+	out := make([]Line, 0, len(a)+len(b))
+	for _, l := range a {
+		out = append(out, Line{Kind: Del, Text: l})
+	}
+	for _, l := range b {
+		out = append(out, Line{Kind: Add, Text: l})
+	}
+	return out
+}
+
+// splitLines splits text into lines, without an empty last line for a
+// trailing newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -22,6 +22,7 @@ ToolCalls []ToolCall
 
 // ToolCall represents a tool invocation shown in the chat.
 type ToolCall struct {
+ID       string // the agent's tool call ID; empty if unknown
 Name     string
 Status   ToolCallStatus
 Summary  string
@@ -30,6 +31,8 @@ Question string   // for ask_user tools, the question text
 Choices  []string // for ask_user tools, the selectable options
 FilePath string   // for edit/create tools, the target file
 Patch    string   // for edit/apply_patch tools, the diff content
+Args      string    // the tool's arguments as JSON
+StartedAt time.Time // when the tool started; zero if unknown
 }
 
 // ToolCallStatus tracks the state of a tool invocation.
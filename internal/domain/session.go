@@ -10,6 +10,7 @@ type Session struct {
 	ID           string    `yaml:"id"`
 	CWD          string    `yaml:"cwd"`
 	Summary      string    `yaml:"summary"`
+	Model        string    `yaml:"model,omitempty"` // last model the session reported; empty if unknown
 	SummaryCount int       `yaml:"summary_count"`
 	CreatedAt    time.Time `yaml:"created_at"`
 	UpdatedAt    time.Time `yaml:"updated_at"`
@@ -0,0 +1,229 @@
+// Package exporter writes conversations to files as markdown, JSON, JSONL
+// or self-contained HTML, with every tool call's arguments, result and
+// timestamps.
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+// Format is an export file format.
+type Format string
+
+const (
+	Markdown Format = "md"
+	JSON     Format = "json"
+	JSONL    Format = "jsonl"
+	HTML     Format = "html"
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{Markdown, JSON, JSONL, HTML}
+
+// ParseFormat parses a format name; "markdown" is accepted for md.
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(strings.TrimPrefix(s, "."))
+	if s == "markdown" {
+		return Markdown, nil
+	}
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown export format %q (want md, json, jsonl or html)", s)
+}
+
+// Conversation is a session and its messages.
+type Conversation struct {
+	Session  domain.Session
+	Messages []domain.Message
+}
+
+// Write writes c to w in format f.
+func Write(w io.Writer, f Format, c Conversation) error {
+	switch f {
+	case Markdown:
+		return writeMarkdown(w, c)
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(document{Version: 1, Session: sessionOf(c.Session), Messages: messagesOf(c.Messages)})
+	case JSONL:
+		return writeJSONL(w, c)
+	case HTML:
+		return writeHTML(w, c)
+	}
+	return fmt.Errorf("unknown export format %q", f)
+}
+
+// WriteFile writes c in format f to the file the template names inside
+// dir, creating directories as needed, and returns the file's path.
+func WriteFile(dir, tmpl string, f Format, c Conversation) (string, error) {
+	name, err := Filename(tmpl, c.Session, f)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("export %s: %w", c.Session.ShortID(), err)
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("export %s: %w", c.Session.ShortID(), err)
+	}
+	if err := Write(out, f, c); err != nil {
+		out.Close()
+		return "", fmt.Errorf("export %s: %w", c.Session.ShortID(), err)
+	}
+	if err := out.Close(); err != nil {
+		return "", fmt.Errorf("export %s: %w", c.Session.ShortID(), err)
+	}
+	return path, nil
+}
+
+// document is the JSON format.
+type document struct {
+	Version  int             `json:"version"`
+	Session  sessionRecord   `json:"session"`
+	Messages []messageRecord `json:"messages"`
+}
+
+type sessionRecord struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Summary   string     `json:"summary,omitempty"`
+	CWD       string     `json:"cwd,omitempty"`
+	Model     string     `json:"model,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+type messageRecord struct {
+	Role      domain.MessageRole `json:"role"`
+	Content   string             `json:"content,omitempty"`
+	Timestamp *time.Time         `json:"timestamp,omitempty"`
+	ToolCalls []toolRecord       `json:"toolCalls,omitempty"`
+}
+
+type toolRecord struct {
+	ID        string                `json:"id,omitempty"`
+	Name      string                `json:"name"`
+	Status    domain.ToolCallStatus `json:"status"`
+	Args      json.RawMessage       `json:"args,omitempty"`
+	Result    string                `json:"result,omitempty"`
+	StartedAt *time.Time            `json:"startedAt,omitempty"`
+	Command   string                `json:"command,omitempty"`
+	FilePath  string                `json:"filePath,omitempty"`
+	Patch     string                `json:"patch,omitempty"`
+	Question  string                `json:"question,omitempty"`
+	Choices   []string              `json:"choices,omitempty"`
+}
+
+func sessionOf(s domain.Session) sessionRecord {
+	return sessionRecord{
+		ID:        s.ID,
+		Name:      s.DisplayName(),
+		Summary:   s.Summary,
+		CWD:       s.CWD,
+		Model:     s.Model,
+		CreatedAt: timePtr(s.CreatedAt),
+		UpdatedAt: timePtr(s.UpdatedAt),
+	}
+}
+
+func messagesOf(msgs []domain.Message) []messageRecord {
+	out := make([]messageRecord, 0, len(msgs))
+	for _, m := range msgs {
+		r := messageRecord{Role: m.Role, Content: m.Content, Timestamp: timePtr(m.Timestamp)}
+		for _, tc := range m.ToolCalls {
+			t := toolRecord{
+				ID:        tc.ID,
+				Name:      tc.Name,
+				Status:    tc.Status,
+				Result:    tc.Summary,
+				StartedAt: timePtr(tc.StartedAt),
+				Command:   tc.Command,
+				FilePath:  tc.FilePath,
+				Patch:     tc.Patch,
+				Question:  tc.Question,
+				Choices:   tc.Choices,
+			}
+			if json.Valid([]byte(tc.Args)) {
+				t.Args = json.RawMessage(tc.Args)
+			}
+			r.ToolCalls = append(r.ToolCalls, t)
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// writeJSONL writes a session line followed by one line per message, each
+// tagged with its type.
+func writeJSONL(w io.Writer, c Conversation) error {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(struct {
+		Type string `json:"type"`
+		sessionRecord
+	}{"session", sessionOf(c.Session)}); err != nil {
+		return err
+	}
+	for _, m := range messagesOf(c.Messages) {
+		if err := enc.Encode(struct {
+			Type string `json:"type"`
+			messageRecord
+		}{"message", m}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// stamp formats a timestamp for people; zero times are left out.
+func stamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// statusIcon is how the markdown and HTML formats show a tool call's status.
+func statusIcon(s domain.ToolCallStatus) string {
+	switch s {
+	case domain.ToolCallComplete:
+		return "✓"
+	case domain.ToolCallFailed:
+		return "✗"
+	}
+	return "⏳"
+}
+
+// prettyArgs indents JSON arguments, leaving anything else as is.
+func prettyArgs(args string) string {
+	var v any
+	if json.Unmarshal([]byte(args), &v) != nil {
+		return args
+	}
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return args
+	}
+	return string(raw)
+}
@@ -0,0 +1,185 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+var at = time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+
+func conversation() Conversation {
+	return Conversation{
+		Session: domain.Session{ID: "3f2a91c0-aaaa", CWD: "/src/app", Summary: "Fix <login> bug", UpdatedAt: at},
+		Messages: []domain.Message{
+			{Role: domain.RoleUser, Content: "run the tests", Timestamp: at},
+			{Role: domain.RoleAssistant, Content: "Running **now**", Timestamp: at.Add(time.Second)},
+			{Role: domain.RoleAssistant, Timestamp: at.Add(3 * time.Second), ToolCalls: []domain.ToolCall{{
+				ID:        "call-1",
+				Name:      "bash",
+				Status:    domain.ToolCallFailed,
+				Args:      `{"command":"go test ./..."}`,
+				Summary:   "FAIL ```x```",
+				StartedAt: at.Add(2 * time.Second),
+			}}},
+		},
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var sb strings.Builder
+	if err := Write(&sb, Markdown, conversation()); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, want := range []string{
+		"# Copilot Session: Fix <login> bug",
+		"### 🧑 You (2026-03-01 10:00:00)",
+		"#### 🔧 bash ✗",
+		"- **Call ID**: `call-1`",
+		"- **Started**: 2026-03-01 10:00:02",
+		"\"command\": \"go test ./...\"",
+		"````\nFAIL ```x```\n````",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestJSONAndJSONL(t *testing.T) {
+	var sb strings.Builder
+	if err := Write(&sb, JSON, conversation()); err != nil {
+		t.Fatal(err)
+	}
+	var doc document
+	if err := json.Unmarshal([]byte(sb.String()), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Messages) != 3 || doc.Session.Name != "Fix <login> bug" {
+		t.Fatalf("doc = %+v", doc)
+	}
+	tc := doc.Messages[2].ToolCalls[0]
+	if tc.ID != "call-1" || !strings.Contains(string(tc.Args), `"command": "go test ./..."`) || tc.StartedAt == nil {
+		t.Errorf("tool call = %+v", tc)
+	}
+
+	sb.Reset()
+	if err := Write(&sb, JSONL, conversation()); err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	sc := bufio.NewScanner(strings.NewReader(sb.String()))
+	for sc.Scan() {
+		var line struct{ Type string }
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, line.Type)
+	}
+	if strings.Join(types, ",") != "session,message,message,message" {
+		t.Errorf("line types = %v", types)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	c := conversation()
+	c.Messages = append(c.Messages, domain.Message{Role: domain.RoleAssistant, Content: "<script>alert(1)</script>"})
+	var sb strings.Builder
+	if err := Write(&sb, HTML, c); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	if strings.Contains(out, "<script>alert") {
+		t.Error("raw HTML in messages must not reach the page")
+	}
+	for _, want := range []string{"<strong>now</strong>", "Fix &lt;login&gt; bug", `<details class="failed">`, "go test ./..."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html is missing %q", want)
+		}
+	}
+}
+
+func TestFilename(t *testing.T) {
+	s := conversation().Session
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{"", "copilot-session-3f2a91c0.md"},
+		{"{{.Project}}/{{.Date}}-{{.Name}}.{{.Ext}}", filepath.Join("app", "2026-03-01-Fix-login-bug.md")},
+	}
+	for _, tt := range tests {
+		got, err := Filename(tt.tmpl, s, Markdown)
+		if err != nil || got != tt.want {
+			t.Errorf("Filename(%q) = %q, %v, want %q", tt.tmpl, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"../{{.ID}}", "/tmp/{{.ID}}", "{{.Nope}}", "{{"} {
+		if _, err := Filename(bad, s, Markdown); err == nil {
+			t.Errorf("Filename(%q) should fail", bad)
+		}
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path, err := WriteFile(dir, "{{.Project}}/{{.ShortID}}.{{.Ext}}", JSONL, conversation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, "app", "3f2a91c0.jsonl") {
+		t.Errorf("path = %s", path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	now := at.Add(24 * time.Hour)
+	sessions := []domain.Session{
+		{ID: "a1", CWD: "/src/app", Summary: "Login bug", Model: "gpt-5", UpdatedAt: at},
+		{ID: "b2", CWD: "/src/app/web", Summary: "Styles", UpdatedAt: at.AddDate(0, 0, -30)},
+		{ID: "c3", CWD: "/src/other", Summary: "Docs", UpdatedAt: now},
+	}
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "a1,b2,c3"},
+		{"cwd:/src/app", "a1,b2"},
+		{"cwd:/src/*", "a1,c3"},
+		{"LOGIN", "a1"},
+		{"cwd:/src/app since:7d", "a1"},
+		{"until:2026-03-01", "a1,b2"},
+		{"model:GPT-5", "a1"},
+		{"id:c", "c3"},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.expr, now)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.expr, err)
+		}
+		var got []string
+		for _, s := range sessions {
+			if f.Match(s) {
+				got = append(got, s.ID)
+			}
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("%q matched %v, want %s", tt.expr, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"owner:me", "since:soon"} {
+		if _, err := ParseFilter(bad, now); err == nil {
+			t.Errorf("ParseFilter(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package exporter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+// DefaultTemplate names export files when no template is configured.
+const DefaultTemplate = "copilot-session-{{.ShortID}}.{{.Ext}}"
+
+// nameFields are what a filename template can use. Every field is safe to
+// put in a path: separators and other awkward characters are replaced.
+type nameFields struct {
+	ID      string // full session ID
+	ShortID string // first 8 characters of the ID
+	Name    string // session name, e.g. its summary
+	Project string // last directory of the session's CWD
+	Date    string // day of the last update, 2006-01-02
+	Created string // day the session was created, 2006-01-02
+	Ext     string // format extension: md, json, jsonl or html
+}
+
+// Filename renders a filename template, a text/template such as
+// "{{.Project}}/{{.Date}}-{{.Name}}.{{.Ext}}", for a session. An empty
+// template uses DefaultTemplate. The result is relative and may contain
+// directories the template itself adds.
+func Filename(tmpl string, s domain.Session, f Format) (string, error) {
+	if tmpl == "" {
+		tmpl = DefaultTemplate
+	}
+	t, err := template.New("filename").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("export filename template: %w", err)
+	}
+
+	fields := nameFields{
+		ID:      slug(s.ID),
+		ShortID: slug(s.ShortID()),
+		Name:    slug(s.DisplayName()),
+		Ext:     string(f),
+	}
+	if s.CWD != "" {
+		fields.Project = slug(filepath.Base(s.CWD))
+	}
+	if !s.UpdatedAt.IsZero() {
+		fields.Date = s.UpdatedAt.Local().Format("2006-01-02")
+	}
+	if !s.CreatedAt.IsZero() {
+		fields.Created = s.CreatedAt.Local().Format("2006-01-02")
+	}
+
+	var sb strings.Builder
+	if err := t.Execute(&sb, fields); err != nil {
+		return "", fmt.Errorf("export filename template: %w", err)
+	}
+	name := filepath.Clean(filepath.FromSlash(sb.String()))
+	if name == "." || filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("export filename template gives %q; it must name a file inside the export directory", sb.String())
+	}
+	return name, nil
+}
+
+// maxSlug keeps names from long summaries to a sensible length.
+const maxSlug = 60
+
+// slug makes s safe as one path element: letters, digits, '.', '_' and '-'
+// are kept and runs of anything else become a single '-'.
+func slug(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-' {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	out := strings.Trim(sb.String(), "-.")
+	if r := []rune(out); len(r) > maxSlug {
+		out = strings.TrimRight(string(r[:maxSlug]), "-.")
+	}
+	if out == "" {
+		return "session"
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package exporter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/e-9/copilot-icq/internal/config"
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+// Filter selects sessions. The zero Filter matches every session.
+type Filter struct {
+	terms []func(domain.Session) bool
+}
+
+// ParseFilter parses a filter expression: terms separated by spaces that a
+// session must all match.
+//
+//	cwd:~/src/app    the CWD is the directory or inside it; globs allowed
+//	name:login       the name contains the text
+//	model:gpt-5      the session's model
+//	id:3f2a          the ID starts with the text
+//	since:7d         updated in the last 7 days, or since a date (2006-01-02)
+//	until:2026-01-31 updated before the end of the day, or more than a duration ago
+//	login            a bare word matches the name or the CWD
+//
+// Text matches ignore case.
+func ParseFilter(expr string, now time.Time) (Filter, error) {
+	var f Filter
+	for _, term := range strings.Fields(expr) {
+		key, val, ok := strings.Cut(term, ":")
+		if !ok || val == "" {
+			word := strings.ToLower(term)
+			f.terms = append(f.terms, func(s domain.Session) bool {
+				return strings.Contains(strings.ToLower(s.DisplayName()), word) ||
+					strings.Contains(strings.ToLower(s.CWD), word)
+			})
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "cwd":
+			dir := config.ExpandHome(val)
+			if strings.ContainsAny(dir, "*?[") {
+				if _, err := filepath.Match(dir, ""); err != nil {
+					return Filter{}, fmt.Errorf("filter %s: %w", term, err)
+				}
+				f.terms = append(f.terms, func(s domain.Session) bool {
+					ok, _ := filepath.Match(dir, s.CWD)
+					return ok
+				})
+			} else {
+				f.terms = append(f.terms, func(s domain.Session) bool {
+					return s.CWD != "" && config.WithinDir(s.CWD, dir)
+				})
+			}
+		case "name":
+			text := strings.ToLower(val)
+			f.terms = append(f.terms, func(s domain.Session) bool {
+				return strings.Contains(strings.ToLower(s.DisplayName()), text)
+			})
+		case "model":
+			f.terms = append(f.terms, func(s domain.Session) bool {
+				return strings.EqualFold(s.Model, val)
+			})
+		case "id":
+			f.terms = append(f.terms, func(s domain.Session) bool {
+				return strings.HasPrefix(s.ID, val)
+			})
+		case "since":
+			t, err := parseWhen(val, now, false)
+			if err != nil {
+				return Filter{}, fmt.Errorf("filter %s: %w", term, err)
+			}
+			f.terms = append(f.terms, func(s domain.Session) bool { return !s.UpdatedAt.Before(t) })
+		case "until":
+			t, err := parseWhen(val, now, true)
+			if err != nil {
+				return Filter{}, fmt.Errorf("filter %s: %w", term, err)
+			}
+			f.terms = append(f.terms, func(s domain.Session) bool { return s.UpdatedAt.Before(t) })
+		default:
+			return Filter{}, fmt.Errorf("filter %s: unknown key %q (want cwd, name, model, id, since or until)", term, key)
+		}
+	}
+	return f, nil
+}
+
+// Match reports whether s matches every term.
+func (f Filter) Match(s domain.Session) bool {
+	for _, t := range f.terms {
+		if !t(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseWhen parses a date, taken as the start of that day (the end with
+// endOfDay), or a duration before now such as 90m, 12h or 7d.
+func parseWhen(s string, now time.Time, endOfDay bool) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("want a date (2006-01-02) or a duration such as 12h or 7d")
+}
@@ -0,0 +1,95 @@
+package exporter
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// md renders message content. Raw HTML in messages is left out, since
+// goldmark only passes it through when asked to.
+var md = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+var page = template.Must(template.New("page").Funcs(template.FuncMap{
+	"markdown": func(s string) template.HTML {
+		var buf bytes.Buffer
+		if err := md.Convert([]byte(s), &buf); err != nil {
+			return template.HTML(template.HTMLEscapeString(s))
+		}
+		return template.HTML(buf.String())
+	},
+	"stamp":  stamp,
+	"status": statusIcon,
+	"pretty": prettyArgs,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Session.DisplayName}} — Copilot Session</title>
+<style>
+body { font: 15px/1.5 system-ui, sans-serif; max-width: 52rem; margin: 2rem auto; padding: 0 1rem; color: #1f2328; background: #fff; }
+header { border-bottom: 1px solid #d0d7de; margin-bottom: 1.5rem; }
+dl { display: grid; grid-template-columns: max-content 1fr; gap: .2rem 1rem; }
+dt { font-weight: 600; }
+dd { margin: 0; }
+.msg { margin: 1rem 0; padding: .5rem 1rem; border-radius: 8px; }
+.user { background: #ddf4ff; }
+.assistant { background: #f6f8fa; }
+.system { color: #656d76; font-style: italic; }
+.who { font-weight: 600; }
+time { color: #656d76; font-size: .85em; margin-left: .5rem; }
+pre { background: #eaeef2; padding: .6rem; border-radius: 6px; overflow-x: auto; white-space: pre-wrap; }
+details { margin: .5rem 0; border: 1px solid #d0d7de; border-radius: 6px; padding: .3rem .6rem; background: #fff; }
+summary { cursor: pointer; font-family: ui-monospace, monospace; }
+.failed summary { color: #cf222e; }
+@media (prefers-color-scheme: dark) {
+  body { color: #e6edf3; background: #0d1117; }
+  .user { background: #0c2d6b; }
+  .assistant { background: #161b22; }
+  pre { background: #21262d; }
+  details { background: #0d1117; border-color: #30363d; }
+}
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Session.DisplayName}}</h1>
+<dl>
+<dt>Session ID</dt><dd><code>{{.Session.ID}}</code></dd>
+{{with .Session.CWD}}<dt>CWD</dt><dd><code>{{.}}</code></dd>{{end}}
+{{with .Session.Model}}<dt>Model</dt><dd>{{.}}</dd>{{end}}
+{{with stamp .Session.CreatedAt}}<dt>Created</dt><dd>{{.}}</dd>{{end}}
+{{with stamp .Session.UpdatedAt}}<dt>Updated</dt><dd>{{.}}</dd>{{end}}
+</dl>
+</header>
+{{range .Messages}}
+{{- if eq .Role "system"}}
+<p class="msg system">ℹ {{.Content}}{{with stamp .Timestamp}}<time>{{.}}</time>{{end}}</p>
+{{- else}}
+<section class="msg {{.Role}}">
+<div class="who">{{if eq .Role "user"}}🧑 You{{else}}🤖 Copilot{{end}}{{with stamp .Timestamp}}<time>{{.}}</time>{{end}}</div>
+{{if .Content}}{{markdown .Content}}{{end}}
+{{- range .ToolCalls}}
+<details{{if eq .Status "failed"}} class="failed"{{end}}>
+<summary>🔧 {{.Name}} {{status .Status}}{{with stamp .StartedAt}}<time>{{.}}</time>{{end}}</summary>
+{{with .ID}}<p>Call ID: <code>{{.}}</code></p>{{end}}
+{{with .FilePath}}<p>File: <code>{{.}}</code></p>{{end}}
+{{with .Args}}<p>Arguments:</p><pre>{{pretty .}}</pre>{{end}}
+{{with .Patch}}<pre>{{.}}</pre>{{end}}
+{{with .Summary}}<p>Result:</p><pre>{{.}}</pre>{{end}}
+</details>
+{{- end}}
+</section>
+{{- end}}
+{{end}}
+</body>
+</html>
+`))
+
+func writeHTML(w io.Writer, c Conversation) error {
+	return page.Execute(w, c)
+}
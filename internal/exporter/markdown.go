@@ -0,0 +1,86 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+func writeMarkdown(out io.Writer, c Conversation) error {
+	w := bufio.NewWriter(out)
+	s := c.Session
+	fmt.Fprintf(w, "# Copilot Session: %s\n\n", s.DisplayName())
+	fmt.Fprintf(w, "- **Session ID**: `%s`\n", s.ID)
+	if s.CWD != "" {
+		fmt.Fprintf(w, "- **CWD**: `%s`\n", s.CWD)
+	}
+	if s.Model != "" {
+		fmt.Fprintf(w, "- **Model**: %s\n", s.Model)
+	}
+	if ts := stamp(s.CreatedAt); ts != "" {
+		fmt.Fprintf(w, "- **Created**: %s\n", ts)
+	}
+	if ts := stamp(s.UpdatedAt); ts != "" {
+		fmt.Fprintf(w, "- **Updated**: %s\n", ts)
+	}
+	w.WriteString("\n---\n\n")
+
+	for _, msg := range c.Messages {
+		at := ""
+		if ts := stamp(msg.Timestamp); ts != "" {
+			at = " (" + ts + ")"
+		}
+		switch msg.Role {
+		case domain.RoleUser:
+			fmt.Fprintf(w, "### 🧑 You%s\n\n%s\n\n", at, msg.Content)
+		case domain.RoleAssistant:
+			fmt.Fprintf(w, "### 🤖 Copilot%s\n\n", at)
+			if msg.Content != "" {
+				w.WriteString(msg.Content + "\n\n")
+			}
+			for _, tc := range msg.ToolCalls {
+				writeMarkdownTool(w, tc)
+			}
+		case domain.RoleSystem:
+			fmt.Fprintf(w, "*ℹ %s*%s\n\n", msg.Content, at)
+		}
+	}
+	return w.Flush()
+}
+
+func writeMarkdownTool(w *bufio.Writer, tc domain.ToolCall) {
+	fmt.Fprintf(w, "#### 🔧 %s %s\n\n", tc.Name, statusIcon(tc.Status))
+	if tc.ID != "" {
+		fmt.Fprintf(w, "- **Call ID**: `%s`\n", tc.ID)
+	}
+	if ts := stamp(tc.StartedAt); ts != "" {
+		fmt.Fprintf(w, "- **Started**: %s\n", ts)
+	}
+	if tc.FilePath != "" {
+		fmt.Fprintf(w, "- **File**: `%s`\n", tc.FilePath)
+	}
+	w.WriteString("\n")
+	if tc.Args != "" {
+		w.WriteString("Arguments:\n\n")
+		codeBlock(w, "json", prettyArgs(tc.Args))
+	}
+	if tc.Patch != "" {
+		codeBlock(w, "diff", tc.Patch)
+	}
+	if tc.Summary != "" {
+		w.WriteString("Result:\n\n")
+		codeBlock(w, "", tc.Summary)
+	}
+}
+
+// codeBlock fences text with more backticks than it contains in a row.
+func codeBlock(w *bufio.Writer, lang, text string) {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(w, "%s%s\n%s\n%s\n\n", fence, lang, strings.TrimRight(text, "\n"), fence)
+}
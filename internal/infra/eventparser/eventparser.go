@@ -0,0 +1,115 @@
+// Package eventparser reads the events.jsonl log Copilot CLI keeps for each
+// session. The log is append-only, so a Parser remembers how far it has read
+// and returns only new events on the next call.
+package eventparser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	sdk "github.com/github/copilot-sdk/go"
+)
+
+// Parser reads a session's events.jsonl incrementally.
+type Parser struct {
+	path   string
+	offset int64
+	types  map[sdk.SessionEventType]bool // only these, when set
+}
+
+// New creates a parser that starts at the beginning of the file.
+func New(path string) *Parser {
+	return &Parser{path: path}
+}
+
+// NewFiltered creates a parser that returns only events of the given types.
+// Other lines are skipped after reading just their type, which is far
+// cheaper than decoding them.
+func NewFiltered(path string, types ...sdk.SessionEventType) *Parser {
+	p := &Parser{path: path, types: make(map[sdk.SessionEventType]bool, len(types))}
+	for _, t := range types {
+		p.types[t] = true
+	}
+	return p
+}
+
+// ParseFile reads every event in the file.
+func ParseFile(path string) ([]sdk.SessionEvent, error) {
+	return New(path).Next()
+}
+
+// SkipToEnd moves past the events already in the file, so Next returns only
+// events appended from now on.
+func (p *Parser) SkipToEnd() error {
+	info, err := os.Stat(p.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	p.offset = info.Size()
+	return nil
+}
+
+// Next returns the events appended since the last call. A trailing line
+// without a newline is still being written and is left for the next call;
+// lines that are not valid events are skipped. A missing file has no events.
+func (p *Parser) Next() ([]sdk.SessionEvent, error) {
+	f, err := os.Open(p.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open events: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("open events: %w", err)
+	}
+	if info.Size() < p.offset {
+		// Truncated or replaced: start over
+		p.offset = 0
+	}
+	if _, err := f.Seek(p.offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("read events: %w", err)
+	}
+
+	var events []sdk.SessionEvent
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return events, fmt.Errorf("read events: %w", err)
+		}
+		p.offset += int64(len(line))
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || !p.wants(line) {
+			continue
+		}
+		if ev, err := sdk.UnmarshalSessionEvent(line); err == nil && ev.Type != "" {
+			events = append(events, ev)
+		}
+	}
+}
+
+// wants reports whether a line holds an event of a type the parser returns.
+func (p *Parser) wants(line []byte) bool {
+	if p.types == nil {
+		return true
+	}
+	var head struct {
+		Type sdk.SessionEventType `json:"type"`
+	}
+	return json.Unmarshal(line, &head) == nil && p.types[head.Type]
+}
@@ -0,0 +1,74 @@
+package eventparser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/github/copilot-sdk/go"
+)
+
+func TestNextReadsOnlyCompleteNewLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	write := func(s string) {
+		t.Helper()
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := New(path)
+	if events, err := p.Next(); err != nil || len(events) != 0 {
+		t.Fatalf("missing file: %v, %v", events, err)
+	}
+
+	write(`{"type":"user.message","id":"1","timestamp":"2025-01-01T00:00:00Z","data":{"content":"hi"}}` + "\n")
+	write("not json\n")
+	write(`{"type":"assistant.message","id":"2","timestamp":"2025-01-01T00:00:01Z","data":{"content":"hel`)
+
+	events, err := p.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Type != sdk.UserMessage {
+		t.Fatalf("events = %+v, want the user message only", events)
+	}
+
+	write(`lo"}}` + "\n")
+	events, err = p.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Type != sdk.AssistantMessage || *events[0].Data.Content != "hello" {
+		t.Fatalf("events = %+v, want the completed assistant message", events)
+	}
+
+	all, err := ParseFile(path)
+	if err != nil || len(all) != 2 {
+		t.Errorf("ParseFile = %d events, %v", len(all), err)
+	}
+}
+
+func TestNewFiltered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	log := `{"type":"session.start","id":"1","timestamp":"2025-01-01T00:00:00Z","data":{"selectedModel":"gpt-5"}}
+{"type":"user.message","id":"2","timestamp":"2025-01-01T00:00:01Z","data":{"content":"\"type\":\"session.model_change\""}}
+{"type":"session.model_change","id":"3","timestamp":"2025-01-01T00:00:02Z","data":{"newModel":"o3"}}
+`
+	if err := os.WriteFile(path, []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := NewFiltered(path, sdk.SessionStart, sdk.SessionModelChange)
+	events, err := p.Next()
+	if err != nil || len(events) != 2 || events[1].Type != sdk.SessionModelChange || *events[1].Data.NewModel != "o3" {
+		t.Fatalf("events = %+v, %v; want only the two model events", events, err)
+	}
+	if events, _ := p.Next(); len(events) != 0 {
+		t.Errorf("events = %+v, want nothing new", events)
+	}
+}
@@ -0,0 +1,216 @@
+// Package hookinstall writes the Copilot CLI hook configuration that runs
+// copilot-icq-hook, merging it with whatever hooks a project already has.
+package hookinstall
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/e-9/copilot-icq/internal/infra/hookserver"
+)
+
+// RelPath is where the hook file lives inside a project.
+var RelPath = filepath.Join(".github", "hooks", "copilot-icq.json")
+
+// binary is the hook companion the installed hooks run.
+const binary = "copilot-icq-hook"
+
+// timeoutSec is how long Copilot CLI lets the hook run.
+const timeoutSec = 10
+
+// Action is what happened to one event's hook.
+type Action string
+
+const (
+	Added     Action = "added"
+	Removed   Action = "removed"
+	Unchanged Action = "unchanged"
+)
+
+// Change is the outcome for one hook event.
+type Change struct {
+	Event  string
+	Action Action
+}
+
+// Result reports what Install or Uninstall changed, or would change.
+type Result struct {
+	Path    string
+	Changes []Change
+	// Content is the file as written; empty when the file was removed.
+	Content []byte
+	// Deleted is set when uninstalling left no other hooks and the file
+	// was removed.
+	Deleted bool
+}
+
+// Changed reports whether anything was added or removed.
+func (r Result) Changed() bool {
+	for _, c := range r.Changes {
+		if c.Action != Unchanged {
+			return true
+		}
+	}
+	return r.Deleted
+}
+
+// Install adds a copilot-icq-hook entry for every lifecycle event to the
+// project's hook file, keeping any other hooks in it. With dryRun nothing
+// is written.
+func Install(dir string, dryRun bool) (Result, error) {
+	return apply(dir, dryRun, func(f *hookFile, event string) Action {
+		for _, h := range f.hooks[event] {
+			if isOurs(h) {
+				return Unchanged
+			}
+		}
+		f.hooks[event] = append(f.hooks[event], entry(event))
+		return Added
+	})
+}
+
+// Uninstall removes the copilot-icq-hook entries, keeping any other hooks.
+// The file is deleted once no hooks are left in it. With dryRun nothing is
+// written.
+func Uninstall(dir string, dryRun bool) (Result, error) {
+	return apply(dir, dryRun, func(f *hookFile, event string) Action {
+		var kept []map[string]any
+		for _, h := range f.hooks[event] {
+			if !isOurs(h) {
+				kept = append(kept, h)
+			}
+		}
+		if len(kept) == len(f.hooks[event]) {
+			return Unchanged
+		}
+		if len(kept) == 0 {
+			delete(f.hooks, event)
+		} else {
+			f.hooks[event] = kept
+		}
+		return Removed
+	})
+}
+
+// hookFile is a parsed hook file. Fields other than "hooks" are kept as is.
+type hookFile struct {
+	other map[string]json.RawMessage
+	hooks map[string][]map[string]any
+}
+
+func apply(dir string, dryRun bool, change func(*hookFile, string) Action) (Result, error) {
+	if dir == "" {
+		dir = "."
+	}
+	if info, err := os.Stat(dir); err != nil {
+		return Result{}, fmt.Errorf("project directory: %w", err)
+	} else if !info.IsDir() {
+		return Result{}, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	res := Result{Path: filepath.Join(dir, RelPath)}
+	f, existed, err := load(res.Path)
+	if err != nil {
+		return res, err
+	}
+	for _, event := range hookserver.Events {
+		res.Changes = append(res.Changes, Change{Event: event, Action: change(f, event)})
+	}
+	if !res.Changed() {
+		return res, nil
+	}
+
+	if len(f.hooks) == 0 {
+		res.Deleted = existed
+		if !dryRun && existed {
+			if err := os.Remove(res.Path); err != nil {
+				return res, fmt.Errorf("remove hook file: %w", err)
+			}
+		}
+		return res, nil
+	}
+
+	res.Content, err = f.marshal()
+	if err != nil {
+		return res, err
+	}
+	if dryRun {
+		return res, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(res.Path), 0o755); err != nil {
+		return res, fmt.Errorf("write hook file: %w", err)
+	}
+	tmp := res.Path + ".tmp"
+	if err := os.WriteFile(tmp, res.Content, 0o644); err != nil {
+		return res, fmt.Errorf("write hook file: %w", err)
+	}
+	if err := os.Rename(tmp, res.Path); err != nil {
+		return res, fmt.Errorf("write hook file: %w", err)
+	}
+	return res, nil
+}
+
+// load reads a hook file; a missing one yields an empty file.
+func load(path string) (*hookFile, bool, error) {
+	f := &hookFile{
+		other: map[string]json.RawMessage{"version": json.RawMessage("1")},
+		hooks: make(map[string][]map[string]any),
+	}
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("read hook file: %w", err)
+	}
+
+	if err := json.Unmarshal(raw, &f.other); err != nil {
+		return nil, true, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if hooks, ok := f.other["hooks"]; ok {
+		if err := json.Unmarshal(hooks, &f.hooks); err != nil {
+			return nil, true, fmt.Errorf("parse %s: hooks: %w", path, err)
+		}
+		delete(f.other, "hooks")
+	}
+	if f.hooks == nil {
+		f.hooks = make(map[string][]map[string]any)
+	}
+	return f, true, nil
+}
+
+func (f *hookFile) marshal() ([]byte, error) {
+	out := make(map[string]any, len(f.other)+1)
+	for k, v := range f.other {
+		out[k] = v
+	}
+	out["hooks"] = f.hooks
+	raw, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode hook file: %w", err)
+	}
+	return append(raw, '\n'), nil
+}
+
+// entry is the hook that runs copilot-icq-hook for one event.
+func entry(event string) map[string]any {
+	return map[string]any{
+		"type":       "command",
+		"bash":       binary + " " + event,
+		"powershell": binary + ".exe " + event,
+		"timeoutSec": timeoutSec,
+	}
+}
+
+// isOurs reports whether a hook entry runs copilot-icq-hook.
+func isOurs(h map[string]any) bool {
+	for _, k := range []string{"bash", "powershell", "command"} {
+		if s, ok := h[k].(string); ok && strings.Contains(s, binary) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package hookinstall
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const existing = `{
+  "version": 1,
+  "note": "kept",
+  "hooks": {
+    "preToolUse": [
+      {"type": "command", "bash": "./scripts/audit.sh"}
+    ]
+  }
+}`
+
+func readHooks(t *testing.T, path string) map[string]any {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f map[string]any
+	if err := json.Unmarshal(raw, &f); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestInstallMergesAndUninstallRestores(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, RelPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Install(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range res.Changes {
+		if c.Action != Added {
+			t.Errorf("%s: %s, want added", c.Event, c.Action)
+		}
+	}
+
+	f := readHooks(t, path)
+	if f["note"] != "kept" {
+		t.Error("unknown fields should be kept")
+	}
+	pre := f["hooks"].(map[string]any)["preToolUse"].([]any)
+	if len(pre) != 2 || pre[0].(map[string]any)["bash"] != "./scripts/audit.sh" {
+		t.Errorf("preToolUse = %v, want the existing hook followed by ours", pre)
+	}
+
+	// Installing again changes nothing.
+	res, err = Install(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Changed() {
+		t.Errorf("second install changed %v", res.Changes)
+	}
+
+	res, err = Uninstall(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Deleted {
+		t.Error("the file still has the project's own hook")
+	}
+	hooks := readHooks(t, path)["hooks"].(map[string]any)
+	if len(hooks) != 1 || len(hooks["preToolUse"].([]any)) != 1 {
+		t.Errorf("hooks after uninstall = %v", hooks)
+	}
+}
+
+func TestDryRunAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, RelPath)
+
+	res, err := Install(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Changed() || len(res.Content) == 0 {
+		t.Error("dry run should report the changes and the content")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("dry run should not write the file")
+	}
+
+	if _, err := Install(dir, false); err != nil {
+		t.Fatal(err)
+	}
+	res, err = Uninstall(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Deleted {
+		t.Error("a file with only our hooks should be deleted")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("hook file should be gone")
+	}
+}
@@ -0,0 +1,256 @@
+// Package hookserver carries Copilot CLI hook events from copilot-icq-hook to
+// the TUI over a Unix socket, and the TUI's allow/deny decision back.
+//
+// Each hook invocation is one connection: the hook writes an Event as a JSON
+// line and, once the TUI has handled it, reads a Decision as a JSON line.
+package hookserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Hook event names, as Copilot CLI calls them in hook configuration.
+const (
+	SessionStart        = "sessionStart"
+	SessionEnd          = "sessionEnd"
+	PreToolUse          = "preToolUse"
+	PostToolUse         = "postToolUse"
+	UserPromptSubmitted = "userPromptSubmitted"
+	ErrorOccurred       = "errorOccurred"
+)
+
+// Events lists the hooks copilot-icq-hook understands.
+var Events = []string{SessionStart, SessionEnd, PreToolUse, PostToolUse, UserPromptSubmitted, ErrorOccurred}
+
+// Event is a hook fired by Copilot CLI in any session, including sessions
+// running in other terminals.
+type Event struct {
+	Name       string          `json:"event"`
+	SessionID  string          `json:"sessionId,omitempty"` // empty when the CLI does not report it
+	CWD        string          `json:"cwd,omitempty"`
+	Time       time.Time       `json:"time"`
+	ToolName   string          `json:"toolName,omitempty"`
+	ToolArgs   json.RawMessage `json:"toolArgs,omitempty"`   // pre/postToolUse arguments as JSON
+	ToolResult string          `json:"toolResult,omitempty"` // postToolUse result type, e.g. success
+	Prompt     string          `json:"prompt,omitempty"`     // userPromptSubmitted, or sessionStart's initial prompt
+	Source     string          `json:"source,omitempty"`     // sessionStart: new, resume or startup
+	Reason     string          `json:"reason,omitempty"`     // sessionEnd: why it ended
+	Error      string          `json:"error,omitempty"`      // errorOccurred message
+}
+
+// Decision is the TUI's answer to a hook. Only preToolUse acts on it.
+type Decision struct {
+	Deny   bool   `json:"deny,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// payload is what Copilot CLI writes to a hook's stdin. Fields that do not
+// apply to a hook are absent.
+type payload struct {
+	SessionID  string          `json:"sessionId"`
+	Timestamp  int64           `json:"timestamp"` // Unix milliseconds
+	CWD        string          `json:"cwd"`
+	ToolName   string          `json:"toolName"`
+	ToolArgs   json.RawMessage `json:"toolArgs"`
+	ToolResult *struct {
+		ResultType string `json:"resultType"`
+	} `json:"toolResult"`
+	Prompt        string `json:"prompt"`
+	InitialPrompt string `json:"initialPrompt"`
+	Source        string `json:"source"`
+	Reason        string `json:"reason"`
+	Error         *struct {
+		Name    string `json:"name"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ParsePayload turns a hook's stdin into an Event.
+func ParsePayload(name string, raw []byte) (Event, error) {
+	if !isEvent(name) {
+		return Event{}, fmt.Errorf("unknown hook event %q", name)
+	}
+	var p payload
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return Event{}, fmt.Errorf("parse %s payload: %w", name, err)
+		}
+	}
+
+	ev := Event{
+		Name:      name,
+		SessionID: p.SessionID,
+		CWD:       p.CWD,
+		Time:      time.Now(),
+		ToolName:  p.ToolName,
+		ToolArgs:  toolArgs(p.ToolArgs),
+		Prompt:    p.Prompt,
+		Source:    p.Source,
+		Reason:    p.Reason,
+	}
+	if p.Timestamp > 0 {
+		ev.Time = time.UnixMilli(p.Timestamp)
+	}
+	if ev.Prompt == "" {
+		ev.Prompt = p.InitialPrompt
+	}
+	if p.ToolResult != nil {
+		ev.ToolResult = p.ToolResult.ResultType
+	}
+	if p.Error != nil {
+		ev.Error = p.Error.Message
+		if ev.Error == "" {
+			ev.Error = p.Error.Name
+		}
+	}
+	return ev, nil
+}
+
+// toolArgs unwraps arguments the CLI sends as a string of JSON.
+func toolArgs(raw json.RawMessage) json.RawMessage {
+	var s string
+	if json.Unmarshal(raw, &s) != nil {
+		return raw
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	return raw
+}
+
+func isEvent(name string) bool {
+	for _, e := range Events {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SocketPath returns the socket the TUI listens on: $COPILOT_ICQ_SOCKET, or
+// ~/.copilot/copilot-icq.sock.
+func SocketPath() string {
+	if p := os.Getenv("COPILOT_ICQ_SOCKET"); p != "" {
+		return p
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".copilot", "copilot-icq.sock")
+}
+
+// Handler decides on a hook event. It runs while Copilot CLI waits, so it
+// must return promptly.
+type Handler func(Event) Decision
+
+// connTimeout bounds how long one hook connection may take.
+const connTimeout = 10 * time.Second
+
+// Server accepts hook events on a Unix socket.
+type Server struct {
+	path    string
+	handler Handler
+	ln      net.Listener
+	wg      sync.WaitGroup
+}
+
+// New creates a server for the socket at path.
+func New(path string, h Handler) *Server {
+	return &Server{path: path, handler: h}
+}
+
+// Listen creates the socket, replacing a stale one left by a crashed TUI.
+// It fails if another TUI is already listening.
+func (s *Server) Listen() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("hook socket: %w", err)
+	}
+	if conn, err := net.DialTimeout("unix", s.path, 200*time.Millisecond); err == nil {
+		conn.Close()
+		return fmt.Errorf("hook socket %s is in use by another copilot-icq", s.path)
+	}
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("hook socket: %w", err)
+	}
+
+	ln, err := net.Listen("unix", s.path)
+	if err != nil {
+		return fmt.Errorf("hook socket: %w", err)
+	}
+	if err := os.Chmod(s.path, 0o600); err != nil {
+		ln.Close()
+		return fmt.Errorf("hook socket: %w", err)
+	}
+	s.ln = ln
+	return nil
+}
+
+// Serve handles connections until Close is called.
+func (s *Server) Serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+// Close stops accepting events, waits for the ones in flight and removes
+// the socket.
+func (s *Server) Close() error {
+	if s.ln == nil {
+		return nil
+	}
+	err := s.ln.Close()
+	s.wg.Wait()
+	os.Remove(s.path)
+	return err
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(connTimeout))
+
+	var ev Event
+	if err := json.NewDecoder(conn).Decode(&ev); err != nil {
+		return
+	}
+	json.NewEncoder(conn).Encode(s.handler(ev))
+}
+
+// Send forwards an event to the TUI listening at path and returns its
+// decision. It fails if no TUI is listening.
+func Send(ctx context.Context, path string, ev Event) (Decision, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return Decision{}, fmt.Errorf("connect to copilot-icq: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(conn).Encode(ev); err != nil {
+		return Decision{}, fmt.Errorf("send hook event: %w", err)
+	}
+	var dec Decision
+	if err := json.NewDecoder(conn).Decode(&dec); err != nil {
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return Decision{}, fmt.Errorf("copilot-icq did not answer in time")
+		}
+		return Decision{}, fmt.Errorf("read hook decision: %w", err)
+	}
+	return dec, nil
+}
@@ -0,0 +1,84 @@
+package hookserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParsePayload(t *testing.T) {
+	raw := `{"timestamp":1704614600000,"cwd":"/repo","toolName":"bash","toolArgs":"{\"command\":\"rm -rf /\"}"}`
+	ev, err := ParsePayload(PreToolUse, []byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.CWD != "/repo" || ev.ToolName != "bash" {
+		t.Errorf("got %+v", ev)
+	}
+	if got := string(ev.ToolArgs); got != `{"command":"rm -rf /"}` {
+		t.Errorf("toolArgs = %s, want the unwrapped JSON", got)
+	}
+	if !ev.Time.Equal(time.UnixMilli(1704614600000)) {
+		t.Errorf("time = %v", ev.Time)
+	}
+
+	ev, err = ParsePayload(ErrorOccurred, []byte(`{"error":{"name":"Boom","message":"went wrong"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Error != "went wrong" {
+		t.Errorf("error = %q", ev.Error)
+	}
+
+	if _, err := ParsePayload("agentStop", nil); err == nil {
+		t.Error("unknown events should be rejected")
+	}
+}
+
+func TestServerRoundTrip(t *testing.T) {
+	// Unix socket paths are short; t.TempDir can exceed the limit on macOS.
+	dir, err := os.MkdirTemp("", "icq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "s.sock")
+
+	got := make(chan Event, 1)
+	srv := New(path, func(ev Event) Decision {
+		got <- ev
+		return Decision{Deny: ev.ToolName == "bash", Reason: "no shell"}
+	})
+	if err := srv.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve()
+	defer srv.Close()
+
+	if err := New(path, nil).Listen(); err == nil {
+		t.Error("a second server on a live socket should fail")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	dec, err := Send(ctx, path, Event{Name: PreToolUse, ToolName: "bash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dec.Deny || dec.Reason != "no shell" {
+		t.Errorf("decision = %+v", dec)
+	}
+	if ev := <-got; ev.Name != PreToolUse {
+		t.Errorf("server got %+v", ev)
+	}
+
+	srv.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Close should remove the socket")
+	}
+	if _, err := Send(ctx, path, Event{Name: SessionEnd}); err == nil {
+		t.Error("Send should fail when nothing is listening")
+	}
+}
@@ -0,0 +1,64 @@
+package notifier
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/e-9/copilot-icq/internal/config"
+)
+
+// Rate limits: one notification per session every 30 seconds, and at most
+// one every 5 seconds overall.
+const (
+	PerSession = 30 * time.Second
+	Global     = 5 * time.Second
+)
+
+// FromConfig builds the notifiers enabled in cfg, or returns nil if none
+// are. Desktop notifications go to w when the terminal fallback is used.
+// $COPILOT_ICQ_NTFY_TOPIC sets the push topic and enables push.
+func FromConfig(cfg *config.AppConfig, w io.Writer) (Notifier, error) {
+	nc := cfg.Notifications
+	if topic := os.Getenv("COPILOT_ICQ_NTFY_TOPIC"); topic != "" {
+		nc.Push, nc.Topic = true, topic
+	}
+
+	var out Multi
+	if nc.OS {
+		out = append(out, NewLimiter(Default(w), PerSession, Global))
+	}
+	if nc.Push {
+		if nc.Topic == "" {
+			return nil, fmt.Errorf("notifications.push needs a topic")
+		}
+		var push Notifier = NewLimiter(Ntfy{
+			Server: nc.Server,
+			Topic:  nc.Topic,
+			Token:  nc.Token,
+			Click:  nc.Click,
+		}, PerSession, Global)
+		if len(nc.Events) > 0 {
+			kinds := make(map[Kind]bool, len(nc.Events))
+			for _, name := range nc.Events {
+				k, err := ParseKind(name)
+				if err != nil {
+					return nil, fmt.Errorf("notifications.events: %w", err)
+				}
+				kinds[k] = true
+			}
+			// Filter first, so events that are not pushed use up no rate
+			push = Filter{Kinds: kinds, Next: push}
+		}
+		out = append(out, push)
+	}
+
+	switch len(out) {
+	case 0:
+		return nil, nil
+	case 1:
+		return out[0], nil
+	}
+	return out, nil
+}
@@ -0,0 +1,53 @@
+package notifier
+
+import (
+	"strings"
+
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+)
+
+// For returns the notification a backend event calls for, reporting false
+// for events that need none. reply is the turn's last reply, announced
+// when the session goes idle. The caller fills in the title and decides
+// whether the session wants notifications at all.
+func For(evt copilot.Event, reply string) (Notification, bool) {
+	n := Notification{SessionID: evt.SessionID}
+	switch {
+	case evt.Type == copilot.EventPermission && evt.Permission != nil:
+		// A request the policy denied needs no answer
+		if evt.Permission.Denied {
+			return n, false
+		}
+		n.Kind, n.Body = Permission, "Wants to run "+evt.Permission.ToolName
+	case evt.Type == copilot.EventUserInput && evt.UserInput != nil:
+		n.Kind, n.Body = Question, evt.UserInput.Question
+	case evt.Type == copilot.EventSession && evt.SessionEvent != nil:
+		e := evt.SessionEvent
+		switch {
+		case e.Type == sdk.SessionIdle && reply != "":
+			n.Kind, n.Body = Reply, reply
+		case e.Type == sdk.SessionError:
+			n.Kind, n.Body = Failure, "unknown error"
+			if e.Data.Message != nil {
+				n.Body = *e.Data.Message
+			}
+		default:
+			return n, false
+		}
+	default:
+		return n, false
+	}
+	n.Body = snippet(n.Body)
+	return n, true
+}
+
+// snippet shortens text to one line that fits in a notification.
+func snippet(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > 120 {
+		s = string(r[:119]) + "…"
+	}
+	return s
+}
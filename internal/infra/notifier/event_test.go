@@ -0,0 +1,47 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+)
+
+func TestFor(t *testing.T) {
+	session := func(typ sdk.SessionEventType, msg *string) copilot.Event {
+		return copilot.Event{Type: copilot.EventSession, SessionID: "s", SessionEvent: &sdk.SessionEvent{Type: typ, Data: sdk.Data{Message: msg}}}
+	}
+	quota := "over quota"
+	long := strings.Repeat("word ", 40)
+
+	tests := []struct {
+		name  string
+		evt   copilot.Event
+		reply string
+		kind  Kind
+		body  string
+		ok    bool
+	}{
+		{"permission", copilot.Event{Type: copilot.EventPermission, SessionID: "s", Permission: &copilot.PermissionEvent{ToolName: "bash"}}, "", Permission, "Wants to run bash", true},
+		{"denied by policy", copilot.Event{Type: copilot.EventPermission, SessionID: "s", Permission: &copilot.PermissionEvent{ToolName: "rm", Denied: true}}, "", 0, "", false},
+		{"question", copilot.Event{Type: copilot.EventUserInput, SessionID: "s", UserInput: &copilot.UserInputEvent{Question: "Which\n  branch?"}}, "", Question, "Which branch?", true},
+		{"idle with a reply", session(sdk.SessionIdle, nil), "All done", Reply, "All done", true},
+		{"idle without a reply", session(sdk.SessionIdle, nil), "", 0, "", false},
+		{"error", session(sdk.SessionError, &quota), "", Failure, "over quota", true},
+		{"error without a message", session(sdk.SessionError, nil), "", Failure, "unknown error", true},
+		{"other events", session(sdk.AssistantMessage, nil), "ignored", 0, "", false},
+		{"long replies are cut", session(sdk.SessionIdle, nil), long, Reply, strings.TrimSpace(long)[:119] + "…", true},
+	}
+	for _, tt := range tests {
+		n, ok := For(tt.evt, tt.reply)
+		if ok != tt.ok {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.ok)
+			continue
+		}
+		if ok && (n.SessionID != "s" || n.Kind != tt.kind || n.Body != tt.body) {
+			t.Errorf("%s: got %+v, want %v %q", tt.name, n, tt.kind, tt.body)
+		}
+	}
+}
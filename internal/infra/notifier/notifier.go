@@ -0,0 +1,194 @@
+// Package notifier tells the user about sessions that need attention while
+// they are looking at something else: a reply, a permission request, a
+// question or an error.
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is why a session wants attention.
+type Kind int
+
+const (
+	Reply Kind = iota
+	Permission
+	Question
+	Failure
+)
+
+// kindNames are the names used for kinds in the config.
+var kindNames = map[Kind]string{
+	Reply:      "reply",
+	Permission: "permission",
+	Question:   "question",
+	Failure:    "error",
+}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// ParseKind returns the kind with the given config name.
+func ParseKind(name string) (Kind, error) {
+	for k, n := range kindNames {
+		if strings.EqualFold(n, name) {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown notification event %q (want reply, permission, question or error)", name)
+}
+
+// Notification is one message to the user about a session.
+type Notification struct {
+	SessionID string
+	Kind      Kind
+	Title     string // session name
+	Body      string
+}
+
+// Notifier delivers notifications. Notify may block, so the app calls it
+// from a tea.Cmd.
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// Default returns the best desktop notifier available: notify-send on
+// Linux, else the terminal bell and OSC 9 written to w.
+func Default(w io.Writer) Notifier {
+	if runtime.GOOS == "linux" {
+		if path, err := exec.LookPath("notify-send"); err == nil {
+			return NotifySend{Path: path}
+		}
+	}
+	return Terminal{W: w}
+}
+
+// NotifySend shows notifications through notify-send, which talks to the
+// desktop's notification daemon over D-Bus.
+type NotifySend struct {
+	Path string
+}
+
+func (s NotifySend) Notify(n Notification) error {
+	urgency := "normal"
+	if n.Kind == Permission || n.Kind == Question {
+		// The agent is blocked until the user answers
+		urgency = "critical"
+	}
+	out, err := exec.Command(s.Path, "--app-name=copilot-icq", "--urgency="+urgency, n.Title, n.Body).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("notify-send: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Terminal rings the terminal bell and sends an OSC 9 notification, which
+// terminals such as iTerm2, WezTerm and Windows Terminal turn into desktop
+// notifications. Other terminals only beep.
+type Terminal struct {
+	W io.Writer
+}
+
+func (t Terminal) Notify(n Notification) error {
+	text := n.Title
+	if n.Body != "" {
+		text += ": " + n.Body
+	}
+	_, err := fmt.Fprintf(t.W, "\x1b]9;%s\x07\a", sanitize(text))
+	return err
+}
+
+// sanitize keeps control characters from ending the escape sequence early.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// Multi delivers every notification to all its notifiers.
+type Multi []Notifier
+
+func (m Multi) Notify(n Notification) error {
+	var errs []error
+	for _, x := range m {
+		if err := x.Notify(n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Filter passes on only notifications of the given kinds.
+type Filter struct {
+	Kinds map[Kind]bool
+	Next  Notifier
+}
+
+func (f Filter) Notify(n Notification) error {
+	if !f.Kinds[n.Kind] {
+		return nil
+	}
+	return f.Next.Notify(n)
+}
+
+// Limiter drops notifications that come too fast, so a chatty session or a
+// burst across sessions does not flood the desktop.
+type Limiter struct {
+	next       Notifier
+	perSession time.Duration // minimum gap between notifications for one session
+	global     time.Duration // minimum gap between any two notifications
+	now        func() time.Time
+
+	mu      sync.Mutex
+	last    map[string]time.Time
+	lastAny time.Time
+}
+
+// NewLimiter passes notifications on to next at the given rates.
+func NewLimiter(next Notifier, perSession, global time.Duration) *Limiter {
+	return &Limiter{
+		next:       next,
+		perSession: perSession,
+		global:     global,
+		now:        time.Now,
+		last:       make(map[string]time.Time),
+	}
+}
+
+// Notify passes n on unless it comes too soon; dropped notifications are
+// not an error. Permission requests and questions are never limited, since
+// the agent waits on them.
+func (l *Limiter) Notify(n Notification) error {
+	if !l.allow(n) {
+		return nil
+	}
+	return l.next.Notify(n)
+}
+
+func (l *Limiter) allow(n Notification) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if n.Kind != Permission && n.Kind != Question {
+		if t, ok := l.last[n.SessionID]; ok && now.Sub(t) < l.perSession {
+			return false
+		}
+		if now.Sub(l.lastAny) < l.global {
+			return false
+		}
+	}
+	l.last[n.SessionID] = now
+	l.lastAny = now
+	return true
+}
@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type recorder struct{ got []Notification }
+
+func (r *recorder) Notify(n Notification) error {
+	r.got = append(r.got, n)
+	return nil
+}
+
+func TestLimiter(t *testing.T) {
+	rec := &recorder{}
+	l := NewLimiter(rec, 30*time.Second, 5*time.Second)
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	l.Notify(Notification{SessionID: "a", Kind: Reply})
+	l.Notify(Notification{SessionID: "a", Kind: Reply}) // same session, too soon
+	l.Notify(Notification{SessionID: "b", Kind: Reply}) // any session, too soon
+	if len(rec.got) != 1 {
+		t.Fatalf("delivered %d, want 1", len(rec.got))
+	}
+
+	// Blocking requests skip both limits, even right after a reply
+	l.Notify(Notification{SessionID: "b", Kind: Permission})
+	l.Notify(Notification{SessionID: "a", Kind: Permission})
+	l.Notify(Notification{SessionID: "a", Kind: Question})
+	if len(rec.got) != 4 {
+		t.Fatalf("delivered %d, want every blocking request too", len(rec.got))
+	}
+
+	now = now.Add(10 * time.Second)
+	l.Notify(Notification{SessionID: "c", Kind: Failure})
+	l.Notify(Notification{SessionID: "a", Kind: Reply})
+	if len(rec.got) != 5 || rec.got[4].SessionID != "c" {
+		t.Errorf("delivered %+v", rec.got)
+	}
+
+	now = now.Add(30 * time.Second)
+	l.Notify(Notification{SessionID: "a", Kind: Reply})
+	if len(rec.got) != 6 {
+		t.Errorf("delivered %d, want a once its limit passed", len(rec.got))
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	Terminal{W: &buf}.Notify(Notification{Title: "Fix tests", Body: "done\x07\nok"})
+	if got, want := buf.String(), "\x1b]9;Fix tests: done  ok\x07\a"; got != want {
+		t.Errorf("wrote %q, want %q", got, want)
+	}
+}
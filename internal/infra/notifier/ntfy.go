@@ -0,0 +1,90 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultServer is the public ntfy server.
+const DefaultServer = "https://ntfy.sh"
+
+// Ntfy pushes notifications to a topic on an ntfy-compatible server, from
+// where the ntfy app delivers them to phones and browsers.
+type Ntfy struct {
+	Server string // server URL; empty uses DefaultServer
+	Topic  string
+	Token  string // optional access token, sent as a bearer token
+	Click  string // optional URL opened when the notification is tapped
+	Client *http.Client
+}
+
+// ntfyTimeout bounds one push; the app does not wait for it.
+const ntfyTimeout = 10 * time.Second
+
+func (n Ntfy) Notify(x Notification) error {
+	server := n.Server
+	if server == "" {
+		server = DefaultServer
+	}
+	url := strings.TrimRight(server, "/") + "/" + n.Topic
+
+	ctx, cancel := context.WithTimeout(context.Background(), ntfyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(x.Body))
+	if err != nil {
+		return fmt.Errorf("ntfy: %w", err)
+	}
+	// Headers must be single-line; titles come from session summaries
+	req.Header.Set("Title", sanitize(x.Title))
+	req.Header.Set("Priority", priority(x.Kind))
+	req.Header.Set("Tags", tags(x.Kind))
+	if n.Click != "" {
+		req.Header.Set("Click", n.Click)
+	}
+	if n.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Token)
+	}
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ntfy: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("ntfy: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// priority maps a kind to an ntfy priority: 3 is default, 4 is high.
+func priority(k Kind) string {
+	switch k {
+	case Permission, Question, Failure:
+		return "4"
+	default:
+		return "3"
+	}
+}
+
+// tags maps a kind to ntfy tags, which the apps show as emoji.
+func tags(k Kind) string {
+	switch k {
+	case Permission:
+		return "zap"
+	case Question:
+		return "question"
+	case Failure:
+		return "warning"
+	default:
+		return "speech_balloon"
+	}
+}
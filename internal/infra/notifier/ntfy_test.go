@@ -0,0 +1,83 @@
+package notifier
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/e-9/copilot-icq/internal/config"
+)
+
+func TestNtfy(t *testing.T) {
+	var got *http.Request
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		got, body = r, string(raw)
+	}))
+	defer srv.Close()
+
+	n := Ntfy{Server: srv.URL + "/", Topic: "icq", Token: "tk", Click: "https://example.com"}
+	if err := n.Notify(Notification{Kind: Permission, Title: "Fix\ntests", Body: "Wants to run bash"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.URL.Path != "/icq" || body != "Wants to run bash" {
+		t.Errorf("posted %q to %s", body, got.URL.Path)
+	}
+	for h, want := range map[string]string{
+		"Title":         "Fix tests",
+		"Priority":      "4",
+		"Tags":          "zap",
+		"Click":         "https://example.com",
+		"Authorization": "Bearer tk",
+	} {
+		if v := got.Header.Get(h); v != want {
+			t.Errorf("%s = %q, want %q", h, v, want)
+		}
+	}
+
+	denied := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusForbidden)
+	}))
+	defer denied.Close()
+	if err := (Ntfy{Server: denied.URL, Topic: "icq"}).Notify(Notification{}); err == nil {
+		t.Error("a rejected push should be an error")
+	}
+}
+
+func TestFromConfigFiltersPushEvents(t *testing.T) {
+	var pushed []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pushed = append(pushed, r.Header.Get("Tags"))
+	}))
+	defer srv.Close()
+	t.Setenv("COPILOT_ICQ_NTFY_TOPIC", "")
+
+	cfg := config.DefaultAppConfig()
+	if n, err := FromConfig(cfg, io.Discard); n != nil || err != nil {
+		t.Fatalf("FromConfig = %v, %v; want nothing enabled", n, err)
+	}
+
+	cfg.Notifications.Push = true
+	if _, err := FromConfig(cfg, io.Discard); err == nil {
+		t.Error("push without a topic should be an error")
+	}
+	cfg.Notifications.Topic = "icq"
+	cfg.Notifications.Events = []string{"bogus"}
+	if _, err := FromConfig(cfg, io.Discard); err == nil {
+		t.Error("an unknown event should be an error")
+	}
+
+	cfg.Notifications.Server = srv.URL
+	cfg.Notifications.Events = []string{"permission", "error"}
+	n, err := FromConfig(cfg, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Notify(Notification{SessionID: "a", Kind: Reply})
+	n.Notify(Notification{SessionID: "a", Kind: Permission})
+	if len(pushed) != 1 || pushed[0] != "zap" {
+		t.Errorf("pushed %v, want only the permission request", pushed)
+	}
+}
@@ -0,0 +1,67 @@
+// Package sessionrepo discovers Copilot CLI sessions on disk. Every session
+// has a directory under ~/.copilot/session-state named after its ID, holding
+// workspace.yaml (metadata) and events.jsonl (the event log).
+package sessionrepo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+// File names inside a session directory.
+const (
+	WorkspaceFile = "workspace.yaml"
+	EventsFile    = "events.jsonl"
+)
+
+// List returns the sessions under base, most recently updated first.
+// Directories without a readable workspace.yaml are skipped.
+func List(base string) ([]domain.Session, error) {
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	var sessions []domain.Session
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		s, err := Load(base, e.Name())
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+	return sessions, nil
+}
+
+// Load reads one session's workspace.yaml. The directory name is the ID
+// when the file does not record one.
+func Load(base, id string) (domain.Session, error) {
+	raw, err := os.ReadFile(filepath.Join(base, id, WorkspaceFile))
+	if err != nil {
+		return domain.Session{}, fmt.Errorf("read session %s: %w", id, err)
+	}
+	var s domain.Session
+	if err := yaml.Unmarshal(raw, &s); err != nil {
+		return domain.Session{}, fmt.Errorf("parse session %s: %w", id, err)
+	}
+	if s.ID == "" {
+		s.ID = id
+	}
+	return s, nil
+}
+
+// EventsPath returns the path of a session's event log.
+func EventsPath(base, id string) string {
+	return filepath.Join(base, id, EventsFile)
+}
@@ -0,0 +1,45 @@
+package sessionrepo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeSession(t *testing.T, base, dir, workspace string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(base, dir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, dir, WorkspaceFile), []byte(workspace), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestList(t *testing.T) {
+	base := t.TempDir()
+	writeSession(t, base, "aaaa", "id: aaaa\ncwd: /repo/a\nsummary: Fix tests\nupdated_at: 2025-01-01T10:00:00Z\n")
+	writeSession(t, base, "bbbb", "cwd: /repo/b\nupdated_at: 2025-01-02T10:00:00Z\n")
+	writeSession(t, base, "broken", "id: [unterminated\n")
+	if err := os.MkdirAll(filepath.Join(base, "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := List(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2: %+v", len(sessions), sessions)
+	}
+	if sessions[0].ID != "bbbb" || sessions[0].CWD != "/repo/b" {
+		t.Errorf("first = %+v, want the most recent session with the directory as ID", sessions[0])
+	}
+	if sessions[1].Summary != "Fix tests" {
+		t.Errorf("second = %+v", sessions[1])
+	}
+
+	if _, err := List(filepath.Join(base, "missing")); err == nil {
+		t.Error("a missing base directory should be an error")
+	}
+}
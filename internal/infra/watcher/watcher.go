@@ -0,0 +1,109 @@
+// Package watcher polls the session-state directory for changes to session
+// files. Polling keeps it portable and needs no file descriptors per session;
+// Copilot CLI writes events a line at a time, so a short interval is enough.
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/e-9/copilot-icq/internal/infra/sessionrepo"
+)
+
+// Op is what happened to a file.
+type Op int
+
+const (
+	Created Op = iota
+	Modified
+	Removed
+)
+
+// Change is one file that changed between two scans.
+type Change struct {
+	SessionID string
+	File      string // sessionrepo.WorkspaceFile or sessionrepo.EventsFile
+	Op        Op
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watcher polls the session files under a base directory.
+type Watcher struct {
+	base     string
+	interval time.Duration
+	seen     map[string]fileState // path relative to base → last state
+	primed   bool
+}
+
+// New creates a watcher for the sessions under base.
+func New(base string, interval time.Duration) *Watcher {
+	return &Watcher{base: base, interval: interval, seen: make(map[string]fileState)}
+}
+
+// Scan compares the session files with the previous scan. The first scan
+// only records what exists and reports nothing.
+func (w *Watcher) Scan() []Change {
+	current := make(map[string]fileState, len(w.seen))
+	entries, _ := os.ReadDir(w.base)
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		for _, name := range []string{sessionrepo.WorkspaceFile, sessionrepo.EventsFile} {
+			rel := filepath.Join(e.Name(), name)
+			info, err := os.Stat(filepath.Join(w.base, rel))
+			if err != nil {
+				continue
+			}
+			current[rel] = fileState{size: info.Size(), modTime: info.ModTime()}
+		}
+	}
+
+	var changes []Change
+	if w.primed {
+		for rel, st := range current {
+			old, ok := w.seen[rel]
+			switch {
+			case !ok:
+				changes = append(changes, change(rel, Created))
+			case old != st:
+				changes = append(changes, change(rel, Modified))
+			}
+		}
+		for rel := range w.seen {
+			if _, ok := current[rel]; !ok {
+				changes = append(changes, change(rel, Removed))
+			}
+		}
+	}
+	w.seen = current
+	w.primed = true
+	return changes
+}
+
+func change(rel string, op Op) Change {
+	return Change{SessionID: filepath.Dir(rel), File: filepath.Base(rel), Op: op}
+}
+
+// Run scans every interval and calls fn with the changes until ctx is done.
+// Call Scan first to take the baseline at a point of your choosing.
+func (w *Watcher) Run(ctx context.Context, fn func([]Change)) {
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if changes := w.Scan(); len(changes) > 0 {
+				fn(changes)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/e-9/copilot-icq/internal/infra/sessionrepo"
+)
+
+func TestScan(t *testing.T) {
+	base := t.TempDir()
+	write := func(id, name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(base, id), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(base, id, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("s1", sessionrepo.WorkspaceFile, "id: s1\n")
+	w := New(base, 0)
+	if changes := w.Scan(); len(changes) != 0 {
+		t.Fatalf("first scan reported %v", changes)
+	}
+
+	write("s1", sessionrepo.EventsFile, "{}\n")
+	write("s2", sessionrepo.WorkspaceFile, "id: s2\n")
+	changes := w.Scan()
+	sort.Slice(changes, func(i, j int) bool { return changes[i].SessionID < changes[j].SessionID })
+	want := []Change{
+		{SessionID: "s1", File: sessionrepo.EventsFile, Op: Created},
+		{SessionID: "s2", File: sessionrepo.WorkspaceFile, Op: Created},
+	}
+	if len(changes) != len(want) || changes[0] != want[0] || changes[1] != want[1] {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+
+	write("s1", sessionrepo.EventsFile, "{}\n{}\n")
+	if err := os.RemoveAll(filepath.Join(base, "s2")); err != nil {
+		t.Fatal(err)
+	}
+	changes = w.Scan()
+	sort.Slice(changes, func(i, j int) bool { return changes[i].SessionID < changes[j].SessionID })
+	want = []Change{
+		{SessionID: "s1", File: sessionrepo.EventsFile, Op: Modified},
+		{SessionID: "s2", File: sessionrepo.WorkspaceFile, Op: Removed},
+	}
+	if len(changes) != len(want) || changes[0] != want[0] || changes[1] != want[1] {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+}
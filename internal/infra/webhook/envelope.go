@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+)
+
+// EnvelopeFor describes a backend event for the webhooks, reporting false
+// for events they do not receive. reply is the turn's last reply, sent with
+// session.idle. The caller fills in the session's name and directory.
+func EnvelopeFor(evt copilot.Event, reply string) (Envelope, bool) {
+	env := Envelope{SessionID: evt.SessionID}
+	switch {
+	case evt.Type == copilot.EventSession && evt.SessionEvent != nil:
+		e := evt.SessionEvent
+		switch e.Type {
+		case sdk.SessionIdle:
+			env.Event = SessionIdle
+			env.Data.Reply = reply
+		case sdk.SessionError:
+			env.Event = SessionError
+			if e.Data.Message != nil {
+				env.Data.Error = *e.Data.Message
+			}
+		case sdk.ToolExecutionComplete:
+			env.Event = ToolCompleted
+			if e.Data.ToolName != nil {
+				env.Data.ToolName = *e.Data.ToolName
+			}
+			env.Data.Success = e.Data.Success
+			if e.Data.Result != nil {
+				env.Data.Result = e.Data.Result.Content
+			}
+		default:
+			return env, false
+		}
+
+	case evt.Type == copilot.EventPermission && evt.Permission != nil:
+		p := evt.Permission
+		env.Event = PermissionRequested
+		env.Data.ToolName = p.ToolName
+		env.Data.ToolArgs = p.Args
+		env.Data.Kind = p.Kind
+		env.Data.Denied = p.Denied
+		env.Data.DenyReason = p.DenyReason
+
+	default:
+		return env, false
+	}
+	return env, true
+}
@@ -0,0 +1,305 @@
+// Package webhook delivers session events to HTTP endpoints, such as chat
+// bots and dashboards. Every delivery is a JSON Envelope, optionally signed
+// with HMAC-SHA256. Deliveries are queued per endpoint and retried with
+// backoff; when a queue is full, new events for it are dropped rather than
+// making the caller wait.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/e-9/copilot-icq/internal/config"
+)
+
+// Event names, as used in the envelope and in the config's event filters.
+const (
+	SessionIdle         = "session.idle"
+	SessionError        = "session.error"
+	PermissionRequested = "permission.requested"
+	ToolCompleted       = "tool.completed"
+)
+
+// Events lists every event a webhook can receive.
+var Events = []string{SessionIdle, SessionError, PermissionRequested, ToolCompleted}
+
+// Version is the envelope format version. Fields are only ever added
+// within a version.
+const Version = 1
+
+// Envelope is the body of every delivery.
+type Envelope struct {
+	Version     int       `json:"version"`
+	ID          string    `json:"id"` // unique per event; retries reuse it
+	Event       string    `json:"event"`
+	Time        time.Time `json:"time"`
+	SessionID   string    `json:"sessionId"`
+	SessionName string    `json:"sessionName,omitempty"`
+	CWD         string    `json:"cwd,omitempty"`
+	Data        Data      `json:"data"`
+}
+
+// Data holds the event-specific fields; those that do not apply are omitted.
+type Data struct {
+	Reply      string `json:"reply,omitempty"`      // session.idle: the turn's last reply
+	Error      string `json:"error,omitempty"`      // session.error
+	ToolName   string `json:"toolName,omitempty"`   // permission.requested, tool.completed
+	ToolArgs   string `json:"toolArgs,omitempty"`   // permission.requested
+	Kind       string `json:"kind,omitempty"`       // permission.requested: shell, write, read, url, mcp
+	Denied     bool   `json:"denied,omitempty"`     // permission.requested: denied by the security policy
+	DenyReason string `json:"denyReason,omitempty"` // permission.requested
+	Success    *bool  `json:"success,omitempty"`    // tool.completed
+	Result     string `json:"result,omitempty"`     // tool.completed
+}
+
+// Delivery headers.
+const (
+	HeaderEvent     = "X-Copilot-ICQ-Event"
+	HeaderDelivery  = "X-Copilot-ICQ-Delivery"
+	HeaderSignature = "X-Copilot-ICQ-Signature" // sha256=<hex HMAC of the body>
+)
+
+// Sign returns the signature header value for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewID returns a random delivery ID.
+func NewID() string {
+	var b [12]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Endpoint is one configured webhook.
+type Endpoint struct {
+	URL    string
+	Events map[string]bool // empty receives every event
+	Secret string
+}
+
+func (e Endpoint) wants(event string) bool {
+	return len(e.Events) == 0 || e.Events[event]
+}
+
+// Delivery tuning.
+const (
+	QueueSize   = 100
+	MaxAttempts = 4
+	BaseBackoff = time.Second
+	timeout     = 10 * time.Second
+)
+
+// Dispatcher delivers envelopes to its endpoints in the background.
+type Dispatcher struct {
+	client  *http.Client
+	backoff time.Duration
+	workers []*worker
+	wg      sync.WaitGroup
+	dropped atomic.Int64
+	closed  chan struct{}
+	once    sync.Once
+}
+
+type worker struct {
+	ep    Endpoint
+	queue chan job
+}
+
+// job is one envelope waiting to be delivered.
+type job struct {
+	event string
+	id    string
+	body  []byte
+}
+
+// New starts a dispatcher for the endpoints. Call Close to stop it.
+func New(endpoints []Endpoint, client *http.Client, backoff time.Duration) *Dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: timeout}
+	}
+	d := &Dispatcher{client: client, backoff: backoff, closed: make(chan struct{})}
+	for _, ep := range endpoints {
+		w := &worker{ep: ep, queue: make(chan job, QueueSize)}
+		d.workers = append(d.workers, w)
+		d.wg.Add(1)
+		go d.run(w)
+	}
+	return d
+}
+
+// FromConfig starts a dispatcher for the configured webhooks, or returns
+// nil if there are none.
+func FromConfig(cfg *config.AppConfig) (*Dispatcher, error) {
+	if len(cfg.Webhooks) == 0 {
+		return nil, nil
+	}
+	var eps []Endpoint
+	for i, wc := range cfg.Webhooks {
+		u, err := url.Parse(wc.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("webhooks[%d]: invalid url %q", i, wc.URL)
+		}
+		ep := Endpoint{URL: wc.URL, Secret: wc.Secret, Events: make(map[string]bool)}
+		for _, ev := range wc.Events {
+			if !known(ev) {
+				return nil, fmt.Errorf("webhooks[%d]: unknown event %q (want one of %v)", i, ev, Events)
+			}
+			ep.Events[ev] = true
+		}
+		eps = append(eps, ep)
+	}
+	return New(eps, nil, BaseBackoff), nil
+}
+
+func known(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish queues env for every endpoint that wants it. It never blocks:
+// an endpoint whose queue is full misses the event. A nil dispatcher
+// publishes nothing.
+func (d *Dispatcher) Publish(env Envelope) {
+	if d == nil {
+		return
+	}
+	select {
+	case <-d.closed:
+		return
+	default:
+	}
+	if env.Version == 0 {
+		env.Version = Version
+	}
+	if env.ID == "" {
+		env.ID = NewID()
+	}
+	if env.Time.IsZero() {
+		env.Time = time.Now()
+	}
+	body, err := json.Marshal(env)
+	if err != nil {
+		return
+	}
+	j := job{event: env.Event, id: env.ID, body: body}
+	for _, w := range d.workers {
+		if !w.ep.wants(env.Event) {
+			continue
+		}
+		select {
+		case w.queue <- j:
+		default:
+			d.dropped.Add(1)
+		}
+	}
+}
+
+// Dropped returns how many deliveries were dropped because a queue was full.
+func (d *Dispatcher) Dropped() int64 {
+	if d == nil {
+		return 0
+	}
+	return d.dropped.Load()
+}
+
+// Close stops accepting events and waits up to grace for queued deliveries;
+// whatever is left after that is abandoned.
+func (d *Dispatcher) Close(grace time.Duration) {
+	if d == nil {
+		return
+	}
+	d.once.Do(func() { close(d.closed) })
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(grace):
+	}
+}
+
+func (d *Dispatcher) run(w *worker) {
+	defer d.wg.Done()
+	for {
+		select {
+		case j := <-w.queue:
+			d.deliver(w.ep, j)
+		case <-d.closed:
+			// Drain what is already queued, then stop
+			for {
+				select {
+				case j := <-w.queue:
+					d.deliver(w.ep, j)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver posts body, retrying network errors, 429 and 5xx responses with
+// exponential backoff.
+func (d *Dispatcher) deliver(ep Endpoint, j job) {
+	wait := d.backoff
+	for attempt := 1; attempt <= MaxAttempts; attempt++ {
+		retry := d.post(ep, j)
+		if !retry || attempt == MaxAttempts {
+			return
+		}
+		select {
+		case <-time.After(wait):
+		case <-d.closed:
+			// Shutting down: one more try without waiting
+			d.post(ep, j)
+			return
+		}
+		wait *= 2
+	}
+}
+
+// post makes one attempt and reports whether it is worth retrying.
+func (d *Dispatcher) post(ep Endpoint, j job) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(j.body))
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "copilot-icq")
+	req.Header.Set(HeaderEvent, j.event)
+	req.Header.Set(HeaderDelivery, j.id)
+	if ep.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(ep.Secret, j.body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
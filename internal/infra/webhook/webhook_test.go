@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/e-9/copilot-icq/internal/config"
+)
+
+func TestDeliverySignedAndRetried(t *testing.T) {
+	var attempts atomic.Int32
+	got := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		got <- r
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	d := New([]Endpoint{{URL: srv.URL, Secret: "s3cret"}}, nil, time.Millisecond)
+	defer d.Close(time.Second)
+	d.Publish(Envelope{Event: SessionError, SessionID: "a", Data: Data{Error: "boom"}})
+
+	var r *http.Request
+	select {
+	case r = <-got:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no successful delivery")
+	}
+	body := <-bodies
+	if n := attempts.Load(); n != 3 {
+		t.Errorf("attempts = %d, want 3", n)
+	}
+	if sig := r.Header.Get(HeaderSignature); sig != Sign("s3cret", body) {
+		t.Errorf("signature = %q", sig)
+	}
+	if r.Header.Get(HeaderEvent) != SessionError {
+		t.Errorf("event header = %q", r.Header.Get(HeaderEvent))
+	}
+
+	var env Envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Version != Version || env.ID == "" || env.ID != r.Header.Get(HeaderDelivery) || env.Data.Error != "boom" {
+		t.Errorf("envelope = %+v", env)
+	}
+}
+
+func TestFilterAndBoundedQueue(t *testing.T) {
+	release := make(chan struct{})
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		received.Add(1)
+	}))
+	defer srv.Close()
+
+	d := New([]Endpoint{{URL: srv.URL, Events: map[string]bool{SessionIdle: true}}}, nil, time.Millisecond)
+
+	start := time.Now()
+	d.Publish(Envelope{Event: ToolCompleted}) // filtered out
+	for i := 0; i < QueueSize+10; i++ {
+		d.Publish(Envelope{Event: SessionIdle})
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Publish should not wait for a slow endpoint")
+	}
+	if d.Dropped() == 0 {
+		t.Error("a full queue should drop events")
+	}
+
+	close(release)
+	d.Close(5 * time.Second)
+	if n := received.Load(); n+int32(d.Dropped()) != QueueSize+10 {
+		t.Errorf("received %d and dropped %d, want %d in total", n, d.Dropped(), QueueSize+10)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	cfg := config.DefaultAppConfig()
+	if d, err := FromConfig(cfg); d != nil || err != nil {
+		t.Fatalf("no webhooks: %v, %v", d, err)
+	}
+	cfg.Webhooks = []config.WebhookConfig{{URL: "ftp://example.com"}}
+	if _, err := FromConfig(cfg); err == nil {
+		t.Error("a non-HTTP URL should be rejected")
+	}
+	cfg.Webhooks = []config.WebhookConfig{{URL: "https://example.com/hook", Events: []string{"session.exploded"}}}
+	if _, err := FromConfig(cfg); err == nil {
+		t.Error("an unknown event should be rejected")
+	}
+}
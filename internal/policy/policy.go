@@ -0,0 +1,270 @@
+// Package policy decides whether an agent's tool request may run without
+// asking the user, must be denied, or needs an interactive approval.
+package policy
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/e-9/copilot-icq/internal/config"
+)
+
+// Mode selects how tools outside the deny lists are handled.
+type Mode string
+
+const (
+	// ModeScoped allows only AllowedTools; everything else asks the user.
+	ModeScoped Mode = "scoped"
+	// ModeFullAuto allows every tool that is not denied.
+	ModeFullAuto Mode = "full-auto"
+)
+
+// Decision is the outcome of evaluating a tool request.
+type Decision int
+
+const (
+	Ask Decision = iota
+	Allow
+	Deny
+)
+
+// Result is a decision with a human-readable reason.
+type Result struct {
+	Decision Decision
+	Reason   string
+}
+
+// Rules is one set of allow and deny lists.
+//
+// Tool names may be globs ("mcp_*"). Argument patterns are substring
+// matches, globs when they contain * or ?, or regular expressions when
+// prefixed with "re:".
+type Rules struct {
+	Mode           Mode
+	AllowedTools   []string
+	DeniedTools    []string
+	DeniedPatterns []string
+}
+
+// Override applies extra rules to sessions whose working directory is Path
+// or below it. A non-empty Mode replaces the base mode; lists are added to
+// the base lists.
+type Override struct {
+	Path string
+	Rules
+}
+
+// Policy evaluates tool requests against base rules and per-CWD overrides.
+type Policy struct {
+	base      Rules
+	overrides []Override // longest path first
+	patterns  map[string]*regexp.Regexp
+}
+
+// New compiles a policy. It fails if a mode is unknown or a pattern is not
+// a valid regex or glob. An empty mode is scoped, or the base mode in an
+// override.
+func New(base Rules, overrides []Override) (*Policy, error) {
+	p := &Policy{
+		base:      base,
+		overrides: make([]Override, len(overrides)),
+		patterns:  make(map[string]*regexp.Regexp),
+	}
+	copy(p.overrides, overrides)
+	for i := range p.overrides {
+		p.overrides[i].Path = filepath.Clean(config.ExpandHome(p.overrides[i].Path))
+	}
+	sort.SliceStable(p.overrides, func(i, j int) bool {
+		return len(p.overrides[i].Path) > len(p.overrides[j].Path)
+	})
+
+	// Compile every pattern up front so Evaluate never writes to the cache
+	// and can be called from concurrent permission handlers.
+	all := append([]Rules{base}, rulesOf(p.overrides)...)
+	for _, r := range all {
+		switch r.Mode {
+		case "", ModeScoped, ModeFullAuto:
+		default:
+			return nil, fmt.Errorf("invalid security mode %q: want %q or %q", r.Mode, ModeScoped, ModeFullAuto)
+		}
+		var pats []string
+		pats = append(pats, r.AllowedTools...)
+		pats = append(pats, r.DeniedTools...)
+		pats = append(pats, r.DeniedPatterns...)
+		for _, pat := range pats {
+			if _, err := p.compile(pat); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return p, nil
+}
+
+// FromConfig builds a policy from the user's security settings.
+func FromConfig(cfg *config.AppConfig) (*Policy, error) {
+	if cfg == nil {
+		return New(Rules{Mode: ModeScoped}, nil)
+	}
+	base := Rules{
+		Mode:           Mode(cfg.SecurityMode),
+		AllowedTools:   cfg.AllowedTools,
+		DeniedTools:    cfg.DeniedTools,
+		DeniedPatterns: cfg.DeniedPatterns,
+	}
+	var overrides []Override
+	for _, pc := range cfg.Projects {
+		overrides = append(overrides, Override{
+			Path: pc.Path,
+			Rules: Rules{
+				Mode:           Mode(pc.SecurityMode),
+				AllowedTools:   pc.AllowedTools,
+				DeniedTools:    pc.DeniedTools,
+				DeniedPatterns: pc.DeniedPatterns,
+			},
+		})
+	}
+	return New(base, overrides)
+}
+
+// Evaluate decides what to do with a tool request from a session in cwd.
+// Deny rules always win; then full-auto allows everything, while scoped
+// mode allows only the allowed tools and asks for the rest.
+func (p *Policy) Evaluate(cwd, tool, args string) Result {
+	r := p.rulesFor(cwd)
+
+	for _, pat := range r.DeniedTools {
+		if p.matchTool(pat, tool) {
+			return Result{Decision: Deny, Reason: fmt.Sprintf("tool %q is in denied_tools", tool)}
+		}
+	}
+	for _, pat := range r.DeniedPatterns {
+		if p.matchArgs(pat, args) {
+			return Result{Decision: Deny, Reason: fmt.Sprintf("arguments match denied pattern %q", pat)}
+		}
+	}
+
+	if r.Mode == ModeFullAuto {
+		return Result{Decision: Allow, Reason: "full-auto mode"}
+	}
+	for _, pat := range r.AllowedTools {
+		if p.matchTool(pat, tool) {
+			return Result{Decision: Allow, Reason: fmt.Sprintf("tool %q is in allowed_tools", tool)}
+		}
+	}
+	return Result{Decision: Ask}
+}
+
+// ModeFor returns the effective security mode for a working directory.
+func (p *Policy) ModeFor(cwd string) Mode {
+	return p.rulesFor(cwd).Mode
+}
+
+// rulesFor merges the base rules with every override that contains cwd.
+func (p *Policy) rulesFor(cwd string) Rules {
+	r := Rules{
+		Mode:           p.base.Mode,
+		AllowedTools:   append([]string{}, p.base.AllowedTools...),
+		DeniedTools:    append([]string{}, p.base.DeniedTools...),
+		DeniedPatterns: append([]string{}, p.base.DeniedPatterns...),
+	}
+	if r.Mode == "" {
+		r.Mode = ModeScoped
+	}
+
+	modeSet := false
+	if cwd != "" {
+		cwd = filepath.Clean(cwd)
+		for _, o := range p.overrides {
+			if !config.WithinDir(cwd, o.Path) {
+				continue
+			}
+			// Overrides are sorted most specific first, so the first mode wins.
+			if o.Mode != "" && !modeSet {
+				r.Mode = o.Mode
+				modeSet = true
+			}
+			r.AllowedTools = append(r.AllowedTools, o.AllowedTools...)
+			r.DeniedTools = append(r.DeniedTools, o.DeniedTools...)
+			r.DeniedPatterns = append(r.DeniedPatterns, o.DeniedPatterns...)
+		}
+	}
+	return r
+}
+
+func (p *Policy) matchTool(pattern, tool string) bool {
+	if !isGlob(pattern) && !strings.HasPrefix(pattern, "re:") {
+		return pattern == tool
+	}
+	re, err := p.compile(pattern)
+	return err == nil && re.MatchString(tool)
+}
+
+func (p *Policy) matchArgs(pattern, args string) bool {
+	if pattern == "" {
+		return false
+	}
+	if !isGlob(pattern) && !strings.HasPrefix(pattern, "re:") {
+		return strings.Contains(args, pattern)
+	}
+	re, err := p.compile(pattern)
+	return err == nil && re.MatchString(args)
+}
+
+// compile turns a glob or "re:" pattern into a cached regexp.
+// Globs are anchored at both ends; regexes match anywhere unless anchored.
+func (p *Policy) compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := p.patterns[pattern]; ok {
+		return re, nil
+	}
+
+	var expr string
+	switch {
+	case strings.HasPrefix(pattern, "re:"):
+		expr = strings.TrimPrefix(pattern, "re:")
+	case isGlob(pattern):
+		expr = globToRegexp(pattern)
+	default:
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	p.patterns[pattern] = re
+	return re, nil
+}
+
+func isGlob(s string) bool {
+	return strings.ContainsAny(s, "*?")
+}
+
+// globToRegexp converts a glob where * matches any run of characters
+// (including /) and ? matches a single character.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
+
+func rulesOf(overrides []Override) []Rules {
+	rules := make([]Rules, len(overrides))
+	for i, o := range overrides {
+		rules[i] = o.Rules
+	}
+	return rules
+}
@@ -0,0 +1,99 @@
+package policy
+
+import "testing"
+
+func TestEvaluate(t *testing.T) {
+	p, err := New(Rules{
+		Mode:           ModeScoped,
+		AllowedTools:   []string{"view", "mcp_*"},
+		DeniedTools:    []string{"sudo"},
+		DeniedPatterns: []string{"rm -rf /", "git push *--force*", `re:(?i)drop\s+table`},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		tool string
+		args string
+		want Decision
+	}{
+		{"denied tool", "sudo", "ls", Deny},
+		{"substring pattern", "bash", "cd /tmp && rm -rf / --no-preserve-root", Deny},
+		{"glob pattern", "bash", "git push origin main --force", Deny},
+		{"glob pattern must match whole args", "bash", "echo git push --force", Ask},
+		{"regex pattern", "bash", "psql -c 'Drop  Table users'", Deny},
+		{"allowed tool", "view", "main.go", Allow},
+		{"allowed tool glob", "mcp_github", "", Allow},
+		{"not allowed asks", "bash", "ls", Ask},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Evaluate("", tt.tool, tt.args); got.Decision != tt.want {
+				t.Errorf("Evaluate(%q, %q) = %v (%s), want %v", tt.tool, tt.args, got.Decision, got.Reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateFullAuto(t *testing.T) {
+	p, err := New(Rules{Mode: ModeFullAuto, DeniedTools: []string{"bash"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Evaluate("", "edit", "main.go"); got.Decision != Allow {
+		t.Errorf("edit = %v, want Allow", got.Decision)
+	}
+	if got := p.Evaluate("", "bash", "ls"); got.Decision != Deny {
+		t.Errorf("bash = %v, want Deny even in full-auto", got.Decision)
+	}
+}
+
+func TestEvaluateOverrides(t *testing.T) {
+	p, err := New(Rules{Mode: ModeScoped}, []Override{
+		{Path: "/src", Rules: Rules{Mode: ModeFullAuto}},
+		{Path: "/src/prod", Rules: Rules{Mode: ModeScoped, DeniedTools: []string{"bash"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := p.ModeFor("/home/me"); got != ModeScoped {
+		t.Errorf("ModeFor(/home/me) = %q, want scoped", got)
+	}
+	if got := p.ModeFor("/src/tool"); got != ModeFullAuto {
+		t.Errorf("ModeFor(/src/tool) = %q, want full-auto", got)
+	}
+	if got := p.ModeFor("/src/prod/api"); got != ModeScoped {
+		t.Errorf("ModeFor(/src/prod/api) = %q, want scoped", got)
+	}
+	if got := p.ModeFor("/srcfoo"); got != ModeScoped {
+		t.Errorf("ModeFor(/srcfoo) = %q, want scoped (not a subdirectory)", got)
+	}
+
+	if got := p.Evaluate("/src/tool", "bash", "ls"); got.Decision != Allow {
+		t.Errorf("bash in /src/tool = %v, want Allow", got.Decision)
+	}
+	if got := p.Evaluate("/src/prod", "bash", "ls"); got.Decision != Deny {
+		t.Errorf("bash in /src/prod = %v, want Deny", got.Decision)
+	}
+}
+
+func TestNewRejectsInvalidPattern(t *testing.T) {
+	if _, err := New(Rules{DeniedPatterns: []string{"re:("}}, nil); err == nil {
+		t.Error("expected an error for an invalid regex")
+	}
+}
+
+func TestNewRejectsUnknownMode(t *testing.T) {
+	if _, err := New(Rules{Mode: "fullauto"}, nil); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+	if _, err := New(Rules{Mode: ModeScoped}, []Override{{Path: "/src", Rules: Rules{Mode: "yolo"}}}); err == nil {
+		t.Error("expected an error for an unknown mode in an override")
+	}
+	if _, err := New(Rules{}, []Override{{Path: "/src", Rules: Rules{Mode: ModeFullAuto}}}); err != nil {
+		t.Errorf("New: %v", err)
+	}
+}
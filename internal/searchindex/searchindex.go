@@ -0,0 +1,519 @@
+// Package searchindex is a full-text index over the history of every
+// session, kept in ~/.copilot-icq/index.json. It is only a cache: sessions
+// are re-read from their events.jsonl whenever it has changed, and live
+// events are added as they arrive in between.
+package searchindex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/infra/sessionrepo"
+)
+
+// version is bumped when the file format or what is indexed changes; an
+// index of another version is dropped and rebuilt.
+const version = 1
+
+// Index is the search index. It is safe for concurrent use, so Sync and
+// Save can run from a tea.Cmd while the app keeps adding live events.
+type Index struct {
+	path string
+	mu   sync.RWMutex
+	data data
+	// terms maps a term to the docs it appears in and how often; lengths
+	// holds each doc's term count. Both are rebuilt from data on load.
+	terms    map[string]map[docRef]int
+	lengths  map[docRef]int
+	totalLen int
+	dirty    bool
+	// sorted holds the terms in order, so the terms a query word is a
+	// prefix of are found by binary search. Once unsorted is set, the next
+	// search rebuilds it before looking anything up.
+	sorted   []string
+	unsorted bool
+}
+
+// data is the on-disk format.
+type data struct {
+	Version  int               `json:"version"`
+	Sessions map[string]*entry `json:"sessions"` // sessionID → its indexed history
+}
+
+// entry is what is indexed of one session.
+type entry struct {
+	Size     int64 `json:"size"`     // events.jsonl size when last read
+	Messages int   `json:"messages"` // messages in the history, including ones without text
+	Docs     []Doc `json:"docs"`
+}
+
+// Doc is the searchable text of one message.
+type Doc struct {
+	Message int                `json:"message"` // position in the session's history
+	Role    domain.MessageRole `json:"role"`
+	Time    time.Time          `json:"time"`
+	Text    string             `json:"text"`
+}
+
+type docRef struct {
+	session string
+	doc     int // index into the session's Docs
+}
+
+// Hit is a message matching a query.
+type Hit struct {
+	SessionID string
+	Message   int // position in the session's history
+	Role      domain.MessageRole
+	Time      time.Time
+	Snippet   string // the text around the first match, on one line
+	Score     float64
+}
+
+// DefaultPath returns the default index file path.
+func DefaultPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".copilot-icq", "index.json")
+}
+
+// Open reads the index file at path. A missing, unreadable or outdated file
+// yields an empty index, which Sync fills. An empty path yields an
+// in-memory index whose Save does nothing.
+func Open(path string) *Index {
+	ix := &Index{path: path}
+	if path != "" {
+		if raw, err := os.ReadFile(path); err == nil {
+			if json.Unmarshal(raw, &ix.data) != nil || ix.data.Version != version {
+				ix.data = data{}
+			}
+		}
+	}
+	ix.data.Version = version
+	if ix.data.Sessions == nil {
+		ix.data.Sessions = make(map[string]*entry)
+	}
+	ix.terms = make(map[string]map[docRef]int)
+	ix.lengths = make(map[docRef]int)
+	for id, e := range ix.data.Sessions {
+		for i := range e.Docs {
+			ix.post(docRef{id, i}, e.Docs[i].Text)
+		}
+	}
+	return ix
+}
+
+// Save writes the index file atomically if anything changed since it was
+// read or last saved.
+func (ix *Index) Save() error {
+	ix.mu.Lock()
+	if !ix.dirty || ix.path == "" {
+		ix.mu.Unlock()
+		return nil
+	}
+	raw, err := json.Marshal(ix.data)
+	ix.dirty = false
+	ix.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(ix.path), 0o700); err != nil {
+		return fmt.Errorf("save search index: %w", err)
+	}
+	tmp := ix.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("save search index: %w", err)
+	}
+	if err := os.Rename(tmp, ix.path); err != nil {
+		return fmt.Errorf("save search index: %w", err)
+	}
+	return nil
+}
+
+// Sync brings the index up to date with the sessions under base: sessions
+// whose events.jsonl changed since they were indexed are read again, and
+// sessions that are gone are dropped. It reports how many sessions it read.
+// A session that cannot be read is skipped and its error returned once the
+// others are done.
+func (ix *Index) Sync(ctx context.Context, base string, sessions []domain.Session) (int, error) {
+	history := copilot.NewFileBackend(base)
+	read := 0
+	var firstErr error
+	listed := make(map[string]bool, len(sessions))
+	for _, s := range sessions {
+		listed[s.ID] = true
+		if err := ctx.Err(); err != nil {
+			return read, err
+		}
+		info, err := os.Stat(sessionrepo.EventsPath(base, s.ID))
+		if err != nil {
+			continue // nothing said in it yet
+		}
+		ix.mu.RLock()
+		e := ix.data.Sessions[s.ID]
+		current := e != nil && e.Size == info.Size()
+		ix.mu.RUnlock()
+		if current {
+			continue
+		}
+
+		msgs, err := history.GetHistory(ctx, s.ID)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		ix.Set(s.ID, msgs, info.Size())
+		read++
+	}
+
+	ix.mu.Lock()
+	for id := range ix.data.Sessions {
+		if !listed[id] {
+			ix.remove(id)
+		}
+	}
+	ix.mu.Unlock()
+	return read, firstErr
+}
+
+// Set replaces what is indexed of a session with its history. size is the
+// size of the events.jsonl it was read from.
+func (ix *Index) Set(sessionID string, msgs []domain.Message, size int64) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(sessionID)
+	e := &entry{Size: size}
+	ix.data.Sessions[sessionID] = e
+	for _, msg := range msgs {
+		ix.add(sessionID, e, msg)
+	}
+	ix.dirty = true
+}
+
+// Remove drops a session from the index.
+func (ix *Index) Remove(sessionID string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(sessionID)
+}
+
+// Apply adds the message a live event carries to its session, so it can be
+// found before the session is next synced. Events of sessions not indexed
+// yet are left for Sync, as their earlier messages are not known.
+func (ix *Index) Apply(ev copilot.Event) {
+	switch {
+	case ev.Type == copilot.EventLifecycle && ev.Lifecycle != nil:
+		if ev.Lifecycle.Type == sdk.SessionLifecycleDeleted {
+			ix.Remove(ev.SessionID)
+		}
+	case ev.Type == copilot.EventSession && ev.SessionEvent != nil:
+		msg, ok := copilot.EventMessage(*ev.SessionEvent)
+		if !ok {
+			return
+		}
+		ix.mu.Lock()
+		defer ix.mu.Unlock()
+		if e, ok := ix.data.Sessions[ev.SessionID]; ok {
+			ix.add(ev.SessionID, e, msg)
+			ix.dirty = true
+		}
+	}
+}
+
+// add appends a message to a session. The caller holds the write lock.
+func (ix *Index) add(sessionID string, e *entry, msg domain.Message) {
+	n := e.Messages
+	e.Messages++
+	text := docText(msg)
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	e.Docs = append(e.Docs, Doc{Message: n, Role: msg.Role, Time: msg.Timestamp, Text: text})
+	ix.post(docRef{sessionID, len(e.Docs) - 1}, text)
+}
+
+// remove drops a session and its postings. The caller holds the write lock.
+func (ix *Index) remove(sessionID string) {
+	e, ok := ix.data.Sessions[sessionID]
+	if !ok {
+		return
+	}
+	for i, d := range e.Docs {
+		ref := docRef{sessionID, i}
+		for _, t := range tokenize(d.Text) {
+			if posts := ix.terms[t]; posts != nil {
+				delete(posts, ref)
+				if len(posts) == 0 {
+					delete(ix.terms, t)
+					ix.unsorted = true
+				}
+			}
+		}
+		ix.totalLen -= ix.lengths[ref]
+		delete(ix.lengths, ref)
+	}
+	delete(ix.data.Sessions, sessionID)
+	ix.dirty = true
+}
+
+// post records the terms of a doc.
+func (ix *Index) post(ref docRef, text string) {
+	terms := tokenize(text)
+	for _, t := range terms {
+		posts := ix.terms[t]
+		if posts == nil {
+			posts = make(map[docRef]int)
+			ix.terms[t] = posts
+			ix.unsorted = true
+		}
+		posts[ref]++
+	}
+	ix.lengths[ref] = len(terms)
+	ix.totalLen += len(terms)
+}
+
+// Ranking uses BM25. A query word also matches the terms it is a prefix
+// of, so "middle" finds "middleware", at prefixWeight of an exact match.
+const (
+	k1           = 1.2
+	b            = 0.75
+	prefixWeight = 0.5
+)
+
+// Search returns up to limit messages containing every word of the query,
+// best first; equally good hits are ordered newest first.
+func (ix *Index) Search(query string, limit int) []Hit {
+	words := unique(tokenize(query))
+	if len(words) == 0 {
+		return nil
+	}
+
+	defer ix.lockSorted()()
+	n := float64(len(ix.lengths))
+	if n == 0 {
+		return nil
+	}
+	avg := float64(ix.totalLen) / n
+
+	var scores map[docRef]float64
+	for i, w := range words {
+		// The best score of any term the word matches, per doc
+		matched := make(map[docRef]float64)
+		for _, term := range ix.withPrefix(w) {
+			posts := ix.terms[term]
+			weight := 1.0
+			if term != w {
+				weight = prefixWeight
+			}
+			df := float64(len(posts))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			for ref, tf := range posts {
+				f := float64(tf)
+				l := float64(ix.lengths[ref])
+				s := weight * idf * f * (k1 + 1) / (f + k1*(1-b+b*l/avg))
+				if s > matched[ref] {
+					matched[ref] = s
+				}
+			}
+		}
+		if i == 0 {
+			scores = matched
+			continue
+		}
+		for ref := range scores {
+			if s, ok := matched[ref]; ok {
+				scores[ref] += s
+			} else {
+				delete(scores, ref)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	refs := make([]docRef, 0, len(scores))
+	for ref, score := range scores {
+		d := ix.data.Sessions[ref.session].Docs[ref.doc]
+		hits = append(hits, Hit{
+			SessionID: ref.session,
+			Message:   d.Message,
+			Role:      d.Role,
+			Time:      d.Time,
+			Score:     score,
+		})
+		refs = append(refs, ref)
+	}
+	order := make([]int, len(hits))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		x, y := hits[order[i]], hits[order[j]]
+		if x.Score != y.Score {
+			return x.Score > y.Score
+		}
+		return x.Time.After(y.Time)
+	})
+	if limit > 0 && len(order) > limit {
+		order = order[:limit]
+	}
+
+	re := wordsPattern(words)
+	out := make([]Hit, len(order))
+	for i, o := range order {
+		out[i] = hits[o]
+		ref := refs[o]
+		out[i].Snippet = snippet(ix.data.Sessions[ref.session].Docs[ref.doc].Text, re)
+	}
+	return out
+}
+
+// lockSorted locks the index for a search and returns the unlock func. A
+// search normally shares the read lock; when terms were added or removed
+// since the last one, it takes the write lock instead, sorts them and keeps
+// the lock, so a Set in between cannot slip past the sort.
+func (ix *Index) lockSorted() (unlock func()) {
+	ix.mu.RLock()
+	if !ix.unsorted {
+		return ix.mu.RUnlock
+	}
+	ix.mu.RUnlock()
+	ix.mu.Lock()
+	ix.sortTerms()
+	return ix.mu.Unlock
+}
+
+// sortTerms sorts the terms again after some were added or removed. The
+// caller holds the write lock.
+func (ix *Index) sortTerms() {
+	if !ix.unsorted {
+		return
+	}
+	ix.sorted = ix.sorted[:0]
+	for t := range ix.terms {
+		ix.sorted = append(ix.sorted, t)
+	}
+	sort.Strings(ix.sorted)
+	ix.unsorted = false
+}
+
+// withPrefix returns the sorted terms that start with prefix. The caller
+// holds the lock from lockSorted.
+func (ix *Index) withPrefix(prefix string) []string {
+	i := sort.SearchStrings(ix.sorted, prefix)
+	j := i + sort.Search(len(ix.sorted)-i, func(k int) bool {
+		return !strings.HasPrefix(ix.sorted[i+k], prefix)
+	})
+	return ix.sorted[i:j]
+}
+
+// maxField caps how much of each tool call field is indexed; tool output
+// can be whole files.
+const maxField = 4096
+
+// docText is the text a message is found by: its content and, for each
+// tool call, the tool, file, command, arguments, result and patch.
+func docText(msg domain.Message) string {
+	parts := []string{msg.Content}
+	for _, tc := range msg.ToolCalls {
+		for _, f := range []string{tc.Name, tc.FilePath, tc.Command, tc.Args, tc.Summary, tc.Patch} {
+			if f != "" {
+				parts = append(parts, clip(f, maxField))
+			}
+		}
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
+
+// tokenize splits text into lower-case terms at anything that is not a
+// letter or digit, so paths and identifiers split into their words.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func unique(words []string) []string {
+	seen := make(map[string]bool, len(words))
+	out := words[:0]
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// WordsPattern returns a pattern that matches the words of a query where
+// the index would, ignoring case, for highlighting them. It returns nil
+// for a query without words.
+func WordsPattern(query string) *regexp.Regexp {
+	return wordsPattern(unique(tokenize(query)))
+}
+
+func wordsPattern(words []string) *regexp.Regexp {
+	if len(words) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = regexp.QuoteMeta(w)
+	}
+	return regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+}
+
+// Snippets show this many runes before the first match and after its start.
+const (
+	snippetBefore = 30
+	snippetAfter  = 90
+)
+
+// snippet returns the text around the first match of re, on one line.
+func snippet(text string, re *regexp.Regexp) string {
+	start := 0
+	if loc := re.FindStringIndex(text); loc != nil {
+		start = loc[0]
+	}
+	before := []rune(text[:start])
+	after := []rune(text[start:])
+
+	prefix := ""
+	if len(before) > snippetBefore {
+		before = before[len(before)-snippetBefore:]
+		prefix = "…"
+	}
+	suffix := ""
+	if len(after) > snippetAfter {
+		after = after[:snippetAfter]
+		suffix = "…"
+	}
+	return prefix + strings.Join(strings.Fields(string(before)+string(after)), " ") + suffix
+}
+
+// clip cuts s to at most n bytes without splitting a rune.
+func clip(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
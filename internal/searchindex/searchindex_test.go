@@ -0,0 +1,179 @@
+package searchindex
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/github/copilot-sdk/go"
+
+	"github.com/e-9/copilot-icq/internal/copilot"
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+// writeSession writes a session directory with the given events.jsonl.
+func writeSession(t *testing.T, base, id, events string) {
+	t.Helper()
+	dir := filepath.Join(base, id)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "workspace.yaml"), []byte("id: "+id+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "events.jsonl"), []byte(events), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const authEvents = `{"type":"user.message","id":"1","timestamp":"2026-03-02T09:00:00Z","data":{"content":"tidy up the login page"}}
+{"type":"tool.execution_start","id":"2","timestamp":"2026-03-02T09:00:01Z","data":{"toolName":"edit","toolCallId":"c1","arguments":{"path":"internal/auth/middleware.go"}}}
+{"type":"tool.execution_complete","id":"3","timestamp":"2026-03-02T09:00:02Z","data":{"toolName":"edit","toolCallId":"c1","success":true,"result":{"content":"edited"}}}
+{"type":"assistant.message","id":"4","timestamp":"2026-03-02T09:00:03Z","data":{"content":"Done: the auth check now runs first."}}
+`
+
+const otherEvents = `{"type":"user.message","id":"1","timestamp":"2026-03-01T09:00:00Z","data":{"content":"what does the auth package do?"}}
+{"type":"assistant.message","id":"2","timestamp":"2026-03-01T09:00:01Z","data":{"content":"It wraps handlers in an auth middleware that checks tokens."}}
+`
+
+func TestSyncAndSearch(t *testing.T) {
+	base := t.TempDir()
+	writeSession(t, base, "s1", authEvents)
+	writeSession(t, base, "s2", otherEvents)
+	sessions := []domain.Session{{ID: "s1"}, {ID: "s2"}}
+
+	path := filepath.Join(t.TempDir(), "index.json")
+	ix := Open(path)
+	ctx := context.Background()
+	if n, err := ix.Sync(ctx, base, sessions); n != 2 || err != nil {
+		t.Fatalf("sync read %d sessions, %v", n, err)
+	}
+
+	// Tool arguments are indexed, and words match as prefixes
+	hits := ix.Search("auth middle", 10)
+	if len(hits) != 2 {
+		t.Fatalf("hits = %+v", hits)
+	}
+	if hits[0].SessionID != "s1" || hits[0].Message != 1 || !strings.Contains(hits[0].Snippet, "internal/auth/middleware.go") {
+		t.Errorf("best hit = %+v, want the edit in s1", hits[0])
+	}
+	if hits[1].SessionID != "s2" || hits[1].Message != 1 || hits[1].Role != domain.RoleAssistant {
+		t.Errorf("second hit = %+v", hits[1])
+	}
+	if hits := ix.Search("login middleware", 10); len(hits) != 0 {
+		t.Errorf("every word must match in the same message, got %+v", hits)
+	}
+
+	// An unchanged session is not read again, and the index survives a reopen
+	if n, _ := ix.Sync(ctx, base, sessions); n != 0 {
+		t.Errorf("second sync read %d sessions", n)
+	}
+	if err := ix.Save(); err != nil {
+		t.Fatal(err)
+	}
+	ix = Open(path)
+	if n, _ := ix.Sync(ctx, base, sessions); n != 0 {
+		t.Errorf("sync after reopening read %d sessions", n)
+	}
+	if len(ix.Search("tokens", 10)) != 1 {
+		t.Error("a reopened index should find what was saved")
+	}
+
+	// Sessions that are gone are dropped
+	ix.Sync(ctx, base, sessions[:1])
+	if hits := ix.Search("tokens", 10); len(hits) != 0 {
+		t.Errorf("hits in a removed session: %+v", hits)
+	}
+}
+
+func TestApplyLiveEvents(t *testing.T) {
+	ix := Open("")
+	ix.Set("s1", []domain.Message{{Role: domain.RoleUser, Content: "hi"}, {Role: domain.RoleAssistant}}, 10)
+
+	content := "the flaky websocket test is fixed"
+	ix.Apply(copilot.Event{Type: copilot.EventSession, SessionID: "s1", SessionEvent: &sdk.SessionEvent{
+		Type:      sdk.AssistantMessage,
+		Timestamp: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+		Data:      sdk.Data{Content: &content},
+	}})
+	ix.Apply(copilot.Event{Type: copilot.EventSession, SessionID: "s2", SessionEvent: &sdk.SessionEvent{
+		Type: sdk.AssistantMessage,
+		Data: sdk.Data{Content: &content},
+	}})
+	hits := ix.Search("websocket", 10)
+	if len(hits) != 1 || hits[0].SessionID != "s1" || hits[0].Message != 2 {
+		t.Fatalf("hits = %+v, want message 2 of s1 only", hits)
+	}
+
+	ix.Apply(copilot.Event{Type: copilot.EventLifecycle, SessionID: "s1", Lifecycle: &sdk.SessionLifecycleEvent{
+		Type: sdk.SessionLifecycleDeleted, SessionID: "s1",
+	}})
+	if len(ix.Search("websocket", 10)) != 0 || len(ix.lengths) != 0 || len(ix.terms) != 0 {
+		t.Error("a deleted session should leave nothing behind")
+	}
+}
+
+func TestPrefixesFollowChanges(t *testing.T) {
+	ix := Open("")
+	ix.Set("s1", []domain.Message{{Role: domain.RoleUser, Content: "cache caching"}}, 10)
+	ix.Set("s2", []domain.Message{{Role: domain.RoleUser, Content: "cat"}}, 10)
+	if got := sessionsOf(ix.Search("cach", 10)); got != "s1" {
+		t.Errorf("hits for cach = %v, want s1", got)
+	}
+
+	// Terms added or removed since the last search are found
+	ix.Set("s3", []domain.Message{{Role: domain.RoleUser, Content: "cachet"}}, 10)
+	ix.Remove("s1")
+	if got := sessionsOf(ix.Search("cach", 10)); got != "s3" {
+		t.Errorf("hits for cach = %v, want s3", got)
+	}
+	if got := sessionsOf(ix.Search("ca", 10)); got != "s2 s3" {
+		t.Errorf("hits for ca = %v, want s2 s3", got)
+	}
+	if hits := ix.Search("zz", 10); len(hits) != 0 {
+		t.Errorf("hits = %+v, want none past the last term", hits)
+	}
+}
+
+// sessionsOf lists the sessions of hits, sorted.
+func sessionsOf(hits []Hit) string {
+	var ids []string
+	for _, h := range hits {
+		ids = append(ids, h.SessionID)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, " ")
+}
+
+// BenchmarkSearch looks up a prefix in an index of many distinct terms.
+// Only the terms with the prefix are visited, so it should stay fast as
+// the dictionary grows.
+func BenchmarkSearch(b *testing.B) {
+	ix := Open("")
+	var msgs []domain.Message
+	for i := 0; i < 50000; i++ {
+		msgs = append(msgs, domain.Message{Role: domain.RoleUser, Content: fmt.Sprintf("term%d word%d", i, i%100)})
+	}
+	ix.Set("s1", msgs, 10)
+	ix.Search("term1", 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ix.Search("word42", 10)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	text := strings.Repeat("lorem ipsum ", 10) + "the needle\n\nis here " + strings.Repeat("dolor sit ", 20)
+	got := snippet(text, WordsPattern("needle"))
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "the needle is here") {
+		t.Errorf("snippet = %q", got)
+	}
+	if got := snippet("short", WordsPattern("absent")); got != "short" {
+		t.Errorf("snippet without a match = %q", got)
+	}
+}
@@ -0,0 +1,198 @@
+// Package state persists TUI-local session state, such as which sessions are
+// archived, unsent drafts and muted notifications, in ~/.copilot-icq/state.json. None of it is
+// shared with Copilot CLI.
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store is the persisted local state. It is safe for concurrent use, so
+// Save can run from a tea.Cmd while the app keeps reading it.
+type Store struct {
+	path string
+	mu   sync.Mutex
+	data data
+}
+
+// data is the on-disk format.
+type data struct {
+	Archived map[string]bool  `json:"archived,omitempty"` // sessionID → hidden from the sidebar
+	Drafts   map[string]Draft `json:"drafts,omitempty"`   // sessionID → unsent composer text
+	Muted    map[string]bool  `json:"muted,omitempty"`    // sessionID → no desktop notifications
+}
+
+// Draft is unsent composer text and where the cursor was.
+type Draft struct {
+	Text string `json:"text"`
+	Row  int    `json:"row,omitempty"`
+	Col  int    `json:"col,omitempty"`
+}
+
+// DefaultPath returns the default state file path.
+func DefaultPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".copilot-icq", "state.json")
+}
+
+// Load reads the state file at path. A missing file yields an empty store.
+// An empty path yields an in-memory store whose Save does nothing.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path}
+	if path == "" {
+		return s, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read state: %w", err)
+	}
+	if err := json.Unmarshal(raw, &s.data); err != nil {
+		return nil, fmt.Errorf("parse state %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save writes the state file atomically.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	raw, err := json.MarshalIndent(s.data, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if s.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+	return nil
+}
+
+// Archived returns a copy of the archived session IDs.
+func (s *Store) Archived() map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]bool, len(s.data.Archived))
+	for id := range s.data.Archived {
+		out[id] = true
+	}
+	return out
+}
+
+// IsArchived reports whether a session is archived.
+func (s *Store) IsArchived(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Archived[id]
+}
+
+// SetArchived archives or unarchives a session.
+func (s *Store) SetArchived(id string, archived bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !archived {
+		delete(s.data.Archived, id)
+		return
+	}
+	if s.data.Archived == nil {
+		s.data.Archived = make(map[string]bool)
+	}
+	s.data.Archived[id] = true
+}
+
+// Draft returns a session's unsent draft, if it has one.
+func (s *Store) Draft(id string) (Draft, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.data.Drafts[id]
+	return d, ok
+}
+
+// SetDraft stores a session's draft; an empty text removes it. It reports
+// whether anything changed, so callers only save when needed.
+func (s *Store) SetDraft(id string, d Draft) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, had := s.data.Drafts[id]
+	if d.Text == "" {
+		delete(s.data.Drafts, id)
+		return had
+	}
+	if had && old == d {
+		return false
+	}
+	if s.data.Drafts == nil {
+		s.data.Drafts = make(map[string]Draft)
+	}
+	s.data.Drafts[id] = d
+	return true
+}
+
+// DraftIDs returns the sessions that have an unsent draft.
+func (s *Store) DraftIDs() map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]bool, len(s.data.Drafts))
+	for id := range s.data.Drafts {
+		out[id] = true
+	}
+	return out
+}
+
+// Forget drops everything stored about a session, e.g. after it is deleted.
+func (s *Store) Forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data.Archived, id)
+	delete(s.data.Drafts, id)
+	delete(s.data.Muted, id)
+}
+
+// Muted returns a copy of the muted session IDs.
+func (s *Store) Muted() map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]bool, len(s.data.Muted))
+	for id := range s.data.Muted {
+		out[id] = true
+	}
+	return out
+}
+
+// IsMuted reports whether a session's notifications are muted.
+func (s *Store) IsMuted(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Muted[id]
+}
+
+// SetMuted mutes or unmutes a session's notifications.
+func (s *Store) SetMuted(id string, muted bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !muted {
+		delete(s.data.Muted, id)
+		return
+	}
+	if s.data.Muted == nil {
+		s.data.Muted = make(map[string]bool)
+	}
+	s.data.Muted[id] = true
+}
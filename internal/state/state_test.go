@@ -0,0 +1,65 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load missing file: %v", err)
+	}
+	s.SetArchived("a", true)
+	s.SetArchived("b", true)
+	s.SetArchived("b", false)
+	s.SetMuted("a", true)
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !loaded.IsArchived("a") || loaded.IsArchived("b") {
+		t.Errorf("archived = %v, want only a", loaded.Archived())
+	}
+	if !loaded.IsMuted("a") {
+		t.Error("muted flag should be saved")
+	}
+
+	loaded.Forget("a")
+	if loaded.IsArchived("a") {
+		t.Error("Forget should drop the archived flag")
+	}
+	if loaded.IsMuted("a") {
+		t.Error("Forget should drop the muted flag")
+	}
+}
+
+func TestDrafts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s, _ := Load(path)
+
+	d := Draft{Text: "fix the\nflaky test", Row: 1, Col: 3}
+	if !s.SetDraft("a", d) {
+		t.Error("first SetDraft should report a change")
+	}
+	if s.SetDraft("a", d) {
+		t.Error("storing the same draft again should not report a change")
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, _ := Load(path)
+	if got, ok := loaded.Draft("a"); !ok || got != d {
+		t.Errorf("Draft(a) = %+v, %v; want %+v", got, ok, d)
+	}
+	if !loaded.SetDraft("a", Draft{}) || len(loaded.DraftIDs()) != 0 {
+		t.Error("an empty draft should remove the stored one")
+	}
+}
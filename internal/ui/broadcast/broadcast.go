@@ -0,0 +1,249 @@
+// Package broadcast provides the composer for sending one prompt to several
+// sessions and the progress view that follows each of them until it is done.
+package broadcast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/e-9/copilot-icq/internal/ui/theme"
+)
+
+// Status is where a target session is in the broadcast.
+type Status int
+
+const (
+	Waiting  Status = iota // busy with an earlier turn
+	Resuming               // being resumed before the prompt can be sent
+	Sending                // prompt sent, Copilot is working
+	Idle                   // Copilot finished the turn
+	Failed                 // resume, send or the turn itself failed
+)
+
+func (s Status) String() string {
+	switch s {
+	case Waiting:
+		return "waiting"
+	case Resuming:
+		return "resuming"
+	case Sending:
+		return "sending"
+	case Idle:
+		return "idle"
+	case Failed:
+		return "error"
+	}
+	return "unknown"
+}
+
+// Target is a session the prompt goes to.
+type Target struct {
+	ID     string
+	Name   string
+	Status Status
+	Err    string
+}
+
+// Model is the broadcast panel. It starts as a composer; once the prompt is
+// submitted it shows the progress of every target.
+type Model struct {
+	targets   []Target
+	text      textarea.Model
+	width     int
+	height    int
+	submitted *string
+	closed    bool
+}
+
+// New creates a composer for the given target sessions.
+func New(targets []Target, width, height int) Model {
+	ta := textarea.New()
+	ta.Placeholder = "Prompt to send to every selected session..."
+	ta.CharLimit = 0
+	ta.ShowLineNumbers = false
+	ta.Prompt = "❯ "
+	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	ta.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter", "ctrl+j"))
+	ta.Focus()
+
+	m := Model{targets: targets, text: ta}
+	m.SetSize(width, height)
+	return m
+}
+
+// SetSize updates the panel dimensions.
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.text.SetWidth(w - 4)
+	m.text.SetHeight(max(3, min(8, h-len(m.targets)-6)))
+}
+
+// Submitted returns the prompt once the user has sent it.
+func (m Model) Submitted() (string, bool) {
+	if m.submitted == nil {
+		return "", false
+	}
+	return *m.submitted, true
+}
+
+// Closed reports whether the user closed the panel.
+func (m Model) Closed() bool {
+	return m.closed
+}
+
+// Reopen shows a closed panel again, e.g. to check on the progress.
+func (m *Model) Reopen() {
+	m.closed = false
+}
+
+// SetStatus records a target's progress. Unknown sessions are ignored.
+func (m *Model) SetStatus(id string, s Status, err error) {
+	for i := range m.targets {
+		if m.targets[i].ID == id {
+			m.targets[i].Status = s
+			m.targets[i].Err = ""
+			if err != nil {
+				m.targets[i].Err = err.Error()
+			}
+		}
+	}
+}
+
+// Status returns a target's progress and whether the session is a target.
+func (m Model) Status(id string) (Status, bool) {
+	for _, t := range m.targets {
+		if t.ID == id {
+			return t.Status, true
+		}
+	}
+	return 0, false
+}
+
+// Targets returns the target sessions.
+func (m Model) Targets() []Target {
+	return m.targets
+}
+
+// Finished reports whether every target is idle or failed.
+func (m Model) Finished() bool {
+	return m.submitted != nil && m.remaining() == 0
+}
+
+func (m Model) remaining() int {
+	n := 0
+	for _, t := range m.targets {
+		if t.Status != Idle && t.Status != Failed {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary is a one-line account of the progress, for the status bar.
+func (m Model) Summary() string {
+	return fmt.Sprintf("📣 %d/%d done", len(m.targets)-m.remaining(), len(m.targets))
+}
+
+// Update handles key presses. While composing, Enter sends and Esc cancels;
+// afterwards Esc, q or Enter close the progress view.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	k, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.submitted != nil {
+		switch k.String() {
+		case "esc", "q", "enter":
+			m.closed = true
+		}
+		return m, nil
+	}
+
+	switch k.String() {
+	case "esc":
+		m.closed = true
+		return m, nil
+	case "enter":
+		text := strings.TrimSpace(m.text.Value())
+		if text == "" {
+			return m, nil
+		}
+		m.submitted = &text
+		m.text.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.text, cmd = m.text.Update(msg)
+	return m, cmd
+}
+
+var (
+	labelStyle = lipgloss.NewStyle().Foreground(theme.Subtle)
+	nameStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	hintStyle  = lipgloss.NewStyle().Foreground(theme.Subtle).Italic(true)
+	errorStyle = lipgloss.NewStyle().Foreground(theme.Error)
+
+	statusStyles = map[Status]lipgloss.Style{
+		Waiting:  lipgloss.NewStyle().Foreground(theme.Subtle),
+		Resuming: lipgloss.NewStyle().Foreground(theme.Warning),
+		Sending:  lipgloss.NewStyle().Foreground(theme.Warning),
+		Idle:     lipgloss.NewStyle().Foreground(theme.Accent),
+		Failed:   lipgloss.NewStyle().Foreground(theme.Error),
+	}
+	statusIcons = map[Status]string{
+		Waiting:  "…",
+		Resuming: "⏳",
+		Sending:  "⏳",
+		Idle:     "✓",
+		Failed:   "✗",
+	}
+)
+
+// View renders the composer or the progress view.
+func (m Model) View() string {
+	var sb strings.Builder
+	if m.submitted == nil {
+		sb.WriteString("  " + labelStyle.Render(fmt.Sprintf("Send to %d sessions:", len(m.targets))) + "\n")
+		for _, t := range m.targets {
+			sb.WriteString("    • " + nameStyle.Render(t.Name) + "\n")
+		}
+		sb.WriteString("\n  " + m.text.View() + "\n\n")
+		sb.WriteString(hintStyle.Render("  Enter send · Alt+Enter newline · Esc cancel"))
+		return lipgloss.NewStyle().Width(m.width).Render(sb.String())
+	}
+
+	sb.WriteString("  " + labelStyle.Render("Prompt: ") + nameStyle.Render(preview(*m.submitted, m.width-12)) + "\n\n")
+	for _, t := range m.targets {
+		st := statusStyles[t.Status]
+		line := fmt.Sprintf("  %s %s %s", st.Render(statusIcons[t.Status]), st.Render(fmt.Sprintf("%-8s", t.Status)), nameStyle.Render(t.Name))
+		sb.WriteString(line + "\n")
+		if t.Err != "" {
+			sb.WriteString("      " + errorStyle.Render(t.Err) + "\n")
+		}
+	}
+	sb.WriteString("\n")
+	if m.Finished() {
+		sb.WriteString(hintStyle.Render("  All sessions finished · Esc close"))
+	} else {
+		sb.WriteString(hintStyle.Render("  " + m.Summary() + " · Esc hide (B in the sidebar shows it again)"))
+	}
+	return lipgloss.NewStyle().Width(m.width).Render(sb.String())
+}
+
+// preview flattens text to one line of at most width runes.
+func preview(s string, width int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	width = max(width, 10)
+	r := []rune(s)
+	if len(r) > width {
+		return string(r[:width-1]) + "…"
+	}
+	return s
+}
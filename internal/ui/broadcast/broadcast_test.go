@@ -0,0 +1,66 @@
+package broadcast
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func press(m Model, keys ...tea.KeyMsg) Model {
+	for _, k := range keys {
+		m, _ = m.Update(k)
+	}
+	return m
+}
+
+var (
+	enter = tea.KeyMsg{Type: tea.KeyEnter}
+	esc   = tea.KeyMsg{Type: tea.KeyEsc}
+)
+
+func TestComposer(t *testing.T) {
+	targets := []Target{{ID: "a", Name: "api"}, {ID: "b", Name: "web"}}
+	m := press(New(targets, 80, 20), enter)
+	if _, ok := m.Submitted(); ok {
+		t.Fatal("an empty prompt should not be sent")
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}, tea.KeyMsg{Type: tea.KeyCtrlJ}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}, enter)
+	if text, ok := m.Submitted(); !ok || text != "q\nx" {
+		t.Fatalf("submitted %v %q; q is text while composing and Ctrl+J a newline", ok, text)
+	}
+	if m.Closed() {
+		t.Fatal("sending should show the progress, not close")
+	}
+	if m = press(New(targets, 80, 20), esc); !m.Closed() {
+		t.Error("Esc should cancel the composer")
+	}
+}
+
+func TestProgress(t *testing.T) {
+	m := press(New([]Target{{ID: "a", Name: "api"}, {ID: "b", Name: "web"}}, 80, 20), tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("go")}, enter)
+	m.SetStatus("a", Idle, nil)
+	m.SetStatus("b", Sending, nil)
+	m.SetStatus("other", Failed, nil)
+	if m.Finished() || m.Summary() != "📣 1/2 done" {
+		t.Errorf("finished = %v, summary = %q", m.Finished(), m.Summary())
+	}
+	if _, ok := m.Status("other"); ok {
+		t.Error("sessions outside the broadcast should not be tracked")
+	}
+
+	m.SetStatus("b", Failed, errors.New("session gone"))
+	if !m.Finished() || !strings.Contains(m.View(), "session gone") {
+		t.Errorf("finished = %v, view:\n%s", m.Finished(), m.View())
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if !m.Closed() {
+		t.Error("q should close the progress view")
+	}
+	if m.Reopen(); m.Closed() {
+		t.Error("Reopen should show the panel again")
+	}
+}
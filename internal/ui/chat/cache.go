@@ -0,0 +1,86 @@
+package chat
+
+import (
+	"hash/fnv"
+	"io"
+	"strconv"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+// rendered is a rendered message and where its tool calls start, as line
+// offsets into the text.
+type rendered struct {
+	text  string
+	tools []int
+}
+
+// toolLines returns where the tool calls start in a message rendered from
+// line start on.
+func (r rendered) toolLines(start int) []int {
+	lines := make([]int, len(r.tools))
+	for i, l := range r.tools {
+		lines[i] = start + l
+	}
+	return lines
+}
+
+// renderKey identifies a rendered message: a hash of everything it is
+// rendered from, and the width it was rendered at. A resize changes the
+// width, so stale entries simply stop matching and are dropped at the next
+// full render.
+type renderKey struct {
+	hash  uint64
+	width int
+}
+
+// cachedMessage renders the message at position i, reusing the output of
+// an earlier render when nothing it depends on has changed. Entries used
+// are recorded in used, which becomes the cache once the render is done.
+func (m *Model) cachedMessage(i int, toolIdx *int, used map[renderKey]rendered) rendered {
+	msg := m.messages[i]
+	key := renderKey{hash: m.messageHash(msg, *toolIdx, m.selection(i), m.streamingAt(i)), width: m.width}
+	r, ok := m.cache[key]
+	if ok {
+		*toolIdx += len(msg.ToolCalls)
+	} else {
+		r = m.renderMessage(i, m.width-2, toolIdx)
+	}
+	used[key] = r
+	return r
+}
+
+// messageHash hashes what a message renders from: its fields, whether its
+// tool calls, numbered from toolIdx, are collapsed or revealed by a search,
+// what the cursor selects in it and whether it is still streaming.
+func (m *Model) messageHash(msg domain.Message, toolIdx, sel int, streaming bool) uint64 {
+	h := fnv.New64a()
+	field := func(s string) {
+		io.WriteString(h, s)
+		h.Write([]byte{0})
+	}
+	field(string(msg.Role))
+	field(msg.Content)
+	field(strconv.FormatInt(msg.Timestamp.UnixNano(), 10))
+	field(strconv.Itoa(toolIdx))
+	field(strconv.Itoa(sel))
+	field(strconv.FormatBool(streaming))
+	for i, tc := range msg.ToolCalls {
+		key := toolKey(tc, toolIdx+i)
+		field(key)
+		field(tc.Name)
+		field(string(tc.Status))
+		field(tc.Summary)
+		field(tc.Command)
+		field(tc.Question)
+		field(strconv.Itoa(len(tc.Choices)))
+		for _, c := range tc.Choices {
+			field(c)
+		}
+		field(tc.FilePath)
+		field(tc.Patch)
+		field(strconv.FormatBool(m.collapsed[key]))
+		field(strconv.FormatBool(m.reveal[key]))
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,208 @@
+package chat
+
+import (
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/ui/theme"
+)
+
+// item is a message, or one of its tool calls, that the cursor can select.
+type item struct {
+	msg  int
+	tool int // index into the message's tool calls, or selMessage
+}
+
+// What the cursor selects in a message: nothing, the message itself, or
+// the tool call at an index from 0.
+const (
+	selNone    = -2
+	selMessage = -1
+)
+
+const cursorMark = "▶ "
+
+var cursorStyle = lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+
+// toolKey identifies a tool call for its folded state: its ID, or its
+// position among the conversation's tool calls when it has none. Keying by
+// ID keeps the state when the history is reloaded or the reply streams.
+func toolKey(tc domain.ToolCall, idx int) string {
+	if tc.ID != "" {
+		return tc.ID
+	}
+	return "#" + strconv.Itoa(idx)
+}
+
+// selection returns what the cursor selects in the message at position i.
+func (m Model) selection(i int) int {
+	if m.cursor == nil || m.cursor.msg != i {
+		return selNone
+	}
+	return m.cursor.tool
+}
+
+// HasCursor reports whether a message or tool call is selected.
+func (m Model) HasCursor() bool {
+	return m.cursor != nil
+}
+
+// SelectedToolCall returns the tool call the cursor is on, if any.
+func (m Model) SelectedToolCall() (domain.ToolCall, bool) {
+	if m.cursor == nil || m.cursor.msg >= len(m.messages) {
+		return domain.ToolCall{}, false
+	}
+	calls := m.messages[m.cursor.msg].ToolCalls
+	if m.cursor.tool < 0 || m.cursor.tool >= len(calls) {
+		return domain.ToolCall{}, false
+	}
+	return calls[m.cursor.tool], true
+}
+
+// updateCursor handles the cursor's keys: J and K select the next and
+// previous message or tool call, o folds or unfolds the selection and Esc
+// drops it. It reports whether it used the key.
+func (m *Model) updateCursor(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "J":
+		m.MoveCursor(1)
+		return true
+	case "K":
+		m.MoveCursor(-1)
+		return true
+	case "o":
+		if m.cursor != nil {
+			m.ToggleCursor()
+			return true
+		}
+	case "esc":
+		if m.cursor != nil {
+			m.cursor = nil
+			m.refresh()
+			return true
+		}
+	}
+	return false
+}
+
+// items lists what the cursor can select, in order.
+func (m Model) items() []item {
+	var out []item
+	for i, msg := range m.messages {
+		out = append(out, item{i, selMessage})
+		for j := range msg.ToolCalls {
+			out = append(out, item{i, j})
+		}
+	}
+	return out
+}
+
+// itemLine returns the first rendered line of an item.
+func (m Model) itemLine(it item) int {
+	if it.msg >= len(m.starts) {
+		return 0
+	}
+	if it.tool >= 0 && it.msg < len(m.toolStarts) && it.tool < len(m.toolStarts[it.msg]) {
+		return m.toolStarts[it.msg][it.tool]
+	}
+	return m.starts[it.msg]
+}
+
+// MoveCursor selects the next item, or the previous one with a negative
+// delta, and scrolls it into view. Without a selection it starts from
+// what is on screen.
+func (m *Model) MoveCursor(delta int) {
+	items := m.items()
+	if len(items) == 0 {
+		return
+	}
+
+	at := -1
+	if m.cursor != nil {
+		for i, it := range items {
+			if it == *m.cursor {
+				at = i
+			}
+		}
+	}
+	if at < 0 {
+		// The first item on screen going down, the last going up
+		top, bottom := m.scroll.offset, m.scroll.offset+m.scroll.height
+		if delta > 0 {
+			at = len(items) - 1
+			for i, it := range items {
+				if m.itemLine(it) >= top {
+					at = i
+					break
+				}
+			}
+		} else {
+			at = 0
+			for i, it := range items {
+				if m.itemLine(it) < bottom {
+					at = i
+				}
+			}
+		}
+	} else {
+		at = min(max(at+delta, 0), len(items)-1)
+	}
+
+	it := items[at]
+	m.cursor = &it
+	m.refresh()
+	m.showLine(m.itemLine(it))
+}
+
+// showLine scrolls just enough to bring a line into view, keeping one line
+// of context above it.
+func (m *Model) showLine(line int) {
+	if line < m.scroll.offset {
+		m.scroll.offset = max(line-1, 0)
+	} else if line >= m.scroll.offset+m.scroll.height {
+		m.scroll.offset = line - 1
+	}
+	m.scroll.clamp()
+}
+
+// ToggleCursor folds or unfolds the selected tool call, or all of the
+// selected message's tool calls.
+func (m *Model) ToggleCursor() {
+	if m.cursor == nil {
+		return
+	}
+	c := *m.cursor
+	if c.tool == selMessage {
+		m.toggleTools(func(i int) bool { return i == c.msg })
+		return
+	}
+	idx := 0
+	for i := 0; i < c.msg; i++ {
+		idx += len(m.messages[i].ToolCalls)
+	}
+	key := toolKey(m.messages[c.msg].ToolCalls[c.tool], idx+c.tool)
+	m.collapsed[key] = !m.collapsed[key]
+	m.refresh()
+}
+
+// clampCursor keeps the selection on an existing item after the messages
+// change, dropping it when the conversation is gone.
+func (m *Model) clampCursor() {
+	if m.cursor == nil {
+		return
+	}
+	if len(m.messages) == 0 {
+		m.cursor = nil
+		return
+	}
+	c := m.cursor
+	if c.msg >= len(m.messages) {
+		c.msg, c.tool = len(m.messages)-1, selMessage
+	}
+	if c.tool >= len(m.messages[c.msg].ToolCalls) {
+		c.tool = selMessage
+	}
+}
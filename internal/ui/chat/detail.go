@@ -0,0 +1,139 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/ui/theme"
+)
+
+var (
+	detailTitleStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	detailSectionStyle = lipgloss.NewStyle().Foreground(theme.Subtle).Bold(true)
+)
+
+// Detail returns the selected tool call, or message with its tool calls,
+// in full for a pager: a title and the complete text, with nothing cut
+// short, wrapped to width. It reports false when nothing is selected.
+func (m Model) Detail(width int) (title, body string, ok bool) {
+	if m.cursor == nil || m.cursor.msg >= len(m.messages) {
+		return "", "", false
+	}
+	msg := m.messages[m.cursor.msg]
+	if m.cursor.tool >= 0 && m.cursor.tool < len(msg.ToolCalls) {
+		tc := msg.ToolCalls[m.cursor.tool]
+		return toolTitle(tc), toolDetail(tc, width), true
+	}
+
+	who := "Copilot"
+	switch msg.Role {
+	case domain.RoleUser:
+		who = "You"
+	case domain.RoleSystem, domain.RoleTool:
+		who = "Note"
+	}
+	title = who
+	if !msg.Timestamp.IsZero() {
+		title += " · " + msg.Timestamp.Format("2006-01-02 15:04:05")
+	}
+
+	var sb strings.Builder
+	if content := strings.TrimSpace(msg.Content); content != "" {
+		sb.WriteString(wrap(content, width))
+		sb.WriteString("\n")
+	}
+	for _, tc := range msg.ToolCalls {
+		sb.WriteString("\n" + detailTitleStyle.Render(toolTitle(tc)) + "\n")
+		sb.WriteString(toolDetail(tc, width))
+	}
+	return title, sb.String(), true
+}
+
+// toolTitle names a tool call and its outcome.
+func toolTitle(tc domain.ToolCall) string {
+	title := fmt.Sprintf("%s %s", toolEmoji(tc.Name), tc.Name)
+	switch tc.Status {
+	case domain.ToolCallComplete:
+		title += " ✓"
+	case domain.ToolCallFailed:
+		title += " ✗ failed"
+	case domain.ToolCallRunning, domain.ToolCallPending:
+		title += " ⏳ " + string(tc.Status)
+	}
+	if !tc.StartedAt.IsZero() {
+		title += " · " + tc.StartedAt.Format("15:04:05")
+	}
+	return title
+}
+
+// toolDetail lays out everything known about a tool call, one section per
+// field.
+func toolDetail(tc domain.ToolCall, width int) string {
+	var sb strings.Builder
+	section := func(name, text string) {
+		sb.WriteString("\n" + detailSectionStyle.Render("── "+name+" ──") + "\n")
+		sb.WriteString(text)
+		sb.WriteString("\n")
+	}
+
+	if tc.Command != "" {
+		section("Command", wrap("$ "+tc.Command, width))
+	}
+	if tc.FilePath != "" {
+		section("File", tc.FilePath)
+	}
+	if tc.Question != "" {
+		q := wrap(tc.Question, width)
+		for i, c := range tc.Choices {
+			q += "\n" + wrap(fmt.Sprintf("[%d] %s", i+1, c), width)
+		}
+		section("Question", q)
+	}
+	if args := indentJSON(tc.Args); args != "" {
+		section("Arguments", wrap(args, width))
+	}
+	if tc.Summary != "" {
+		name := "Output"
+		if tc.Name == "ask_user" {
+			name = "Answer"
+		}
+		section(name, wrap(tc.Summary, width))
+	}
+	if tc.Patch != "" {
+		lines := strings.Split(tc.Patch, "\n")
+		for i, line := range lines {
+			lines[i] = patchLine(line)
+		}
+		section("Patch", wrap(strings.Join(lines, "\n"), width))
+	}
+	if sb.Len() == 0 {
+		sb.WriteString("\n" + detailSectionStyle.Render("No details") + "\n")
+	}
+	return sb.String()
+}
+
+// indentJSON pretty-prints JSON arguments, leaving anything else as it is.
+// Empty objects are dropped.
+func indentJSON(s string) string {
+	if s == "" || s == "{}" || s == "null" {
+		return ""
+	}
+	var buf bytes.Buffer
+	if json.Indent(&buf, []byte(s), "", "  ") != nil {
+		return s
+	}
+	return buf.String()
+}
+
+// wrap breaks long lines at width, keeping the text's own line breaks.
+func wrap(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	return lipgloss.NewStyle().Width(width).Render(s)
+}
@@ -3,8 +3,8 @@ package chat
 import (
 	"fmt"
 	"strings"
+	"time"
 
-	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
@@ -22,54 +22,108 @@ type PendingTool struct {
 
 // Model represents the chat panel showing conversation history.
 type Model struct {
-	viewport     viewport.Model
+	scroll       scroller
 	messages     []domain.Message
 	width        int
 	height       int
 	ready        bool
 	mdRender     *glamour.TermRenderer
-	collapsed    map[int]bool // tool call indices collapsed state
+	collapsed    map[string]bool // tool call key → folded, see toolKey
+	reveal       map[string]bool // tool calls shown in full because they match the search
 	pendingTools []PendingTool
+	streaming    bool    // the last message is a reply still streaming in, shown as plain text
+	search       *search // open / search, see search.go
+	cursor       *item   // selected message or tool call, see cursor.go
+	starts       []int   // first rendered line of each message
+	toolStarts   [][]int // first rendered line of each message's tool calls
+
+	cache map[renderKey]rendered // rendered messages, see cache.go
+	// prefixLines lines hold all messages but the last, so a streamed
+	// token only re-renders the message it belongs to. They are valid
+	// while prefixN and prefixW match the messages and width.
+	prefixLines int
+	prefixN     int
+	prefixW     int
+	prefixTools int // tool calls before the last message
 }
 
 // New creates a new chat panel.
 func New(width, height int) Model {
-	vp := viewport.New(width, height)
-	vp.SetContent("")
-
 	r, _ := glamour.NewTermRenderer(
 		glamour.WithAutoStyle(),
 		glamour.WithWordWrap(width-4),
 	)
 
 	return Model{
-		viewport:  vp,
+		scroll:    newScroller(width, height),
 		width:     width,
 		height:    height,
 		ready:     true,
 		mdRender:  r,
-		collapsed: make(map[int]bool),
+		collapsed: make(map[string]bool),
+		reveal:    make(map[string]bool),
+		prefixN:   -1,
 	}
 }
 
-// SetSize updates the chat panel dimensions.
+// SetSize updates the chat panel dimensions. A new width re-renders the
+// messages; what was rendered at the old width stays cached until the next
+// full render.
 func (m *Model) SetSize(w, h int) {
-	m.width = w
 	m.height = h
-	m.viewport.Width = w
-	m.viewport.Height = h
+	m.scroll.width = w
+	m.layout()
+	if w == m.width && m.mdRender != nil {
+		return
+	}
+	m.width = w
 	m.mdRender, _ = glamour.NewTermRenderer(
 		glamour.WithAutoStyle(),
 		glamour.WithWordWrap(w-4),
 	)
+	if len(m.messages) > 0 {
+		m.refresh()
+	}
+}
+
+// layout gives the scroller the panel's height, less a line for the search
+// bar while a search is open.
+func (m *Model) layout() {
+	h := m.height
+	if m.search != nil {
+		h--
+	}
+	m.scroll.height = max(h, 1)
+	m.scroll.clamp()
 }
 
-// SetMessages replaces the displayed messages and re-renders.
+// Width returns the chat panel width.
+func (m Model) Width() int {
+	return m.width
+}
+
+// SetMessages replaces the displayed messages and re-renders. Folded tool
+// calls stay folded, as their state is kept by tool call ID; call Reset
+// first when the messages are another conversation's.
 func (m *Model) SetMessages(msgs []domain.Message) {
 	m.messages = msgs
-	m.collapsed = make(map[int]bool) // reset collapsed state
-	m.viewport.SetContent(m.renderMessages())
-	m.viewport.GotoBottom()
+	m.streaming = false
+	m.clampCursor()
+	if m.search != nil {
+		m.revealMatches()
+	}
+	m.refresh()
+	m.scroll.gotoBottom()
+}
+
+// Reset forgets which tool calls are folded or revealed by the search and
+// drops the cursor, before another conversation is shown. Calls without an
+// ID are keyed by position, so their folds would land on the wrong calls.
+func (m *Model) Reset() {
+	m.collapsed = make(map[string]bool)
+	m.reveal = make(map[string]bool)
+	m.cursor = nil
+	m.refresh()
 }
 
 // Messages returns the current messages for export.
@@ -80,57 +134,139 @@ func (m Model) Messages() []domain.Message {
 // AppendMessages adds new messages and scrolls to bottom.
 func (m *Model) AppendMessages(msgs []domain.Message) {
 	m.messages = append(m.messages, msgs...)
-	m.viewport.SetContent(m.renderMessages())
-	m.viewport.GotoBottom()
+	m.refresh()
+	m.scroll.gotoBottom()
+}
+
+// AppendDelta adds streamed text to the reply in progress, starting a new
+// reply if the last message is not one. Only the reply is re-rendered, and
+// as plain text: markdown is rendered once, when the reply is done.
+func (m *Model) AppendDelta(delta string) {
+	m.streaming = true
+	if n := len(m.messages); n > 0 && m.messages[n-1].Role == domain.RoleAssistant {
+		m.messages[n-1].Content += delta
+	} else {
+		m.messages = append(m.messages, domain.Message{Role: domain.RoleAssistant, Content: delta, Timestamp: time.Now()})
+	}
+	m.refreshTail()
+	m.scroll.gotoBottom()
+}
+
+// SetReply sets the whole text of the reply in progress, or adds it as a
+// new message if the last message is not a reply, and ends its streaming.
+func (m *Model) SetReply(content string) {
+	m.streaming = false
+	if n := len(m.messages); n > 0 && m.messages[n-1].Role == domain.RoleAssistant {
+		m.messages[n-1].Content = content
+	} else {
+		m.messages = append(m.messages, domain.Message{Role: domain.RoleAssistant, Content: content, Timestamp: time.Now()})
+	}
+	m.refreshTail()
+	m.scroll.gotoBottom()
+}
+
+// EndReply renders a reply left streaming as markdown, e.g. when the turn
+// ends without a final reply because it was aborted.
+func (m *Model) EndReply() {
+	if !m.streaming {
+		return
+	}
+	m.streaming = false
+	m.refreshTail()
 }
 
 // SetPendingTools updates the pending tool list and re-renders.
 func (m *Model) SetPendingTools(tools []PendingTool) {
 	m.pendingTools = tools
-	m.viewport.SetContent(m.renderMessages())
-	m.viewport.GotoBottom()
+	m.refreshTail()
+	m.scroll.gotoBottom()
 }
 
 // ToggleAllToolCalls toggles collapse state for all tool calls.
 func (m *Model) ToggleAllToolCalls() {
-	// Count total tool calls
-	total := 0
-	for _, msg := range m.messages {
-		total += len(msg.ToolCalls)
+	m.toggleTools(func(int) bool { return true })
+}
+
+// toggleTools collapses the tool calls of the messages keep selects if any
+// of them is expanded, and expands them all otherwise.
+func (m *Model) toggleTools(keep func(msg int) bool) {
+	var keys []string
+	idx := 0
+	for i, msg := range m.messages {
+		for _, tc := range msg.ToolCalls {
+			if keep(i) {
+				keys = append(keys, toolKey(tc, idx))
+			}
+			idx++
+		}
 	}
-	if total == 0 {
+	if len(keys) == 0 {
 		return
 	}
 
-	// If any are expanded, collapse all; otherwise expand all
 	anyExpanded := false
-	for i := 0; i < total; i++ {
-		if !m.collapsed[i] {
+	for _, k := range keys {
+		if !m.collapsed[k] {
 			anyExpanded = true
 			break
 		}
 	}
-
-	for i := 0; i < total; i++ {
-		m.collapsed[i] = anyExpanded
+	for _, k := range keys {
+		m.collapsed[k] = anyExpanded
 	}
 
-	m.viewport.SetContent(m.renderMessages())
+	m.refresh()
 }
 
-// Update handles viewport messages (scrolling).
+// Update handles scrolling, the cursor and, while one is open, the search.
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
-	var cmd tea.Cmd
-	m.viewport, cmd = m.viewport.Update(msg)
-	return m, cmd
+	if key, ok := msg.(tea.KeyMsg); ok {
+		if m.search != nil {
+			if cmd, used := m.updateSearch(key); used {
+				return m, cmd
+			}
+		}
+		if m.updateCursor(key) {
+			return m, nil
+		}
+	}
+	m.scroll = m.scroll.update(msg)
+	return m, nil
 }
 
 // View renders the chat panel.
 func (m Model) View() string {
-	return m.viewport.View()
+	if m.search == nil {
+		return m.scroll.view(nil)
+	}
+	return m.scroll.view(m.highlight) + "\n" + m.searchBar()
 }
 
-func (m Model) renderMessages() string {
+// refresh re-renders every message, taking unchanged ones from the cache.
+func (m *Model) refresh() {
+	m.scroll.setContent(m.renderMessages())
+	m.findMatches()
+}
+
+// refreshTail re-renders only the last message and the pending tools after
+// it, keeping the lines of the messages before it.
+func (m *Model) refreshTail() {
+	n := len(m.messages)
+	if n == 0 || m.prefixN != n-1 || m.prefixW != m.width {
+		m.refresh()
+		return
+	}
+	toolIdx := m.prefixTools
+	r := m.renderMessage(n-1, m.width-2, &toolIdx)
+	m.toolStarts[n-1] = r.toolLines(m.prefixLines)
+	m.scroll.replaceFrom(m.prefixLines, r.text+"\n"+m.renderPendingTools())
+	m.findMatches()
+}
+
+// renderMessages renders every message, taking unchanged ones from the
+// cache, and records where the last one starts for refreshTail.
+func (m *Model) renderMessages() string {
+	m.prefixN = -1
 	if len(m.messages) == 0 && len(m.pendingTools) == 0 {
 		return lipgloss.NewStyle().
 			Foreground(theme.Subtle).
@@ -138,13 +274,31 @@ func (m Model) renderMessages() string {
 	}
 
 	var sb strings.Builder
-	toolIdx := 0
-	for _, msg := range m.messages {
-		sb.WriteString(m.renderMessage(msg, m.width-2, &toolIdx))
+	used := make(map[renderKey]rendered, len(m.messages))
+	toolIdx, line := 0, 0
+	m.starts = m.starts[:0]
+	m.toolStarts = m.toolStarts[:0]
+	for i := range m.messages {
+		m.starts = append(m.starts, line)
+		if i == len(m.messages)-1 {
+			m.prefixLines = line
+			m.prefixN, m.prefixW, m.prefixTools = i, m.width, toolIdx
+		}
+		r := m.cachedMessage(i, &toolIdx, used)
+		m.toolStarts = append(m.toolStarts, r.toolLines(line))
+		sb.WriteString(r.text)
 		sb.WriteString("\n")
+		line += strings.Count(r.text, "\n") + 1
 	}
+	m.cache = used
 
-	// Render pending tools from preToolUse hooks
+	sb.WriteString(m.renderPendingTools())
+	return sb.String()
+}
+
+// renderPendingTools renders the tools waiting on preToolUse hooks.
+func (m *Model) renderPendingTools() string {
+	var sb strings.Builder
 	for _, pt := range m.pendingTools {
 		if pt.Denied {
 			sb.WriteString(lipgloss.NewStyle().Foreground(theme.Error).Bold(true).
@@ -189,47 +343,77 @@ var (
 			Foreground(lipgloss.Color("214"))
 
 	contentStyle = lipgloss.NewStyle()
+
+	// streamStyle indents a streaming reply as glamour will
+	streamStyle = lipgloss.NewStyle().PaddingLeft(2)
 )
 
-func (m Model) renderMessage(msg domain.Message, maxWidth int, toolIdx *int) string {
+// streamingAt reports whether the message at position i is a reply still
+// streaming in.
+func (m Model) streamingAt(i int) bool {
+	return m.streaming && i == len(m.messages)-1
+}
+
+// renderMessage renders the message at position i. Its tool calls are
+// numbered from toolIdx, which is advanced past them.
+func (m Model) renderMessage(i int, maxWidth int, toolIdx *int) rendered {
+	msg := m.messages[i]
+	sel := m.selection(i)
 	var sb strings.Builder
+	var tools []int
 	ts := timestampStyle.Render(msg.Timestamp.Format("15:04"))
+	mark := ""
+	if sel == selMessage {
+		mark = cursorStyle.Render(cursorMark)
+	}
 
 	switch msg.Role {
 	case domain.RoleUser:
 		label := userLabelStyle.Render("You")
-		sb.WriteString(fmt.Sprintf("%s %s\n", label, ts))
+		sb.WriteString(fmt.Sprintf("%s%s %s\n", mark, label, ts))
 		sb.WriteString(contentStyle.Width(maxWidth).Render(msg.Content))
 
 	case domain.RoleAssistant:
 		label := assistantLabelStyle.Render("Copilot")
-		sb.WriteString(fmt.Sprintf("%s %s\n", label, ts))
+		sb.WriteString(fmt.Sprintf("%s%s %s\n", mark, label, ts))
 		if msg.Content != "" {
 			content := strings.TrimSpace(msg.Content)
-			if content != "" {
+			if content != "" && m.streamingAt(i) {
+				// Glamour is too slow to run on every token; lay the
+				// text out as it will, under a blank line
+				sb.WriteString("\n" + streamStyle.Width(maxWidth).Render(content))
+				if len(msg.ToolCalls) > 0 {
+					sb.WriteString("\n")
+				}
+			} else if content != "" {
 				rendered := m.renderMarkdown(content)
 				if rendered != "" {
 					sb.WriteString(rendered)
+					// Tool calls start on a line of their own
+					if len(msg.ToolCalls) > 0 {
+						sb.WriteString("\n")
+					}
 				} else {
 					sb.WriteString(contentStyle.Width(maxWidth).Render(content))
 					sb.WriteString("\n")
 				}
 			}
 		}
-		for _, tc := range msg.ToolCalls {
-			sb.WriteString(m.renderToolCall(tc, *toolIdx))
+		for j, tc := range msg.ToolCalls {
+			tools = append(tools, strings.Count(sb.String(), "\n"))
+			sb.WriteString(m.renderToolCall(tc, toolKey(tc, *toolIdx), sel == j))
 			sb.WriteString("\n")
 			*toolIdx++
 		}
 
 	case domain.RoleSystem:
-		sb.WriteString(fmt.Sprintf("%s %s\n", systemLabelStyle.Render("ℹ "+msg.Content), ts))
+		sb.WriteString(fmt.Sprintf("%s%s %s\n", mark, systemLabelStyle.Render("ℹ "+msg.Content), ts))
 
 	case domain.RoleTool:
 		sb.WriteString(fmt.Sprintf("  %s\n", toolCallStyle.Render(msg.Content)))
 	}
 
-	return sb.String()
+	return rendered{text: sb.String(), tools: tools}
 }
 
 // renderMarkdown renders markdown content using Glamour.
@@ -244,7 +428,39 @@ func (m Model) renderMarkdown(content string) string {
 	return strings.TrimRight(out, "\n")
 }
 
-func (m Model) renderToolCall(tc domain.ToolCall, idx int) string {
+// toolEmoji marks what kind of tool a call is.
+func toolEmoji(name string) string {
+	switch name {
+	case "ask_user":
+		return "❓"
+	case "edit", "create", "apply_patch":
+		return "📝"
+	}
+	return "🔧"
+}
+
+var (
+	addStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("114"))
+	delStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("210"))
+	hunkStyle = lipgloss.NewStyle().Foreground(theme.Subtle)
+)
+
+// patchLine colors a line of a patch by what it does.
+func patchLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+"):
+		return addStyle.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return delStyle.Render(line)
+	case strings.HasPrefix(line, "***") || strings.HasPrefix(line, "@@"):
+		return hunkStyle.Render(line)
+	}
+	return line
+}
+
+// renderToolCall renders a tool call, folded or not according to its key,
+// with the cursor's mark when selected.
+func (m Model) renderToolCall(tc domain.ToolCall, key string, selected bool) string {
 	var icon string
 	switch tc.Status {
 	case domain.ToolCallComplete:
@@ -259,19 +475,18 @@ func (m Model) renderToolCall(tc domain.ToolCall, idx int) string {
 		icon = "…"
 	}
 
-	collapsed := m.collapsed[idx]
+	revealed := m.reveal[key]
+	collapsed := m.collapsed[key] && !revealed
 	chevron := "▸" // collapsed
 	if !collapsed {
 		chevron = "▾" // expanded
 	}
 
-	header := toolCallStyle.Render(fmt.Sprintf("  %s 🔧 %s %s", chevron, tc.Name, icon))
-	if tc.Name == "ask_user" {
-		header = toolCallStyle.Render(fmt.Sprintf("  %s ❓ %s %s", chevron, tc.Name, icon))
-	}
-	if tc.Name == "edit" || tc.Name == "create" || tc.Name == "apply_patch" {
-		header = toolCallStyle.Render(fmt.Sprintf("  %s 📝 %s %s", chevron, tc.Name, icon))
+	lead := "  "
+	if selected {
+		lead = cursorStyle.Render(cursorMark)
 	}
+	header := lead + toolCallStyle.Render(fmt.Sprintf("%s %s %s %s", chevron, toolEmoji(tc.Name), tc.Name, icon))
 
 	// For pending/running tools, always show the command if available
 	isPending := tc.Status == domain.ToolCallPending || tc.Status == domain.ToolCallRunning
@@ -306,7 +521,7 @@ func (m Model) renderToolCall(tc domain.ToolCall, idx int) string {
 			waitingHint := lipgloss.NewStyle().
 				Foreground(theme.Warning).
 				Bold(true).
-				Render("    ⚡ Respond in terminal")
+				Render("    ⚡ Waiting for your answer")
 			return header + "\n" + detail.String() + waitingHint
 		}
 		if tc.Summary != "" {
@@ -322,24 +537,19 @@ func (m Model) renderToolCall(tc domain.ToolCall, idx int) string {
 		var detail strings.Builder
 		detail.WriteString("    " + pathStyle.Render(tc.FilePath) + "\n")
 		if !collapsed && tc.Patch != "" {
-			addStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("114"))
-			delStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("210"))
 			patchLines := strings.Split(tc.Patch, "\n")
 			maxLines := 12
+			if revealed {
+				maxLines = len(patchLines)
+			}
 			shown := 0
 			for _, line := range patchLines {
 				if shown >= maxLines {
 					detail.WriteString("    " + lipgloss.NewStyle().Foreground(theme.Subtle).Render(fmt.Sprintf("... +%d more lines", len(patchLines)-shown)) + "\n")
 					break
 				}
-				if strings.HasPrefix(line, "+") {
-					detail.WriteString("    " + addStyle.Render(line) + "\n")
-				} else if strings.HasPrefix(line, "-") {
-					detail.WriteString("    " + delStyle.Render(line) + "\n")
-				} else if strings.HasPrefix(line, "***") || strings.HasPrefix(line, "@@") {
-					detail.WriteString("    " + lipgloss.NewStyle().Foreground(theme.Subtle).Render(line) + "\n")
-				} else if strings.TrimSpace(line) != "" {
-					detail.WriteString("    " + line + "\n")
+				if strings.TrimSpace(line) != "" {
+					detail.WriteString("    " + patchLine(line) + "\n")
 				}
 				shown++
 			}
@@ -358,6 +568,10 @@ func (m Model) renderToolCall(tc domain.ToolCall, idx int) string {
 		return header
 	}
 
+	// A search hit in the command or summary shows them in full
+	if revealed && tc.Command != "" {
+		header += "\n" + lipgloss.NewStyle().Foreground(theme.Subtle).Render("    $ "+tc.Command)
+	}
 	if tc.Summary == "" {
 		return header
 	}
@@ -365,7 +579,7 @@ func (m Model) renderToolCall(tc domain.ToolCall, idx int) string {
 	// Expanded: show summary (truncated to 3 lines max)
 	summary := tc.Summary
 	lines := strings.Split(summary, "\n")
-	if len(lines) > 3 {
+	if len(lines) > 3 && !revealed {
 		summary = strings.Join(lines[:3], "\n") + "\n    ..."
 	}
 	detail := lipgloss.NewStyle().Foreground(theme.Subtle).Render("    " + strings.ReplaceAll(summary, "\n", "\n    "))
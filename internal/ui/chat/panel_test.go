@@ -0,0 +1,306 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+func history(n int) []domain.Message {
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	var msgs []domain.Message
+	for i := 0; i < n; i++ {
+		msgs = append(msgs,
+			domain.Message{Role: domain.RoleUser, Content: fmt.Sprintf("question %d", i), Timestamp: at},
+			domain.Message{Role: domain.RoleAssistant, Timestamp: at, Content: fmt.Sprintf(
+				"## Answer %d\n\nSome **markdown** with `code` and a list:\n\n- one\n- two\n\n```go\nfmt.Println(%d)\n```", i, i),
+				ToolCalls: []domain.ToolCall{{Name: "bash", Status: domain.ToolCallComplete, Summary: "ok"}},
+			},
+		)
+	}
+	return msgs
+}
+
+// fullRender renders msgs from scratch, without any cache, with the last
+// one still streaming if streaming is set.
+func fullRender(width int, msgs []domain.Message, collapsed map[string]bool, streaming bool) string {
+	m := New(width, 20)
+	m.messages = append([]domain.Message(nil), msgs...)
+	m.streaming = streaming
+	for k, v := range collapsed {
+		m.collapsed[k] = v
+	}
+	return m.renderMessages()
+}
+
+func TestStreamingMatchesFullRender(t *testing.T) {
+	m := New(80, 20)
+	m.SetMessages(history(3))
+	m.AppendMessages([]domain.Message{{Role: domain.RoleUser, Content: "and now?"}})
+	for _, d := range []string{"Stream", "ing **bold", "** text\n\n- a", "\n- b"} {
+		m.AppendDelta(d)
+	}
+	if got, want := strings.Join(m.scroll.lines, "\n"), fullRender(80, m.messages, nil, true); got != want {
+		t.Fatalf("streamed render differs from a full render:\n%s\n---\n%s", got, want)
+	}
+
+	m.SetReply("Final")
+	if got, want := strings.Join(m.scroll.lines, "\n"), fullRender(80, m.messages, nil, false); got != want {
+		t.Error("the final reply differs from a full render")
+	}
+	if last := m.messages[len(m.messages)-1]; last.Content != "Final" || len(m.messages) != 8 {
+		t.Errorf("messages = %d, last = %+v", len(m.messages), last)
+	}
+}
+
+func TestMarkdownWaitsForTheEndOfAReply(t *testing.T) {
+	m := New(80, 20)
+	m.SetMessages(history(1))
+	m.AppendMessages([]domain.Message{{Role: domain.RoleUser, Content: "test it"}})
+	m.AppendDelta("run `go test`")
+	view := ansi.Strip(strings.Join(m.scroll.lines, "\n"))
+	if !strings.Contains(view, "  run `go test`") {
+		t.Errorf("a streaming reply should show as plain text:\n%s", view)
+	}
+
+	// An aborted turn ends without a final reply
+	m.EndReply()
+	view = ansi.Strip(strings.Join(m.scroll.lines, "\n"))
+	if strings.Contains(view, "`") || !strings.Contains(view, "run go test") {
+		t.Errorf("a finished reply should be rendered as markdown:\n%s", view)
+	}
+	if got, want := strings.Join(m.scroll.lines, "\n"), fullRender(80, m.messages, nil, false); got != want {
+		t.Error("the finished reply differs from a full render")
+	}
+}
+
+func TestCacheFollowsWidthAndCollapse(t *testing.T) {
+	m := New(80, 20)
+	m.SetMessages(history(3))
+	if len(m.cache) != 6 {
+		t.Fatalf("cache has %d entries, want one per message", len(m.cache))
+	}
+
+	m.SetSize(50, 20)
+	for k := range m.cache {
+		if k.width != 50 {
+			t.Errorf("entry for width %d survived the resize", k.width)
+		}
+	}
+	if got := m.renderMessages(); got != fullRender(50, m.messages, nil, false) {
+		t.Error("after a resize the render differs from a full render")
+	}
+
+	m.ToggleAllToolCalls()
+	want := fullRender(50, m.messages, map[string]bool{"#0": true, "#1": true, "#2": true}, false)
+	if got := m.renderMessages(); got != want {
+		t.Error("collapsing tool calls should not reuse expanded renders")
+	}
+	if !strings.Contains(want, "▸") {
+		t.Error("collapsed tool calls should show ▸")
+	}
+}
+
+func TestScroller(t *testing.T) {
+	s := newScroller(10, 3)
+	s.setContent("1\n2\n3\n4\n5")
+	s.gotoBottom()
+	if got := strings.Fields(s.view(nil)); strings.Join(got, ",") != "3,4,5" {
+		t.Errorf("bottom shows %v", got)
+	}
+	s = s.update(tea.KeyMsg{Type: tea.KeyUp})
+	s = s.update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelUp})
+	if s.offset != 0 {
+		t.Errorf("offset = %d, want the top", s.offset)
+	}
+
+	s.replaceFrom(1, "x")
+	if strings.Join(s.lines, ",") != "1,x" || s.offset != 0 {
+		t.Errorf("lines = %v, offset %d", s.lines, s.offset)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	m := New(80, 10)
+	msgs := history(20)
+	msgs[3].ToolCalls = []domain.ToolCall{{
+		Name: "bash", Status: domain.ToolCallComplete, Command: "grep -r needle .",
+		Summary: "line 1\nline 2\nline 3\nline 4 has the Needle",
+	}}
+	m.SetMessages(msgs)
+	m.collapsed["#1"] = true
+	m.refresh()
+	m.scroll.gotoBottom()
+	bottom := m.scroll.offset
+
+	key := func(s string) tea.KeyMsg {
+		switch s {
+		case "enter":
+			return tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			return tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+	m.StartSearch()
+	if m.scroll.height != 9 {
+		t.Errorf("scroll height = %d with the search bar open, want 9", m.scroll.height)
+	}
+	for _, r := range "needle" {
+		m, _ = m.Update(key(string(r)))
+	}
+
+	// The hits are in a collapsed tool call: its command and the summary
+	// line past the three shown are both revealed
+	if len(m.search.matches) != 2 {
+		t.Fatalf("matches = %+v, want the command and the fourth summary line", m.search.matches)
+	}
+	first := m.search.matches[0].line
+	if got := ansi.Strip(m.scroll.lines[first]); !strings.Contains(got, "$ grep -r needle .") {
+		t.Errorf("first match on %q", got)
+	}
+	if m.scroll.offset > first || first >= m.scroll.offset+m.scroll.height {
+		t.Errorf("offset %d does not show line %d", m.scroll.offset, first)
+	}
+	if view := m.View(); !strings.Contains(view, currentStyle.Render("needle")) || !strings.Contains(view, "1/2") {
+		t.Errorf("view lacks the highlighted match or its position:\n%s", view)
+	}
+
+	m, _ = m.Update(key("enter"))
+	m, _ = m.Update(key("n"))
+	m, _ = m.Update(key("n"))
+	if m.search.current != 0 {
+		t.Errorf("n should wrap around, current = %d", m.search.current)
+	}
+	m, _ = m.Update(key("N"))
+	if m.search.current != 1 {
+		t.Errorf("N should wrap back, current = %d", m.search.current)
+	}
+
+	m, _ = m.Update(key("/"))
+	m, _ = m.Update(key("esc"))
+	if m.HasSearch() || m.scroll.offset != bottom || m.scroll.height != 10 {
+		t.Errorf("Esc while editing should close and restore: open %v, offset %d, height %d",
+			m.HasSearch(), m.scroll.offset, m.scroll.height)
+	}
+	if strings.Contains(strings.Join(m.scroll.lines, "\n"), "Needle") {
+		t.Error("closing the search should fold the revealed tool call again")
+	}
+}
+
+func TestToolCallCursor(t *testing.T) {
+	m := New(80, 20)
+	msgs := history(3)
+	for i := 1; i < len(msgs); i += 2 {
+		msgs[i].ToolCalls[0].ID = fmt.Sprintf("call-%d", i)
+	}
+	msgs[3].ToolCalls[0].Summary = "line 1\nline 2\nline 3\nline 4\nline 5"
+	m.SetMessages(msgs)
+	m.scroll.offset = 0
+
+	// The cursor starts on the first message on screen, then steps through
+	// every message and tool call
+	for i := 0; i < 6; i++ {
+		m.MoveCursor(1)
+	}
+	if *m.cursor != (item{3, 0}) {
+		t.Fatalf("cursor = %+v, want the second reply's tool call", *m.cursor)
+	}
+	if line := ansi.Strip(m.scroll.lines[m.itemLine(*m.cursor)]); !strings.HasPrefix(line, "▶ ▾") {
+		t.Errorf("selected tool call renders as %q", line)
+	}
+
+	// The detail has what the chat cuts short
+	title, body, ok := m.Detail(60)
+	if !ok || !strings.Contains(title, "bash") || !strings.Contains(body, "line 5") {
+		t.Errorf("detail = %q, %q, %v", title, body, ok)
+	}
+
+	// Folding survives a reload and streaming, as it is kept by tool call ID
+	m.ToggleCursor()
+	m.SetMessages(append([]domain.Message(nil), msgs...))
+	m.AppendDelta("more")
+	if !m.collapsed["call-3"] || !strings.Contains(ansi.Strip(m.scroll.lines[m.itemLine(item{3, 0})]), "▸") {
+		t.Error("the folded tool call should stay folded")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.HasCursor() {
+		t.Error("Esc should drop the cursor")
+	}
+	m.AppendDelta("!")
+	if got := strings.Join(m.scroll.lines, "\n"); got != fullRender(80, m.messages, map[string]bool{"call-3": true}, true) {
+		t.Error("the render with a folded call differs from a full render")
+	}
+
+	m.cursor = &item{3, selMessage}
+	m.ToggleCursor()
+	if m.collapsed["call-3"] {
+		t.Error("o on a message should unfold its folded tool calls")
+	}
+}
+
+func TestResetForgetsFoldsAndCursor(t *testing.T) {
+	m := New(80, 20)
+	m.SetMessages(history(2))
+	m.ToggleAllToolCalls()
+	m.MoveCursor(1)
+	if !m.collapsed["#0"] || !m.HasCursor() {
+		t.Fatal("the calls should be folded and one item selected")
+	}
+
+	m.Reset()
+	m.SetMessages(history(2))
+	if len(m.collapsed) != 0 || len(m.reveal) != 0 || m.HasCursor() {
+		t.Errorf("collapsed = %v, reveal = %v, cursor = %v", m.collapsed, m.reveal, m.cursor)
+	}
+	if got := strings.Join(m.scroll.lines, "\n"); got != fullRender(80, m.messages, nil, false) {
+		t.Error("after a reset the render differs from a full render")
+	}
+}
+
+// BenchmarkAppendDelta streams tokens into a reply after histories of
+// different lengths. Only the reply is rendered and split into lines per
+// token, so the time per token should not grow with the history.
+func BenchmarkAppendDelta(b *testing.B) {
+	for _, n := range []int{10, 100, 500} {
+		b.Run(fmt.Sprintf("history=%d", n), func(b *testing.B) {
+			base := history(n)
+			m := New(100, 40)
+			m.SetMessages(append([]domain.Message(nil), base...))
+			i := 0
+			for b.Loop() {
+				// Start a fresh reply now and then so the reply, the part
+				// that is rendered, stays the same size across runs
+				if i%50 == 0 {
+					b.StopTimer()
+					m.SetMessages(append(append([]domain.Message(nil), base...), domain.Message{Role: domain.RoleUser, Content: "next"}))
+					b.StartTimer()
+				}
+				m.AppendDelta("token ")
+				i++
+			}
+		})
+	}
+}
+
+// BenchmarkFullRender is the per-token cost without the cache, for
+// comparison with BenchmarkAppendDelta.
+func BenchmarkFullRender(b *testing.B) {
+	for _, n := range []int{10, 100} {
+		b.Run(fmt.Sprintf("history=%d", n), func(b *testing.B) {
+			msgs := history(n)
+			m := New(100, 40)
+			m.messages = msgs
+			for b.Loop() {
+				m.cache = nil
+				m.renderMessages()
+			}
+		})
+	}
+}
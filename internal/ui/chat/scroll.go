@@ -0,0 +1,109 @@
+package chat
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// wheelDelta is how many lines a mouse wheel step scrolls.
+const wheelDelta = 3
+
+// scroller is a vertical viewport over rendered lines. Unlike the bubbles
+// viewport it does not measure every line when the content changes, and
+// the lines of the message being streamed can be replaced on their own.
+type scroller struct {
+	keys   viewport.KeyMap
+	lines  []string
+	offset int
+	width  int
+	height int
+}
+
+func newScroller(width, height int) scroller {
+	return scroller{keys: viewport.DefaultKeyMap(), width: width, height: height}
+}
+
+// setContent replaces all lines.
+func (s *scroller) setContent(content string) {
+	s.lines = strings.Split(content, "\n")
+	s.clamp()
+}
+
+// replaceFrom keeps the first n lines and replaces the rest with content.
+func (s *scroller) replaceFrom(n int, content string) {
+	s.lines = append(s.lines[:n], strings.Split(content, "\n")...)
+	s.clamp()
+}
+
+func (s *scroller) maxOffset() int {
+	return max(0, len(s.lines)-s.height)
+}
+
+func (s *scroller) clamp() {
+	s.offset = min(max(s.offset, 0), s.maxOffset())
+}
+
+func (s *scroller) scroll(n int) {
+	s.offset += n
+	s.clamp()
+}
+
+func (s *scroller) gotoBottom() {
+	s.offset = s.maxOffset()
+}
+
+// update scrolls on the bubbles viewport's keys and on the mouse wheel.
+func (s scroller) update(msg tea.Msg) scroller {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, s.keys.PageDown):
+			s.scroll(s.height)
+		case key.Matches(msg, s.keys.PageUp):
+			s.scroll(-s.height)
+		case key.Matches(msg, s.keys.HalfPageDown):
+			s.scroll(s.height / 2)
+		case key.Matches(msg, s.keys.HalfPageUp):
+			s.scroll(-s.height / 2)
+		case key.Matches(msg, s.keys.Down):
+			s.scroll(1)
+		case key.Matches(msg, s.keys.Up):
+			s.scroll(-1)
+		}
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionPress {
+			break
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelDown:
+			s.scroll(wheelDelta)
+		case tea.MouseButtonWheelUp:
+			s.scroll(-wheelDelta)
+		}
+	}
+	return s
+}
+
+// view renders the visible lines padded to the scroller's size. decorate,
+// when set, may restyle each visible line, given its index.
+func (s scroller) view(decorate func(int, string) string) string {
+	top := min(s.offset, len(s.lines))
+	bottom := min(top+s.height, len(s.lines))
+	lines := s.lines[top:bottom]
+	if decorate != nil {
+		lines = make([]string, 0, bottom-top)
+		for i := top; i < bottom; i++ {
+			lines = append(lines, decorate(i, s.lines[i]))
+		}
+	}
+	return lipgloss.NewStyle().
+		Width(s.width).
+		Height(s.height).
+		MaxHeight(s.height).
+		MaxWidth(s.width).
+		Render(strings.Join(lines, "\n"))
+}
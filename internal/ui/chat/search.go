@@ -0,0 +1,285 @@
+package chat
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/e-9/copilot-icq/internal/ui/theme"
+)
+
+// search is the state of a / search in the conversation.
+type search struct {
+	input  textinput.Model
+	typing bool // the query is being edited
+	re     *regexp.Regexp
+	// matches are the hits in the rendered lines, in order; current is
+	// the one shown, -1 when there are none.
+	matches []match
+	current int
+	origin  int // scroll offset before the search, restored on Esc
+}
+
+// match is a hit in a rendered line, as byte offsets into the line with
+// its styling stripped.
+type match struct {
+	line       int
+	start, end int
+}
+
+var (
+	matchStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("229"))
+	currentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("214")).Bold(true)
+	barStyle     = lipgloss.NewStyle().Foreground(theme.Subtle)
+)
+
+// StartSearch opens the search prompt. Matches are found and highlighted
+// as the query is typed; Enter keeps them for n and N, Esc cancels.
+func (m *Model) StartSearch() tea.Cmd {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "search"
+	m.search = &search{input: ti, typing: true, current: -1, origin: m.scroll.offset}
+	m.layout()
+	return m.search.input.Focus()
+}
+
+// ShowMessage scrolls to the message at position i. Given a pattern, it
+// also opens the search on it, as if query had been typed and confirmed,
+// showing the first match in or after the message.
+func (m *Model) ShowMessage(i int, query string, re *regexp.Regexp) {
+	if re != nil {
+		ti := textinput.New()
+		ti.Prompt = "/"
+		ti.SetValue(query)
+		m.search = &search{input: ti, re: re, current: -1}
+		m.layout()
+		m.revealMatches()
+		m.refresh()
+	}
+	if i >= 0 && i < len(m.starts) {
+		m.scroll.offset = m.starts[i]
+		m.scroll.clamp()
+	}
+	if m.search != nil {
+		m.search.origin = m.scroll.offset
+		m.showMatchFrom(m.search.origin)
+	}
+}
+
+// IsSearching reports whether the search prompt has the keyboard.
+func (m Model) IsSearching() bool {
+	return m.search != nil && m.search.typing
+}
+
+// HasSearch reports whether a search is open, typing or showing matches.
+func (m Model) HasSearch() bool {
+	return m.search != nil
+}
+
+// updateSearch handles keys while a search is open. It reports whether it
+// used the key.
+func (m *Model) updateSearch(msg tea.KeyMsg) (tea.Cmd, bool) {
+	s := m.search
+	if s.typing {
+		switch msg.String() {
+		case "esc":
+			m.scroll.offset = s.origin
+			m.closeSearch()
+			return nil, true
+		case "enter":
+			if s.input.Value() == "" {
+				m.closeSearch()
+				return nil, true
+			}
+			s.typing = false
+			s.input.Blur()
+			return nil, true
+		}
+		var cmd tea.Cmd
+		before := s.input.Value()
+		s.input, cmd = s.input.Update(msg)
+		if s.input.Value() != before {
+			m.setQuery(s.input.Value())
+		}
+		return cmd, true
+	}
+
+	switch msg.String() {
+	case "n":
+		m.jump(1)
+		return nil, true
+	case "N":
+		m.jump(-1)
+		return nil, true
+	case "/":
+		s.typing = true
+		return s.input.Focus(), true
+	case "esc":
+		m.closeSearch()
+		return nil, true
+	}
+	return nil, false
+}
+
+func (m *Model) closeSearch() {
+	m.search = nil
+	m.reveal = make(map[string]bool)
+	m.layout()
+	m.refresh()
+}
+
+// setQuery compiles a query and finds its matches. The query is matched
+// literally, ignoring case unless it has an upper-case letter.
+func (m *Model) setQuery(q string) {
+	s := m.search
+	s.re = nil
+	if q != "" {
+		pattern := regexp.QuoteMeta(q)
+		if !strings.ContainsFunc(q, unicode.IsUpper) {
+			pattern = "(?i)" + pattern
+		}
+		s.re = regexp.MustCompile(pattern)
+	}
+	m.revealMatches()
+	m.refresh()
+	m.showMatchFrom(s.origin)
+}
+
+// showMatchFrom shows the first match at or below a line, wrapping around
+// to the first one. Without matches it goes back to where the search
+// started.
+func (m *Model) showMatchFrom(line int) {
+	s := m.search
+	s.current = -1
+	for i, hit := range s.matches {
+		if hit.line >= line {
+			s.current = i
+			break
+		}
+	}
+	if s.current < 0 && len(s.matches) > 0 {
+		s.current = 0
+	}
+	if s.current >= 0 {
+		m.scrollTo(s.matches[s.current].line)
+	} else {
+		m.scroll.offset = s.origin
+		m.scroll.clamp()
+	}
+}
+
+// revealMatches expands the tool calls whose summary, command or patch
+// matches, so hits that are folded or cut short become visible.
+func (m *Model) revealMatches() {
+	m.reveal = make(map[string]bool)
+	re := m.search.re
+	if re == nil {
+		return
+	}
+	idx := 0
+	for _, msg := range m.messages {
+		for _, tc := range msg.ToolCalls {
+			if re.MatchString(tc.Summary) || re.MatchString(tc.Command) || re.MatchString(tc.Patch) {
+				m.reveal[toolKey(tc, idx)] = true
+			}
+			idx++
+		}
+	}
+}
+
+// findMatches finds the query in the rendered lines. It runs whenever the
+// lines change, so the matches follow streamed text.
+func (m *Model) findMatches() {
+	s := m.search
+	if s == nil {
+		return
+	}
+	s.matches = s.matches[:0]
+	if s.re != nil {
+		for i, line := range m.scroll.lines {
+			for _, loc := range s.re.FindAllStringIndex(ansi.Strip(line), -1) {
+				s.matches = append(s.matches, match{line: i, start: loc[0], end: loc[1]})
+			}
+		}
+	}
+	if s.current >= len(s.matches) {
+		s.current = len(s.matches) - 1
+	}
+}
+
+// jump moves to the next match, or the previous one with dir -1, wrapping
+// around at the ends.
+func (m *Model) jump(dir int) {
+	s := m.search
+	if len(s.matches) == 0 {
+		return
+	}
+	s.current = (s.current + dir + len(s.matches)) % len(s.matches)
+	m.scrollTo(s.matches[s.current].line)
+}
+
+// scrollTo puts a line in the middle of the panel.
+func (m *Model) scrollTo(line int) {
+	m.scroll.offset = line - m.scroll.height/2
+	m.scroll.clamp()
+}
+
+// highlight redraws a line with its matches marked. The line loses its
+// other styling, which keeps the marks readable on any background.
+func (m Model) highlight(i int, line string) string {
+	s := m.search
+	if s == nil || len(s.matches) == 0 {
+		return line
+	}
+	var hits []int // indexes into s.matches on this line
+	for j, hit := range s.matches {
+		if hit.line == i {
+			hits = append(hits, j)
+		} else if hit.line > i {
+			break
+		}
+	}
+	if len(hits) == 0 {
+		return line
+	}
+
+	plain := ansi.Strip(line)
+	var sb strings.Builder
+	at := 0
+	for _, j := range hits {
+		hit := s.matches[j]
+		sb.WriteString(plain[at:hit.start])
+		style := matchStyle
+		if j == s.current {
+			style = currentStyle
+		}
+		sb.WriteString(style.Render(plain[hit.start:hit.end]))
+		at = hit.end
+	}
+	sb.WriteString(plain[at:])
+	return sb.String()
+}
+
+// searchBar shows the query and where the current match is.
+func (m Model) searchBar() string {
+	s := m.search
+	status := ""
+	switch {
+	case s.re == nil:
+	case len(s.matches) == 0:
+		status = "no matches"
+	default:
+		status = fmt.Sprintf("%d/%d", s.current+1, len(s.matches))
+	}
+	if !s.typing {
+		status += "  n/N next/prev · / edit · Esc close"
+	}
+	return s.input.View() + "  " + barStyle.Render(status)
+}
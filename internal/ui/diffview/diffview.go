@@ -0,0 +1,176 @@
+// Package diffview shows the changes the agent's edit tools made, full
+// screen: a summary of the files changed, then each file's hunks, unified
+// or side by side, with syntax highlighting.
+package diffview
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/e-9/copilot-icq/internal/diff"
+	"github.com/e-9/copilot-icq/internal/ui/theme"
+)
+
+// sideBySideWidth is the narrowest screen that starts side by side.
+const sideBySideWidth = 140
+
+// Edit is the patch one tool call made.
+type Edit struct {
+	Time  time.Time
+	Files []diff.File
+}
+
+// Model is a scrollable diff of one or more edits, grouped by file.
+type Model struct {
+	title      string
+	files      []fileChanges
+	sideBySide bool
+	view       viewport.Model
+	width      int
+	hunks      []int // first line of each hunk in the content
+	fileStarts []int // first line of each file's section
+	closed     bool
+}
+
+var (
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(theme.Accent).
+			Background(lipgloss.Color("236")).
+			Padding(0, 1)
+	footerStyle = lipgloss.NewStyle().Foreground(theme.Subtle)
+)
+
+// New creates a diff view of edits filling width × height. Wide screens
+// start side by side.
+func New(title string, edits []Edit, width, height int) Model {
+	d := Model{
+		title:      title,
+		files:      group(edits),
+		sideBySide: width >= sideBySideWidth,
+		view:       viewport.New(width, 1),
+	}
+	d.SetSize(width, height)
+	return d
+}
+
+// SetSize fits the view to the screen, keeping a line for the title and one
+// for the footer, and lays the diff out again for the new width.
+func (d *Model) SetSize(w, h int) {
+	d.width = w
+	d.view.Width = w
+	d.view.Height = max(h-2, 1)
+	d.relayout()
+}
+
+// Closed reports whether the user closed the view.
+func (d Model) Closed() bool {
+	return d.closed
+}
+
+// Update handles the keys: n and N jump to the next and previous hunk,
+// ] and [ to the next and previous file, v switches between unified and
+// side by side, g and G jump to the top and bottom, and q or Esc close the
+// view. Anything else scrolls.
+func (d Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "q", "esc", "enter":
+			d.closed = true
+			return d, nil
+		case "n":
+			d.jump(d.hunks, 1)
+			return d, nil
+		case "N":
+			d.jump(d.hunks, -1)
+			return d, nil
+		case "]":
+			d.jump(d.fileStarts, 1)
+			return d, nil
+		case "[":
+			d.jump(d.fileStarts, -1)
+			return d, nil
+		case "v":
+			d.sideBySide = !d.sideBySide
+			d.relayout()
+			return d, nil
+		case "g", "home":
+			d.view.GotoTop()
+			return d, nil
+		case "G", "end":
+			d.view.GotoBottom()
+			return d, nil
+		}
+	}
+	var cmd tea.Cmd
+	d.view, cmd = d.view.Update(msg)
+	return d, cmd
+}
+
+// relayout renders the diff again, e.g. for a new width or layout, keeping
+// the hunk at the top of the screen there.
+func (d *Model) relayout() {
+	at := current(d.hunks, d.view.YOffset)
+	content, hunks, fileStarts := d.render()
+	d.hunks, d.fileStarts = hunks, fileStarts
+	d.view.SetContent(content)
+	if at >= 0 && at < len(d.hunks) {
+		d.view.SetYOffset(d.hunks[at])
+	}
+}
+
+// jump scrolls to the next of starts below the top of the screen, or the
+// previous one above it with a negative delta.
+func (d *Model) jump(starts []int, delta int) {
+	top := d.view.YOffset
+	if delta > 0 {
+		for _, line := range starts {
+			if line > top {
+				d.view.SetYOffset(line)
+				return
+			}
+		}
+		return
+	}
+	for i := len(starts) - 1; i >= 0; i-- {
+		if starts[i] < top {
+			d.view.SetYOffset(starts[i])
+			return
+		}
+	}
+}
+
+// current returns the index of the last of starts at or above line, or -1.
+func current(starts []int, line int) int {
+	at := -1
+	for i, s := range starts {
+		if s <= line {
+			at = i
+		}
+	}
+	return at
+}
+
+// View renders the diff view.
+func (d Model) View() string {
+	title := titleStyle.Width(d.width).MaxWidth(d.width).Render(d.title)
+
+	layout := "side by side"
+	if d.sideBySide {
+		layout = "unified"
+	}
+	hunk := ""
+	if at := current(d.hunks, d.view.YOffset); at >= 0 {
+		hunk = fmt.Sprintf("hunk %d/%d · ", at+1, len(d.hunks))
+	} else if len(d.hunks) == 1 {
+		hunk = "1 hunk · "
+	} else if len(d.hunks) > 1 {
+		hunk = fmt.Sprintf("%d hunks · ", len(d.hunks))
+	}
+	footer := footerStyle.Render(fmt.Sprintf(" %sn/N hunk · ]/[ file · v %s · q close   %3.f%%", hunk, layout, d.view.ScrollPercent()*100))
+	return title + "\n" + d.view.View() + "\n" + footer
+}
@@ -0,0 +1,83 @@
+package diffview
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/e-9/copilot-icq/internal/diff"
+)
+
+func TestGroupAndLayouts(t *testing.T) {
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	edits := []Edit{
+		{Time: at, Files: diff.Parse(diff.Create("main.go", "package main\n\nfunc main() {}\n"))},
+		{Time: at.Add(time.Minute), Files: diff.Parse(diff.Edit("main.go", "func main() {}\n", "func main() {\n\trun()\n}\n"))},
+		{Time: at.Add(2 * time.Minute), Files: diff.Parse(diff.Edit("util.go", "a\nb\n", "a\nc\n"))},
+	}
+	files := group(edits)
+	if len(files) != 2 || files[0].path != "main.go" || files[0].op != diff.Added || files[0].edits != 2 || len(files[0].hunks) != 2 {
+		t.Fatalf("files = %+v", files)
+	}
+	if files[0].added != 6 || files[0].removed != 1 {
+		t.Errorf("main.go = +%d -%d, want +6 -1", files[0].added, files[0].removed)
+	}
+
+	d := New("changes", edits, 100, 40)
+	if d.sideBySide {
+		t.Error("a narrow screen should start unified")
+	}
+	view := ansi.Strip(d.View())
+	for _, want := range []string{"2 files changed · +7 −2", "A main.go", "2 edits", "@@ · 10:01:00", "- b", "+ c"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("unified view is missing %q:\n%s", want, view)
+		}
+	}
+
+	// Side by side, a removed line sits next to the line that replaced it
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	var paired bool
+	for _, line := range strings.Split(ansi.Strip(d.View()), "\n") {
+		if left, right, ok := strings.Cut(line, "│"); ok && strings.TrimSpace(left) == "- b" && strings.TrimSpace(right) == "+ c" {
+			paired = true
+		}
+	}
+	if !paired {
+		t.Errorf("side by side should pair - b with + c:\n%s", ansi.Strip(d.View()))
+	}
+
+	// n steps through the hunks, ] to the next file
+	d.SetSize(100, 8)
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if d.view.YOffset != d.hunks[0] {
+		t.Errorf("n should show the first hunk, offset %d, hunks %v", d.view.YOffset, d.hunks)
+	}
+	// The last file can only scroll up as far as the bottom of the diff
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")})
+	if top := d.view.YOffset; d.fileStarts[1] < top || d.fileStarts[1] >= top+d.view.Height {
+		t.Errorf("] should show the next file, offset %d, files %v", top, d.fileStarts)
+	}
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	if d.view.YOffset != d.hunks[1] {
+		t.Errorf("N should go back a hunk, offset %d, hunks %v", d.view.YOffset, d.hunks)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	code := []string{"// comment", "func main() {", "}"}
+	got := highlight("main.go", code)
+	if len(got) != len(code) {
+		t.Fatalf("got %d lines, want %d", len(got), len(code))
+	}
+	for i := range code {
+		if ansi.Strip(got[i]) != code[i] {
+			t.Errorf("line %d = %q, want %q", i, ansi.Strip(got[i]), code[i])
+		}
+	}
+	if plain := highlight("notes.unknownext", code); plain[1] != code[1] {
+		t.Errorf("an unknown language should be left plain, got %q", plain[1])
+	}
+}
@@ -0,0 +1,383 @@
+package diffview
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/e-9/copilot-icq/internal/diff"
+	"github.com/e-9/copilot-icq/internal/ui/theme"
+)
+
+// codeStyle is the chroma style code is highlighted with.
+const codeStyle = "monokai"
+
+var (
+	addStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("114"))
+	delStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("210"))
+	subtleStyle = lipgloss.NewStyle().Foreground(theme.Subtle)
+	fileStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("117")).Bold(true)
+	hunkStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("141"))
+	opStyles    = map[diff.Op]lipgloss.Style{
+		diff.Added:    addStyle.Bold(true),
+		diff.Deleted:  delStyle.Bold(true),
+		diff.Modified: lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true),
+		diff.Renamed:  fileStyle,
+	}
+)
+
+// fileChanges is every edit to one file, in order.
+type fileChanges struct {
+	path    string
+	oldPath string
+	op      diff.Op
+	edits   int
+	added   int
+	removed int
+	hunks   []hunk
+}
+
+// hunk is a hunk with the time of the edit that made it.
+type hunk struct {
+	diff.Hunk
+	label string
+}
+
+// group collects the edits' hunks by file, in the order the files were
+// first changed. A file added and then edited stays added; one removed at
+// the end shows as deleted.
+func group(edits []Edit) []fileChanges {
+	var files []fileChanges
+	index := make(map[string]int)
+	for _, e := range edits {
+		label := ""
+		if !e.Time.IsZero() {
+			label = e.Time.Format("15:04:05")
+		}
+		for _, f := range e.Files {
+			i, ok := index[f.Path]
+			if !ok {
+				i = len(files)
+				index[f.Path] = i
+				files = append(files, fileChanges{path: f.Path, oldPath: f.OldPath, op: f.Op})
+			}
+			fc := &files[i]
+			switch {
+			case f.Op == diff.Deleted:
+				fc.op = diff.Deleted
+			case f.Op == diff.Added && fc.op == diff.Deleted:
+				fc.op = diff.Modified
+			case f.Op == diff.Renamed && fc.op != diff.Added:
+				fc.op, fc.oldPath = diff.Renamed, f.OldPath
+			}
+			fc.edits++
+			a, r := f.Stats()
+			fc.added += a
+			fc.removed += r
+			for _, h := range f.Hunks {
+				fc.hunks = append(fc.hunks, hunk{Hunk: h, label: label})
+			}
+		}
+	}
+	return files
+}
+
+// render lays out the summary and every file's hunks, returning the text
+// and the first line of each hunk and each file.
+func (d Model) render() (content string, hunks, fileStarts []int) {
+	var lines []string
+	if len(d.files) == 0 {
+		return subtleStyle.Render("No file changes"), nil, nil
+	}
+
+	lines = append(lines, d.summary()...)
+	for _, f := range d.files {
+		lines = append(lines, "")
+		fileStarts = append(fileStarts, len(lines))
+		lines = append(lines, d.fileHeader(f))
+
+		width := numberWidth(f)
+		for _, h := range f.hunks {
+			hunks = append(hunks, len(lines))
+			lines = append(lines, d.hunkHeader(h))
+			rows := highlightHunk(f.path, h.Hunk)
+			if d.sideBySide {
+				lines = append(lines, d.sideBySideRows(rows, width)...)
+			} else {
+				lines = append(lines, d.unifiedRows(rows, width)...)
+			}
+		}
+	}
+	return strings.Join(lines, "\n"), hunks, fileStarts
+}
+
+// summary lists the files changed with their added and removed lines.
+func (d Model) summary() []string {
+	added, removed := 0, 0
+	pathWidth := 0
+	for _, f := range d.files {
+		added += f.added
+		removed += f.removed
+		pathWidth = max(pathWidth, lipgloss.Width(displayPath(f)))
+	}
+	pathWidth = min(pathWidth, max(d.width-30, 10))
+
+	files := "files"
+	if len(d.files) == 1 {
+		files = "file"
+	}
+	lines := []string{fmt.Sprintf("%s %s %s %s",
+		lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("%d %s changed", len(d.files), files)),
+		subtleStyle.Render("·"), addStyle.Render(fmt.Sprintf("+%d", added)), delStyle.Render(fmt.Sprintf("−%d", removed)))}
+	for _, f := range d.files {
+		path := ansi.Truncate(displayPath(f), pathWidth, "…")
+		line := fmt.Sprintf("  %s %s  %s", opLetter(f.op), path+strings.Repeat(" ", pathWidth-lipgloss.Width(path)), stats(f.added, f.removed))
+		if f.edits > 1 {
+			line += subtleStyle.Render(fmt.Sprintf("  %d edits", f.edits))
+		}
+		lines = append(lines, d.fit(line))
+	}
+	return lines
+}
+
+// fileHeader is the bar above a file's hunks.
+func (d Model) fileHeader(f fileChanges) string {
+	head := fmt.Sprintf("%s %s  %s ", opLetter(f.op), fileStyle.Render(displayPath(f)), stats(f.added, f.removed))
+	if rule := d.width - lipgloss.Width(head); rule > 0 {
+		head += subtleStyle.Render(strings.Repeat("─", rule))
+	}
+	return d.fit(head)
+}
+
+// hunkHeader shows a hunk's @@ line and when its edit was made.
+func (d Model) hunkHeader(h hunk) string {
+	head := hunkStyle.Render(strings.TrimSpace("@@ " + h.Header))
+	if h.label != "" {
+		head += subtleStyle.Render(" · " + h.label)
+	}
+	return d.fit(head)
+}
+
+// row is a line of a hunk, highlighted.
+type row struct {
+	diff.Line
+	code string
+}
+
+// unifiedRows shows a hunk's lines one under the other, with the old and
+// new line numbers in front.
+func (d Model) unifiedRows(rows []row, width int) []string {
+	out := make([]string, 0, len(rows))
+	for _, r := range rows {
+		gutter := ""
+		if width > 0 {
+			gutter = subtleStyle.Render(number(r.Old, width)+" "+number(r.New, width)) + " "
+		}
+		out = append(out, d.fit(gutter+sign(r.Kind)+" "+r.code))
+	}
+	return out
+}
+
+// sideBySideRows shows the old file on the left and the new one on the
+// right, pairing each run of removed lines with the added lines after it.
+func (d Model) sideBySideRows(rows []row, width int) []string {
+	half := max((d.width-1)/2, 1)
+	side := func(r *row, old bool) string {
+		if r == nil {
+			return strings.Repeat(" ", half)
+		}
+		n := r.New
+		if old {
+			n = r.Old
+		}
+		s := sign(r.Kind) + " " + r.code
+		if width > 0 {
+			s = subtleStyle.Render(number(n, width)) + " " + s
+		}
+		s = ansi.Truncate(s, half, "…")
+		return s + strings.Repeat(" ", max(half-lipgloss.Width(s), 0))
+	}
+	sep := subtleStyle.Render("│")
+
+	var out []string
+	for i := 0; i < len(rows); {
+		if rows[i].Kind == diff.Context {
+			out = append(out, side(&rows[i], true)+sep+side(&rows[i], false))
+			i++
+			continue
+		}
+		var dels, adds []*row
+		for ; i < len(rows) && rows[i].Kind == diff.Del; i++ {
+			dels = append(dels, &rows[i])
+		}
+		for ; i < len(rows) && rows[i].Kind == diff.Add; i++ {
+			adds = append(adds, &rows[i])
+		}
+		for j := 0; j < max(len(dels), len(adds)); j++ {
+			var left, right *row
+			if j < len(dels) {
+				left = dels[j]
+			}
+			if j < len(adds) {
+				right = adds[j]
+			}
+			out = append(out, side(left, true)+sep+side(right, false))
+		}
+	}
+	return out
+}
+
+// fit cuts a line to the screen width.
+func (d Model) fit(s string) string {
+	if d.width <= 0 {
+		return s
+	}
+	return ansi.Truncate(s, d.width, "…")
+}
+
+// highlightHunk highlights a hunk's code for the file's language. The old
+// and new sides are highlighted separately, so each reads as a whole.
+func highlightHunk(path string, h diff.Hunk) []row {
+	var oldCode, newCode []string
+	for _, l := range h.Lines {
+		text := strings.ReplaceAll(l.Text, "\t", "    ")
+		if l.Kind != diff.Add {
+			oldCode = append(oldCode, text)
+		}
+		if l.Kind != diff.Del {
+			newCode = append(newCode, text)
+		}
+	}
+	oldCode, newCode = highlight(path, oldCode), highlight(path, newCode)
+
+	rows := make([]row, len(h.Lines))
+	o, n := 0, 0
+	for i, l := range h.Lines {
+		rows[i].Line = l
+		switch l.Kind {
+		case diff.Del:
+			rows[i].code = oldCode[o]
+			o++
+		case diff.Add:
+			rows[i].code = newCode[n]
+			n++
+		default:
+			rows[i].code = newCode[n]
+			o, n = o+1, n+1
+		}
+	}
+	return rows
+}
+
+// highlight colours lines of code with chroma, by the language the path's
+// extension suggests. Lines in an unknown language are left plain.
+func highlight(path string, code []string) []string {
+	lexer := lexers.Match(path)
+	if lexer == nil || len(code) == 0 {
+		return code
+	}
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, strings.Join(code, "\n"))
+	if err != nil {
+		return code
+	}
+	style := styles.Get(codeStyle)
+
+	out := make([]string, 0, len(code))
+	var line strings.Builder
+	for tok := it(); tok != chroma.EOF; tok = it() {
+		s := tokenStyle(style.Get(tok.Type))
+		for i, part := range strings.Split(tok.Value, "\n") {
+			if i > 0 {
+				out = append(out, line.String())
+				line.Reset()
+			}
+			if part != "" {
+				line.WriteString(s.Render(part))
+			}
+		}
+	}
+	out = append(out, line.String())
+
+	// The lexer may add or drop a trailing newline; keep one line per line
+	for len(out) < len(code) {
+		out = append(out, "")
+	}
+	return out[:len(code)]
+}
+
+// tokenStyle turns a chroma style entry into a lipgloss style.
+func tokenStyle(e chroma.StyleEntry) lipgloss.Style {
+	s := lipgloss.NewStyle()
+	if e.Colour.IsSet() {
+		s = s.Foreground(lipgloss.Color(e.Colour.String()))
+	}
+	if e.Bold == chroma.Yes {
+		s = s.Bold(true)
+	}
+	if e.Italic == chroma.Yes {
+		s = s.Italic(true)
+	}
+	return s
+}
+
+// numberWidth is the width of the largest line number in a file's hunks,
+// or 0 when its patches have none.
+func numberWidth(f fileChanges) int {
+	top := 0
+	for _, h := range f.hunks {
+		for _, l := range h.Lines {
+			top = max(top, l.Old, l.New)
+		}
+	}
+	if top == 0 {
+		return 0
+	}
+	return len(strconv.Itoa(top))
+}
+
+// number right-aligns a line number, leaving 0 blank.
+func number(n, width int) string {
+	if n == 0 {
+		return strings.Repeat(" ", width)
+	}
+	return fmt.Sprintf("%*d", width, n)
+}
+
+// sign marks an added, removed or context line.
+func sign(k diff.Kind) string {
+	switch k {
+	case diff.Add:
+		return addStyle.Render("+")
+	case diff.Del:
+		return delStyle.Render("-")
+	}
+	return " "
+}
+
+// opLetter marks what happened to a file, as git status does.
+func opLetter(op diff.Op) string {
+	letter := map[diff.Op]string{diff.Added: "A", diff.Deleted: "D", diff.Renamed: "R"}[op]
+	if letter == "" {
+		letter = "M"
+	}
+	return opStyles[op].Render(letter)
+}
+
+// stats shows added and removed line counts.
+func stats(added, removed int) string {
+	return addStyle.Render(fmt.Sprintf("+%d", added)) + " " + delStyle.Render(fmt.Sprintf("−%d", removed))
+}
+
+// displayPath is a file's path, with where it came from when renamed.
+func displayPath(f fileChanges) string {
+	if f.oldPath != "" && f.op == diff.Renamed {
+		return f.oldPath + " → " + f.path
+	}
+	return f.path
+}
@@ -0,0 +1,200 @@
+// Package finder provides the view for searching every session's history.
+package finder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+	"github.com/e-9/copilot-icq/internal/ui/theme"
+)
+
+// Result is a message matching the query.
+type Result struct {
+	SessionID string
+	Session   string // session display name
+	Message   int    // position in the session's history
+	Role      domain.MessageRole
+	Time      time.Time
+	Snippet   string
+}
+
+// Model is a query box over a ranked list of results. The app runs the
+// query whenever it changes and hands back the results with SetResults.
+type Model struct {
+	input     textinput.Model
+	results   []Result
+	highlight *regexp.Regexp
+	status    string
+	cursor    int
+	width     int
+	height    int
+	chosen    *Result
+	cancelled bool
+}
+
+// New creates an empty finder.
+func New(width, height int) Model {
+	ti := textinput.New()
+	ti.Prompt = "🔎 "
+	ti.Placeholder = "search every session"
+	return Model{input: ti, width: width, height: height}
+}
+
+// Focus gives the query box the keyboard.
+func (f *Model) Focus() tea.Cmd {
+	return f.input.Focus()
+}
+
+// Query returns the text typed so far.
+func (f Model) Query() string {
+	return f.input.Value()
+}
+
+// SetResults shows the results of the current query, best first, with the
+// matched words marked by highlight.
+func (f *Model) SetResults(rs []Result, highlight *regexp.Regexp) {
+	f.results = rs
+	f.highlight = highlight
+	f.cursor = 0
+}
+
+// SetStatus shows a note under the results, such as indexing progress.
+func (f *Model) SetStatus(s string) {
+	f.status = s
+}
+
+// SetSize updates the finder dimensions.
+func (f *Model) SetSize(w, h int) {
+	f.width = w
+	f.height = h
+}
+
+// Chosen returns the result picked with Enter.
+func (f Model) Chosen() (Result, bool) {
+	if f.chosen == nil {
+		return Result{}, false
+	}
+	return *f.chosen, true
+}
+
+// Cancelled reports whether the user closed the finder.
+func (f Model) Cancelled() bool {
+	return f.cancelled
+}
+
+// Update handles key presses: arrows move through the results, Enter opens
+// one, Esc closes, and anything else edits the query.
+func (f Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return f, nil
+	}
+	switch key.String() {
+	case "esc":
+		f.cancelled = true
+		return f, nil
+	case "up", "ctrl+p":
+		if f.cursor > 0 {
+			f.cursor--
+		}
+		return f, nil
+	case "down", "ctrl+n":
+		if f.cursor < len(f.results)-1 {
+			f.cursor++
+		}
+		return f, nil
+	case "enter":
+		if len(f.results) > 0 {
+			r := f.results[f.cursor]
+			f.chosen = &r
+		}
+		return f, nil
+	}
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(msg)
+	return f, cmd
+}
+
+var (
+	sessionStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true)
+	selectedStyle = lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+	dimStyle      = lipgloss.NewStyle().Foreground(theme.Subtle)
+	matchStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("229"))
+)
+
+// View renders the finder.
+func (f Model) View() string {
+	var sb strings.Builder
+	sb.WriteString(" " + f.input.View() + "\n\n")
+
+	switch {
+	case strings.TrimSpace(f.Query()) == "":
+		sb.WriteString(dimStyle.Render("  Type to search messages, tool calls, commands and patches"))
+	case len(f.results) == 0:
+		sb.WriteString(dimStyle.Render("  No matches"))
+	default:
+		// Two lines per result; keep the cursor on screen
+		rows := max((f.height-5)/2, 1)
+		start := 0
+		if f.cursor >= rows {
+			start = f.cursor - rows + 1
+		}
+		end := min(start+rows, len(f.results))
+		for i := start; i < end; i++ {
+			sb.WriteString(f.renderResult(f.results[i], i == f.cursor))
+		}
+		sb.WriteString(dimStyle.Render(fmt.Sprintf("  %d/%d", f.cursor+1, len(f.results))))
+	}
+
+	sb.WriteString("\n")
+	if f.status != "" {
+		sb.WriteString(dimStyle.Render("  "+f.status) + "\n")
+	}
+	sb.WriteString(dimStyle.Render("  ↑↓ move · Enter open conversation · Esc close"))
+	return lipgloss.NewStyle().Width(f.width).Render(sb.String())
+}
+
+func (f Model) renderResult(r Result, selected bool) string {
+	marker, name := "  ", sessionStyle.Render(r.Session)
+	if selected {
+		marker, name = selectedStyle.Render("▸ "), selectedStyle.Render(r.Session)
+	}
+	who := "you"
+	if r.Role != domain.RoleUser {
+		who = "copilot"
+	}
+	meta := who
+	if !r.Time.IsZero() {
+		meta = r.Time.Local().Format("2006-01-02 15:04") + " · " + who
+	}
+
+	snippet := r.Snippet
+	if limit := f.width - 4; limit > 0 && len([]rune(snippet)) > limit {
+		snippet = string([]rune(snippet)[:limit-1]) + "…"
+	}
+	return marker + name + dimStyle.Render("  "+meta) + "\n" +
+		"    " + f.mark(snippet) + "\n"
+}
+
+// mark highlights the query's words in a snippet.
+func (f Model) mark(s string) string {
+	if f.highlight == nil {
+		return s
+	}
+	var sb strings.Builder
+	at := 0
+	for _, loc := range f.highlight.FindAllStringIndex(s, -1) {
+		sb.WriteString(s[at:loc[0]])
+		sb.WriteString(matchStyle.Render(s[loc[0]:loc[1]]))
+		at = loc[1]
+	}
+	sb.WriteString(s[at:])
+	return sb.String()
+}
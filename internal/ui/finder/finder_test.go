@@ -0,0 +1,107 @@
+package finder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/e-9/copilot-icq/internal/domain"
+)
+
+func press(f Model, keys ...tea.KeyMsg) Model {
+	for _, k := range keys {
+		f, _ = f.Update(k)
+	}
+	return f
+}
+
+func typed(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+var (
+	up    = tea.KeyMsg{Type: tea.KeyUp}
+	down  = tea.KeyMsg{Type: tea.KeyDown}
+	enter = tea.KeyMsg{Type: tea.KeyEnter}
+)
+
+func TestFinderChoosesResult(t *testing.T) {
+	f := New(80, 20)
+	f.Focus()
+	if _, ok := press(f, enter).Chosen(); ok {
+		t.Fatal("nothing can be chosen without results")
+	}
+	if view := ansi.Strip(f.View()); !strings.Contains(view, "Type to search") {
+		t.Errorf("an empty query should say what to do:\n%s", view)
+	}
+
+	f = press(f, typed("auth"))
+	if f.Query() != "auth" {
+		t.Fatalf("query = %q", f.Query())
+	}
+	if view := ansi.Strip(f.View()); !strings.Contains(view, "No matches") {
+		t.Errorf("a query without results should say so:\n%s", view)
+	}
+
+	f.SetResults([]Result{
+		{SessionID: "a", Session: "Login", Message: 3, Role: domain.RoleUser, Snippet: "fix the auth check"},
+		{SessionID: "b", Session: "API", Message: 7, Role: domain.RoleAssistant, Snippet: "auth middleware"},
+	}, regexp.MustCompile(`(?i)auth`))
+	view := ansi.Strip(f.View())
+	for _, want := range []string{"▸ Login", "you", "copilot", "fix the auth check", "1/2"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view is missing %q:\n%s", want, view)
+		}
+	}
+
+	// The cursor stops at both ends
+	if r, _ := press(f, down, down, enter).Chosen(); r.SessionID != "b" || r.Message != 7 {
+		t.Errorf("chose %+v, want b's message 7", r)
+	}
+	if r, _ := press(f, down, up, up, enter).Chosen(); r.SessionID != "a" {
+		t.Errorf("chose %+v, want a", r)
+	}
+	if !press(f, tea.KeyMsg{Type: tea.KeyEsc}).Cancelled() {
+		t.Error("Esc should close the finder")
+	}
+}
+
+func TestFinderKeepsCursorOnScreen(t *testing.T) {
+	f := New(80, 11) // room for three results
+	f.Focus()
+	var rs []Result
+	for i := range 10 {
+		rs = append(rs, Result{SessionID: fmt.Sprint(i), Session: fmt.Sprintf("session %d", i), Snippet: "match"})
+	}
+	f = press(f, typed("match"))
+	f.SetResults(rs, nil)
+	for range 6 {
+		f = press(f, down)
+	}
+	view := ansi.Strip(f.View())
+	if !strings.Contains(view, "▸ session 6") || strings.Contains(view, "session 3") || !strings.Contains(view, "7/10") {
+		t.Errorf("the cursor should scroll the list:\n%s", view)
+	}
+
+	// New results start from the top
+	f.SetResults(rs[:2], nil)
+	if !strings.Contains(ansi.Strip(f.View()), "1/2") {
+		t.Error("new results should reset the cursor")
+	}
+}
+
+func TestMarkHighlightsMatches(t *testing.T) {
+	f := New(80, 20)
+	f.highlight = regexp.MustCompile(`(?i)auth`)
+	got := f.mark("Auth and auth")
+	if ansi.Strip(got) != "Auth and auth" {
+		t.Errorf("marking changed the text: %q", ansi.Strip(got))
+	}
+	if want := matchStyle.Render("Auth") + " and " + matchStyle.Render("auth"); got != want {
+		t.Errorf("mark = %q, want %q", got, want)
+	}
+}
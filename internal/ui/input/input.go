@@ -1,83 +1,163 @@
 package input
 
 import (
-	"github.com/charmbracelet/bubbles/textinput"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/e-9/copilot-icq/internal/ui/theme"
 )
 
-// Model represents the message input area at the bottom of the chat panel.
+// MaxRows caps how tall the composer grows before it scrolls.
+const MaxRows = 8
+
+const placeholder = "Type a message... (Enter to send, Alt+Enter for newline, Ctrl+E for $EDITOR)"
+
+// Model represents the message composer at the bottom of the chat panel.
+// Enter is left to the app to send; Alt+Enter inserts a newline.
 type Model struct {
-	textInput textinput.Model
-	width     int
-	sending   bool
+	textArea textarea.Model
+	width    int
+	sending  bool
+
+	// Per-session prompt history, oldest first. histIdx == len(history)
+	// means the user is editing a fresh prompt, kept in draft meanwhile.
+	session   string
+	histories map[string][]string
+	histIdx   int
+	draft     string
 }
 
 // New creates a new input model.
 func New(width int) Model {
-	ti := textinput.New()
-	ti.Placeholder = "Type a message... (Enter to send)"
-	ti.CharLimit = 4096
-	ti.Width = width - 4
-	ti.Prompt = "❯ "
-	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Accent)
-
-	return Model{
-		textInput: ti,
-		width:     width,
+	ta := textarea.New()
+	ta.Placeholder = placeholder
+	ta.CharLimit = 0 // pasted stack traces can be long
+	ta.MaxHeight = 0
+	ta.ShowLineNumbers = false
+	ta.Prompt = "❯ "
+	ta.SetPromptFunc(2, func(lineIdx int) string {
+		if lineIdx == 0 {
+			return lipgloss.NewStyle().Foreground(theme.Accent).Render("❯ ")
+		}
+		return "  "
+	})
+	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	ta.FocusedStyle.Prompt = lipgloss.NewStyle().Foreground(theme.Accent)
+	ta.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter", "ctrl+j"))
+	ta.SetHeight(1)
+
+	m := Model{
+		textArea:  ta,
+		histories: make(map[string][]string),
 	}
+	m.SetWidth(width)
+	return m
 }
 
 // Focus gives keyboard focus to the input.
 func (m *Model) Focus() {
-	m.textInput.Focus()
+	m.textArea.Focus()
 }
 
 // Blur removes keyboard focus.
 func (m *Model) Blur() {
-	m.textInput.Blur()
+	m.textArea.Blur()
 }
 
 // SetWidth updates the input width.
 func (m *Model) SetWidth(w int) {
 	m.width = w
-	m.textInput.Width = w - 4
+	m.textArea.SetWidth(w - 2)
+	m.fitHeight()
+}
+
+// Height returns the number of rows the composer currently needs.
+func (m Model) Height() int {
+	if m.sending {
+		return m.textArea.Height() + 1 // status line
+	}
+	return m.textArea.Height()
 }
 
 // Value returns the current input text.
 func (m Model) Value() string {
-	return m.textInput.Value()
+	return m.textArea.Value()
+}
+
+// SetValue replaces the input text and moves the cursor to the end.
+func (m *Model) SetValue(s string) {
+	m.textArea.SetValue(s)
+	m.fitHeight()
+}
+
+// Cursor returns the cursor's line and rune column within that line.
+func (m Model) Cursor() (row, col int) {
+	li := m.textArea.LineInfo()
+	return m.textArea.Line(), li.StartColumn + li.ColumnOffset
+}
+
+// SetCursor moves the cursor to a line and rune column, clamped to the text.
+func (m *Model) SetCursor(row, col int) {
+	// SetValue leaves the cursor on the last line; walk up to the wanted one.
+	// CursorUp moves by wrapped rows, so the bound is generous.
+	for i := 0; m.textArea.Line() > row && i < len(m.Value())+1; i++ {
+		m.textArea.CursorUp()
+	}
+	m.textArea.SetCursor(col)
 }
 
 // Reset clears the input.
 func (m *Model) Reset() {
-	m.textInput.Reset()
+	m.textArea.Reset()
+	m.histIdx = len(m.histories[m.session])
+	m.draft = ""
+	m.fitHeight()
+}
+
+// SetSession switches the prompt history to another session.
+func (m *Model) SetSession(id string) {
+	m.session = id
+	m.histIdx = len(m.histories[id])
+	m.draft = ""
+}
+
+// Remember appends a sent prompt to the current session's history.
+func (m *Model) Remember(text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	h := m.histories[m.session]
+	if len(h) == 0 || h[len(h)-1] != text {
+		m.histories[m.session] = append(h, text)
+	}
+	m.histIdx = len(m.histories[m.session])
+	m.draft = ""
 }
 
-// SetSending toggles the sending state.
+// SetSending toggles the sending state. The composer stays usable so the
+// user can write follow-up prompts, which the app queues.
 func (m *Model) SetSending(sending bool) {
 	m.sending = sending
 	if sending {
-		m.textInput.Placeholder = "Sending..."
-		m.Blur()
+		m.textArea.Placeholder = "Copilot is working... Enter queues a follow-up"
 	} else {
-		m.textInput.Placeholder = "Type a message... (Enter to send)"
-		m.Focus()
+		m.textArea.Placeholder = placeholder
 	}
 }
 
 // SetRenaming puts the input into rename mode with prefilled text.
 func (m *Model) SetRenaming(currentName string) {
-	m.textInput.Placeholder = "Enter new session name..."
-	m.textInput.SetValue(currentName)
-	m.textInput.CursorEnd()
+	m.textArea.Placeholder = "Enter new session name..."
+	m.SetValue(currentName)
 	m.Focus()
 }
 
 // ClearRenaming restores the input to normal message mode.
 func (m *Model) ClearRenaming() {
-	m.textInput.Placeholder = "Type a message... (Enter to send)"
+	m.textArea.Placeholder = placeholder
 }
 
 // IsSending returns whether a message is being sent.
@@ -85,21 +165,79 @@ func (m Model) IsSending() bool {
 	return m.sending
 }
 
-// Update handles textinput messages.
+// Update handles textarea messages. Up on the first line and Down on the
+// last line walk the session's prompt history.
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok && m.textArea.Focused() {
+		switch key.String() {
+		case "up":
+			if m.textArea.Line() == 0 && m.historyBack() {
+				return m, nil
+			}
+		case "down":
+			if m.textArea.Line() == m.textArea.LineCount()-1 && m.historyForward() {
+				return m, nil
+			}
+		}
+	}
+
 	var cmd tea.Cmd
-	m.textInput, cmd = m.textInput.Update(msg)
+	m.textArea, cmd = m.textArea.Update(msg)
+	m.fitHeight()
 	return m, cmd
 }
 
+func (m *Model) historyBack() bool {
+	h := m.histories[m.session]
+	if m.histIdx == 0 || len(h) == 0 {
+		return false
+	}
+	if m.histIdx == len(h) {
+		m.draft = m.textArea.Value()
+	}
+	m.histIdx--
+	m.SetValue(h[m.histIdx])
+	return true
+}
+
+func (m *Model) historyForward() bool {
+	h := m.histories[m.session]
+	if m.histIdx >= len(h) {
+		return false
+	}
+	m.histIdx++
+	if m.histIdx == len(h) {
+		m.SetValue(m.draft)
+	} else {
+		m.SetValue(h[m.histIdx])
+	}
+	return true
+}
+
+// fitHeight grows the textarea with its wrapped content, up to MaxRows.
+func (m *Model) fitHeight() {
+	w := m.textArea.Width()
+	if w < 1 {
+		w = 1
+	}
+	rows := 0
+	for _, line := range strings.Split(m.textArea.Value(), "\n") {
+		rows += lipgloss.Width(line)/w + 1
+	}
+	rows = max(1, min(rows, MaxRows))
+	if rows != m.textArea.Height() {
+		m.textArea.SetHeight(rows)
+	}
+}
+
 // View renders the input area.
 func (m Model) View() string {
 	if m.sending {
 		spinner := lipgloss.NewStyle().
 			Foreground(theme.Warning).
 			Bold(true).
-			Render("⏳ Sending message to Copilot...")
-		return spinner
+			Render("⏳ Copilot is working...")
+		return spinner + "\n" + m.textArea.View()
 	}
-	return m.textInput.View()
+	return m.textArea.View()
 }
@@ -0,0 +1,65 @@
+package input
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHistoryNavigation(t *testing.T) {
+	m := New(80)
+	m.Focus()
+	m.SetSession("s1")
+	m.Remember("first")
+	m.Remember("second")
+	m.SetValue("draft")
+
+	up := tea.KeyMsg{Type: tea.KeyUp}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+
+	m, _ = m.Update(up)
+	if m.Value() != "second" {
+		t.Fatalf("after Up = %q, want second", m.Value())
+	}
+	m, _ = m.Update(up)
+	m, _ = m.Update(up) // stops at the oldest prompt
+	if m.Value() != "first" {
+		t.Fatalf("after Up x3 = %q, want first", m.Value())
+	}
+	m, _ = m.Update(down)
+	m, _ = m.Update(down)
+	if m.Value() != "draft" {
+		t.Fatalf("after Down back to the end = %q, want the draft", m.Value())
+	}
+
+	// History is per session
+	m.SetSession("s2")
+	m.Reset()
+	m, _ = m.Update(up)
+	if m.Value() != "" {
+		t.Errorf("s2 history should be empty, got %q", m.Value())
+	}
+}
+
+func TestLongMultilineInput(t *testing.T) {
+	m := New(40)
+	m.Focus()
+
+	long := strings.Repeat("x", 5000) + "\n" + "trace line"
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(long), Paste: true})
+	if m.Value() != long {
+		t.Errorf("pasted %d chars, kept %d", len(long), len(m.Value()))
+	}
+	if m.Height() != MaxRows {
+		t.Errorf("Height = %d, want capped at %d", m.Height(), MaxRows)
+	}
+
+	m.Reset()
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter, Alt: true})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if m.Value() != "a\nb" || m.Height() != 2 {
+		t.Errorf("Value = %q, Height = %d; want a\\nb on 2 rows", m.Value(), m.Height())
+	}
+}
@@ -0,0 +1,156 @@
+// Package modelpicker provides the list used to choose a session's model.
+package modelpicker
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/e-9/copilot-icq/internal/ui/theme"
+)
+
+// Option is a model the user can pick.
+type Option struct {
+	ID   string
+	Name string
+}
+
+// Picker lists the available models with the session's current one marked.
+// It starts out loading until SetOptions is called.
+type Picker struct {
+	current   string
+	options   []Option
+	cursor    int
+	width     int
+	height    int
+	loading   bool
+	err       string
+	chosen    *string
+	cancelled bool
+}
+
+// New creates a picker for a session currently using the given model.
+func New(current string, width, height int) Picker {
+	return Picker{current: current, width: width, height: height, loading: true}
+}
+
+// SetOptions fills the list once the models have loaded, or shows why
+// they could not be.
+func (p *Picker) SetOptions(opts []Option, err error) {
+	p.loading = false
+	if err != nil {
+		p.err = err.Error()
+		return
+	}
+	p.options = opts
+	for i, o := range opts {
+		if o.ID == p.current {
+			p.cursor = i
+		}
+	}
+}
+
+// SetSize updates the picker dimensions.
+func (p *Picker) SetSize(w, h int) {
+	p.width = w
+	p.height = h
+}
+
+// Chosen returns the picked model ID once the user has made a choice.
+func (p Picker) Chosen() (string, bool) {
+	if p.chosen == nil {
+		return "", false
+	}
+	return *p.chosen, true
+}
+
+// Cancelled reports whether the user closed the picker.
+func (p Picker) Cancelled() bool {
+	return p.cancelled
+}
+
+// Update handles key presses for the picker.
+func (p Picker) Update(msg tea.Msg) (Picker, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+
+	switch key.String() {
+	case "esc", "q":
+		p.cancelled = true
+	case "up", "k":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down", "j":
+		if p.cursor < len(p.options)-1 {
+			p.cursor++
+		}
+	case "enter":
+		if len(p.options) > 0 {
+			id := p.options[p.cursor].ID
+			p.chosen = &id
+		}
+	}
+	return p, nil
+}
+
+var (
+	currentStyle  = lipgloss.NewStyle().Foreground(theme.Accent)
+	selectedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("0")).
+			Background(theme.Accent).
+			Bold(true)
+	normalStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	dimStyle    = lipgloss.NewStyle().Foreground(theme.Subtle)
+	errorStyle  = lipgloss.NewStyle().Foreground(theme.Error)
+)
+
+// View renders the picker.
+func (p Picker) View() string {
+	switch {
+	case p.loading:
+		return dimStyle.Render("  ⏳ Loading models...")
+	case p.err != "":
+		return errorStyle.Render("  ⚠️  "+p.err) + "\n\n" + dimStyle.Render("  Esc close")
+	case len(p.options) == 0:
+		return dimStyle.Render("  No models available") + "\n\n" + dimStyle.Render("  Esc close")
+	}
+
+	// Keep the cursor on screen when the list is taller than the panel
+	rows := p.height - 2
+	if rows < 1 {
+		rows = 1
+	}
+	start := 0
+	if p.cursor >= rows {
+		start = p.cursor - rows + 1
+	}
+	end := start + rows
+	if end > len(p.options) {
+		end = len(p.options)
+	}
+
+	var sb strings.Builder
+	for i := start; i < end; i++ {
+		o := p.options[i]
+		marker := "  "
+		if o.ID == p.current {
+			marker = currentStyle.Render("● ")
+		}
+		label := o.ID
+		if o.Name != "" && o.Name != o.ID {
+			label = fmt.Sprintf("%s  %s", o.ID, dimStyle.Render(o.Name))
+		}
+		if i == p.cursor {
+			label = selectedStyle.Render(" " + o.ID + " ")
+		} else {
+			label = normalStyle.Render(" ") + label
+		}
+		sb.WriteString("  " + marker + label + "\n")
+	}
+	sb.WriteString("\n" + dimStyle.Render("  ↑↓ move · Enter use model · Esc cancel · ● current"))
+	return lipgloss.NewStyle().Width(p.width).Render(sb.String())
+}
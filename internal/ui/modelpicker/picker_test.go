@@ -0,0 +1,61 @@
+package modelpicker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func press(p Picker, keys ...tea.KeyMsg) Picker {
+	for _, k := range keys {
+		p, _ = p.Update(k)
+	}
+	return p
+}
+
+var (
+	up    = tea.KeyMsg{Type: tea.KeyUp}
+	down  = tea.KeyMsg{Type: tea.KeyDown}
+	enter = tea.KeyMsg{Type: tea.KeyEnter}
+)
+
+func TestPickerChoosesModel(t *testing.T) {
+	p := New("gpt-5", 40, 10)
+	if p = press(p, enter); !strings.Contains(p.View(), "Loading") {
+		t.Fatal("the picker should show it is loading")
+	}
+	if _, ok := p.Chosen(); ok {
+		t.Fatal("nothing can be chosen before the models load")
+	}
+
+	p.SetOptions([]Option{{ID: "claude-sonnet-4.5"}, {ID: "gpt-5"}, {ID: "o3"}}, nil)
+	if id, ok := press(p, enter).Chosen(); !ok || id != "gpt-5" {
+		t.Errorf("Enter chose %q, want the current model", id)
+	}
+	// The cursor stops at both ends of the list
+	if id, _ := press(p, down, down, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}, enter).Chosen(); id != "o3" {
+		t.Errorf("chose %q, want o3", id)
+	}
+	if id, _ := press(p, up, up, up, enter).Chosen(); id != "claude-sonnet-4.5" {
+		t.Errorf("chose %q, want claude-sonnet-4.5", id)
+	}
+	if p = press(p, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); !p.Cancelled() {
+		t.Error("q should close the picker")
+	}
+}
+
+func TestPickerError(t *testing.T) {
+	p := New("", 40, 10)
+	p.SetOptions(nil, errors.New("not signed in"))
+	if !strings.Contains(p.View(), "not signed in") {
+		t.Errorf("view should show the error:\n%s", p.View())
+	}
+	if _, ok := press(p, enter).Chosen(); ok {
+		t.Error("Enter should not choose without options")
+	}
+	if p = press(p, tea.KeyMsg{Type: tea.KeyEsc}); !p.Cancelled() {
+		t.Error("Esc should close the picker")
+	}
+}
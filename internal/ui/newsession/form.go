@@ -0,0 +1,180 @@
+// Package newsession provides the form for starting a brand-new Copilot session.
+package newsession
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/e-9/copilot-icq/internal/ui/theme"
+)
+
+// Result holds the values the user submitted.
+type Result struct {
+	CWD          string
+	Model        string
+	SystemPrompt string
+}
+
+const (
+	fieldCWD = iota
+	fieldModel
+	fieldPrompt
+	fieldCount
+)
+
+var fieldLabels = [fieldCount]string{
+	fieldCWD:    "Working directory",
+	fieldModel:  "Model (optional)",
+	fieldPrompt: "System prompt (optional)",
+}
+
+// Form is the new-session form: a working directory, an optional model and
+// an optional system prompt.
+type Form struct {
+	fields    [fieldCount]textinput.Model
+	cursor    int
+	width     int
+	busy      bool
+	err       string
+	submitted *Result
+	cancelled bool
+}
+
+// New creates a form with the working directory and model prefilled.
+func New(cwd, model string, width int) Form {
+	placeholders := [fieldCount]string{
+		fieldCWD:    "~/src/project",
+		fieldModel:  "CLI default",
+		fieldPrompt: "Extra instructions appended to the system message",
+	}
+
+	f := Form{width: width}
+	for i := range f.fields {
+		ti := textinput.New()
+		ti.Prompt = "❯ "
+		ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Accent)
+		ti.Placeholder = placeholders[i]
+		f.fields[i] = ti
+	}
+	f.fields[fieldModel].SetValue(model)
+	f.fields[fieldCWD].SetValue(cwd)
+	f.fields[fieldCWD].CursorEnd()
+	f.fields[fieldCWD].Focus()
+	f.SetWidth(width)
+	return f
+}
+
+// SetWidth updates the form width.
+func (f *Form) SetWidth(w int) {
+	f.width = w
+	for i := range f.fields {
+		f.fields[i].Width = w - 6
+	}
+}
+
+// SetBusy marks the form as waiting for the session to be created. Marking
+// it busy consumes the submission, so Submitted reports it only once.
+func (f *Form) SetBusy(busy bool) {
+	f.busy = busy
+	if busy {
+		f.submitted = nil
+	}
+}
+
+// SetError shows why creating the session failed and lets the user edit
+// the fields and submit again.
+func (f *Form) SetError(err error) {
+	f.busy = false
+	f.submitted = nil
+	f.err = ""
+	if err != nil {
+		f.err = err.Error()
+	}
+}
+
+// Submitted returns the field values once the user has submitted the form.
+func (f Form) Submitted() (Result, bool) {
+	if f.submitted == nil {
+		return Result{}, false
+	}
+	return *f.submitted, true
+}
+
+// Cancelled reports whether the user closed the form.
+func (f Form) Cancelled() bool {
+	return f.cancelled
+}
+
+// Update handles key presses for the form. Tab and the arrow keys move
+// between fields, Enter submits and Esc cancels.
+func (f Form) Update(msg tea.Msg) (Form, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || f.busy {
+		return f, nil
+	}
+
+	switch key.String() {
+	case "esc":
+		f.cancelled = true
+		return f, nil
+	case "enter":
+		res := Result{
+			CWD:          strings.TrimSpace(f.fields[fieldCWD].Value()),
+			Model:        strings.TrimSpace(f.fields[fieldModel].Value()),
+			SystemPrompt: strings.TrimSpace(f.fields[fieldPrompt].Value()),
+		}
+		if res.CWD == "" {
+			f.err = "working directory is required"
+			return f, f.focus(fieldCWD)
+		}
+		f.err = ""
+		f.submitted = &res
+		return f, nil
+	case "tab", "down":
+		return f, f.focus((f.cursor + 1) % fieldCount)
+	case "shift+tab", "up":
+		return f, f.focus((f.cursor + fieldCount - 1) % fieldCount)
+	}
+
+	var cmd tea.Cmd
+	f.fields[f.cursor], cmd = f.fields[f.cursor].Update(msg)
+	return f, cmd
+}
+
+func (f *Form) focus(i int) tea.Cmd {
+	f.fields[f.cursor].Blur()
+	f.cursor = i
+	return f.fields[i].Focus()
+}
+
+var (
+	labelStyle       = lipgloss.NewStyle().Foreground(theme.Subtle)
+	activeLabelStyle = lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+	errorStyle       = lipgloss.NewStyle().Foreground(theme.Error)
+	hintStyle        = lipgloss.NewStyle().Foreground(theme.Subtle).Italic(true)
+)
+
+// View renders the form.
+func (f Form) View() string {
+	var sb strings.Builder
+	for i, field := range f.fields {
+		label := labelStyle
+		if i == f.cursor {
+			label = activeLabelStyle
+		}
+		sb.WriteString("  " + label.Render(fieldLabels[i]) + "\n")
+		sb.WriteString("  " + field.View() + "\n\n")
+	}
+
+	switch {
+	case f.busy:
+		sb.WriteString(hintStyle.Render("  ⏳ Starting session..."))
+	case f.err != "":
+		sb.WriteString(errorStyle.Render("  ⚠️  " + f.err))
+	default:
+		sb.WriteString(hintStyle.Render("  Tab/↑↓ move · Enter start session · Esc cancel"))
+	}
+	return lipgloss.NewStyle().Width(f.width).Render(sb.String())
+}
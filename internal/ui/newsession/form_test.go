@@ -0,0 +1,59 @@
+package newsession
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func press(f Form, keys ...tea.KeyMsg) Form {
+	for _, k := range keys {
+		f, _ = f.Update(k)
+	}
+	return f
+}
+
+func typed(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+var (
+	enter = tea.KeyMsg{Type: tea.KeyEnter}
+	tab   = tea.KeyMsg{Type: tea.KeyTab}
+)
+
+func TestFormSubmit(t *testing.T) {
+	f := press(New("/src/app", "gpt-5", 80), tab, tab, typed("be brief"), enter)
+	res, ok := f.Submitted()
+	if !ok || res != (Result{CWD: "/src/app", Model: "gpt-5", SystemPrompt: "be brief"}) {
+		t.Fatalf("submitted %v %+v", ok, res)
+	}
+
+	// Creating the session consumes the submission and holds every key
+	f.SetBusy(true)
+	if _, ok := f.Submitted(); ok {
+		t.Error("a busy form should not report its submission again")
+	}
+	if f = press(f, tea.KeyMsg{Type: tea.KeyEsc}, enter); f.Cancelled() {
+		t.Error("keys should be ignored while the session is created")
+	}
+
+	// A failure lets the user fix the fields and submit again
+	f.SetError(errors.New("no such directory"))
+	f = press(f, enter)
+	if _, ok := f.Submitted(); !ok {
+		t.Error("the form should submit again after an error")
+	}
+}
+
+func TestFormNeedsDirectory(t *testing.T) {
+	f := New("", "", 80)
+	f = press(f, enter)
+	if _, ok := f.Submitted(); ok || f.err == "" {
+		t.Error("an empty working directory should not submit")
+	}
+	if f = press(f, tea.KeyMsg{Type: tea.KeyEsc}); !f.Cancelled() {
+		t.Error("Esc should cancel the form")
+	}
+}
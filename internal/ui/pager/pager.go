@@ -0,0 +1,84 @@
+// Package pager shows a long text full screen, such as the complete output
+// of a tool call.
+package pager
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/e-9/copilot-icq/internal/ui/theme"
+)
+
+// Model is a scrollable text under a title bar.
+type Model struct {
+	title  string
+	view   viewport.Model
+	width  int
+	closed bool
+}
+
+var (
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(theme.Accent).
+			Background(lipgloss.Color("236")).
+			Padding(0, 1)
+	footerStyle = lipgloss.NewStyle().Foreground(theme.Subtle)
+)
+
+// New creates a pager filling width × height, showing content from the top.
+func New(title, content string, width, height int) Model {
+	p := Model{title: title, view: viewport.New(width, 1)}
+	p.SetSize(width, height)
+	p.view.SetContent(content)
+	return p
+}
+
+// SetSize fits the pager to the screen, keeping a line for the title and
+// one for the footer.
+func (p *Model) SetSize(w, h int) {
+	p.width = w
+	p.view.Width = w
+	p.view.Height = max(h-2, 1)
+}
+
+// SetContent replaces the text, e.g. re-wrapped after a resize.
+func (p *Model) SetContent(content string) {
+	p.view.SetContent(content)
+}
+
+// Closed reports whether the user closed the pager.
+func (p Model) Closed() bool {
+	return p.closed
+}
+
+// Update scrolls on the viewport's keys, g and G jump to the top and
+// bottom, and q or Esc close the pager.
+func (p Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "q", "esc", "enter":
+			p.closed = true
+			return p, nil
+		case "g", "home":
+			p.view.GotoTop()
+			return p, nil
+		case "G", "end":
+			p.view.GotoBottom()
+			return p, nil
+		}
+	}
+	var cmd tea.Cmd
+	p.view, cmd = p.view.Update(msg)
+	return p, cmd
+}
+
+// View renders the pager.
+func (p Model) View() string {
+	title := titleStyle.Width(p.width).MaxWidth(p.width).Render(p.title)
+	footer := footerStyle.Render(fmt.Sprintf(" ↑↓ PgUp PgDn scroll · g/G top/bottom · q close   %3.f%%", p.view.ScrollPercent()*100))
+	return title + "\n" + p.view.View() + "\n" + footer
+}
@@ -0,0 +1,71 @@
+package pager
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func press(p Model, keys ...string) Model {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		p, _ = p.Update(msg)
+	}
+	return p
+}
+
+func TestPagerScrollsAndCloses(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 50; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	p := New("bash · go test", strings.Join(lines, "\n"), 40, 12)
+
+	view := ansi.Strip(p.View())
+	rows := strings.Split(view, "\n")
+	if len(rows) != 12 || !strings.Contains(rows[0], "bash · go test") || !strings.Contains(rows[1], "line 1") {
+		t.Fatalf("the pager should fill the screen from the top:\n%s", view)
+	}
+	if !strings.Contains(rows[11], "q close") || !strings.Contains(rows[11], "0%") {
+		t.Errorf("footer = %q", rows[11])
+	}
+
+	p = press(p, "down")
+	if !strings.Contains(ansi.Strip(p.View()), "line 11") {
+		t.Error("↓ should scroll a line")
+	}
+	p = press(p, "G")
+	if view := ansi.Strip(p.View()); !strings.Contains(view, "line 50") || !strings.Contains(view, "100%") {
+		t.Errorf("G should show the end:\n%s", view)
+	}
+	p = press(p, "g")
+	if rows := strings.Split(ansi.Strip(p.View()), "\n"); strings.TrimSpace(rows[1]) != "line 1" {
+		t.Error("g should show the top")
+	}
+
+	// A taller screen shows more of the text
+	p.SetSize(40, 30)
+	if !strings.Contains(ansi.Strip(p.View()), "line 28") {
+		t.Error("the pager should grow with the screen")
+	}
+
+	for _, k := range []string{"q", "esc"} {
+		if !press(p, k).Closed() {
+			t.Errorf("%s should close the pager", k)
+		}
+	}
+	if p.Closed() {
+		t.Error("scrolling should not close the pager")
+	}
+}
@@ -0,0 +1,253 @@
+// Package prompt provides the inline dialogs shown beneath the chat panel
+// when an agent is blocked waiting on the user.
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/e-9/copilot-icq/internal/ui/theme"
+)
+
+// Decision is the user's answer to a tool approval request.
+type Decision int
+
+const (
+	AllowOnce Decision = iota
+	AllowSession
+	Deny
+	DenyWithReason
+)
+
+// ApprovalRequest describes the tool the agent wants to run.
+type ApprovalRequest struct {
+	ToolName string
+	Kind     string
+	Args     string
+}
+
+// ApprovalResult is returned once the user has decided.
+type ApprovalResult struct {
+	Decision Decision
+	Reason   string
+}
+
+type choice struct {
+	key      string
+	label    string
+	decision Decision
+}
+
+var approvalChoices = []choice{
+	{"a", "Allow once", AllowOnce},
+	{"s", "Allow for session", AllowSession},
+	{"d", "Deny", Deny},
+	{"r", "Deny with reason", DenyWithReason},
+}
+
+// maxArgLines caps how much of the tool arguments the dialog shows.
+const maxArgLines = 6
+
+// Approval is the tool permission dialog.
+type Approval struct {
+	req       ApprovalRequest
+	cursor    int
+	queued    int
+	width     int
+	reasoning bool // typing a deny reason
+	reason    textinput.Model
+	result    *ApprovalResult
+}
+
+// NewApproval creates a dialog for the given request.
+func NewApproval(req ApprovalRequest, width int) Approval {
+	ti := textinput.New()
+	ti.Placeholder = "Why is this denied? (Enter to send, Esc to go back)"
+	ti.Prompt = "❯ "
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Error)
+	ti.Width = width - 6
+
+	return Approval{req: req, width: width, reason: ti}
+}
+
+// SetWidth updates the dialog width.
+func (a *Approval) SetWidth(w int) {
+	a.width = w
+	a.reason.Width = w - 6
+}
+
+// SetQueued sets how many further requests are waiting behind this one.
+func (a *Approval) SetQueued(n int) {
+	a.queued = n
+}
+
+// Answered returns the user's decision once one has been made.
+func (a Approval) Answered() (ApprovalResult, bool) {
+	if a.result == nil {
+		return ApprovalResult{}, false
+	}
+	return *a.result, true
+}
+
+// Handles reports whether the dialog consumes the given key.
+// Keys it does not handle fall through to the normal app bindings.
+func (a Approval) Handles(msg tea.KeyMsg) bool {
+	if a.reasoning {
+		return msg.String() != "ctrl+c"
+	}
+	switch msg.String() {
+	case "left", "right", "h", "l", "enter", "1", "2", "3", "4":
+		return true
+	}
+	for _, c := range approvalChoices {
+		if msg.String() == c.key {
+			return true
+		}
+	}
+	return false
+}
+
+// Update handles key presses for the dialog.
+func (a Approval) Update(msg tea.Msg) (Approval, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return a, nil
+	}
+
+	if a.reasoning {
+		switch key.String() {
+		case "esc":
+			a.reasoning = false
+			a.reason.Blur()
+			return a, nil
+		case "enter":
+			a.result = &ApprovalResult{Decision: DenyWithReason, Reason: strings.TrimSpace(a.reason.Value())}
+			return a, nil
+		}
+		var cmd tea.Cmd
+		a.reason, cmd = a.reason.Update(msg)
+		return a, cmd
+	}
+
+	switch key.String() {
+	case "left", "h":
+		if a.cursor > 0 {
+			a.cursor--
+		}
+		return a, nil
+	case "right", "l":
+		if a.cursor < len(approvalChoices)-1 {
+			a.cursor++
+		}
+		return a, nil
+	case "enter":
+		return a.choose(approvalChoices[a.cursor].decision)
+	case "1", "2", "3", "4":
+		i := int(key.String()[0] - '1')
+		a.cursor = i
+		return a.choose(approvalChoices[i].decision)
+	}
+	for i, c := range approvalChoices {
+		if key.String() == c.key {
+			a.cursor = i
+			return a.choose(c.decision)
+		}
+	}
+	return a, nil
+}
+
+func (a Approval) choose(d Decision) (Approval, tea.Cmd) {
+	if d == DenyWithReason {
+		a.reasoning = true
+		return a, a.reason.Focus()
+	}
+	a.result = &ApprovalResult{Decision: d}
+	return a, nil
+}
+
+var (
+	dialogTitleStyle = lipgloss.NewStyle().Foreground(theme.Warning).Bold(true)
+	dialogLabelStyle = lipgloss.NewStyle().Foreground(theme.Subtle)
+	dialogArgsStyle  = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("251")).
+				Background(lipgloss.Color("237")).
+				Padding(0, 1)
+	choiceStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Padding(0, 1)
+	selectedChoiceStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("0")).
+				Background(theme.Accent).
+				Bold(true).
+				Padding(0, 1)
+)
+
+// View renders the dialog.
+func (a Approval) View() string {
+	var sb strings.Builder
+
+	title := fmt.Sprintf("⚡ Copilot wants to run %s", a.req.ToolName)
+	if a.queued > 0 {
+		title += dialogLabelStyle.Render(fmt.Sprintf("  (+%d more waiting)", a.queued))
+	}
+	sb.WriteString(dialogTitleStyle.Render(title) + "\n")
+	if a.req.Kind != "" && a.req.Kind != a.req.ToolName {
+		sb.WriteString(dialogLabelStyle.Render("  kind: "+a.req.Kind) + "\n")
+	}
+
+	for _, line := range argLines(a.req.Args, a.width-6) {
+		sb.WriteString("  " + dialogArgsStyle.Render(line) + "\n")
+	}
+
+	if a.reasoning {
+		sb.WriteString(a.reason.View())
+		return sb.String()
+	}
+
+	var choices []string
+	for i, c := range approvalChoices {
+		label := fmt.Sprintf("%d %s", i+1, c.label)
+		if i == a.cursor {
+			choices = append(choices, selectedChoiceStyle.Render(label))
+		} else {
+			choices = append(choices, choiceStyle.Render(label))
+		}
+	}
+	row := strings.Join(choices, " ")
+	if lipgloss.Width(row) > a.width {
+		row = strings.Join(choices, "\n")
+	}
+	sb.WriteString(row)
+	return sb.String()
+}
+
+// Height returns the number of lines View renders.
+func (a Approval) Height() int {
+	return lipgloss.Height(a.View())
+}
+
+// argLines splits tool arguments into at most maxArgLines display lines.
+func argLines(args string, width int) []string {
+	args = strings.TrimSpace(args)
+	if args == "" {
+		return nil
+	}
+	if width < 10 {
+		width = 10
+	}
+	var lines []string
+	for _, l := range strings.Split(args, "\n") {
+		r := []rune(l)
+		for len(r) > width {
+			lines = append(lines, string(r[:width]))
+			r = r[width:]
+		}
+		lines = append(lines, string(r))
+	}
+	if len(lines) > maxArgLines {
+		more := len(lines) - maxArgLines + 1
+		lines = append(lines[:maxArgLines-1], fmt.Sprintf("... +%d more lines", more))
+	}
+	return lines
+}
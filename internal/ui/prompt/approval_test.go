@@ -0,0 +1,81 @@
+package prompt
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "right":
+		return tea.KeyMsg{Type: tea.KeyRight}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "ctrl+c":
+		return tea.KeyMsg{Type: tea.KeyCtrlC}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func press(a Approval, keys ...string) Approval {
+	for _, k := range keys {
+		a, _ = a.Update(key(k))
+	}
+	return a
+}
+
+func TestApprovalKeys(t *testing.T) {
+	req := ApprovalRequest{ToolName: "bash", Kind: "shell", Args: "ls"}
+	tests := []struct {
+		keys []string
+		want Decision
+	}{
+		{[]string{"a"}, AllowOnce},
+		{[]string{"s"}, AllowSession},
+		{[]string{"3"}, Deny},
+		{[]string{"enter"}, AllowOnce},
+		{[]string{"right", "l", "enter"}, Deny},
+	}
+	for _, tt := range tests {
+		res, ok := press(NewApproval(req, 80), tt.keys...).Answered()
+		if !ok || res.Decision != tt.want {
+			t.Errorf("%v: answered %v %+v, want %v", tt.keys, ok, res, tt.want)
+		}
+	}
+
+	a := NewApproval(req, 80)
+	for _, k := range []string{"t", "q", "esc", "ctrl+c"} {
+		if a.Handles(key(k)) {
+			t.Errorf("%q should fall through to the app", k)
+		}
+	}
+}
+
+func TestApprovalDenyWithReason(t *testing.T) {
+	a := press(NewApproval(ApprovalRequest{ToolName: "bash"}, 80), "r")
+	if _, ok := a.Answered(); ok {
+		t.Fatal("r should ask for a reason before answering")
+	}
+	// While typing, letters go to the reason and Esc goes back to the choices
+	if !a.Handles(key("q")) || !a.Handles(key("esc")) || a.Handles(key("ctrl+c")) {
+		t.Error("the reason prompt should take every key but Ctrl+C")
+	}
+	if a = press(a, "esc"); a.reasoning {
+		t.Fatal("Esc should leave the reason prompt")
+	}
+
+	a = press(a, "4", "n", "o", "enter")
+	res, ok := a.Answered()
+	if !ok || res.Decision != DenyWithReason || res.Reason != "no" {
+		t.Errorf("answered %v %+v, want deny with reason %q", ok, res, "no")
+	}
+}
@@ -0,0 +1,201 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/e-9/copilot-icq/internal/ui/theme"
+)
+
+// QuestionRequest is an ask_user question from the agent.
+type QuestionRequest struct {
+	Question      string
+	Choices       []string
+	AllowFreeform bool
+}
+
+// QuestionResult is the user's answer.
+type QuestionResult struct {
+	Answer      string
+	WasFreeform bool
+}
+
+// Question is the ask_user dialog: a selectable list of choices with
+// numeric hotkeys, plus a freeform text box when the agent allows one.
+type Question struct {
+	req      QuestionRequest
+	cursor   int // index into choices; len(choices) is the freeform box
+	queued   int
+	width    int
+	freeform textinput.Model
+	result   *QuestionResult
+}
+
+// NewQuestion creates a dialog for the given question.
+func NewQuestion(req QuestionRequest, width int) Question {
+	ti := textinput.New()
+	ti.Placeholder = "Type an answer..."
+	ti.Prompt = "❯ "
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Accent)
+	ti.Width = width - 6
+
+	// A question without choices can only be answered in free form.
+	if len(req.Choices) == 0 {
+		req.AllowFreeform = true
+	}
+	q := Question{req: req, width: width, freeform: ti}
+	if len(req.Choices) == 0 {
+		q.focusFreeform()
+	}
+	return q
+}
+
+// SetWidth updates the dialog width.
+func (q *Question) SetWidth(w int) {
+	q.width = w
+	q.freeform.Width = w - 6
+}
+
+// SetQueued sets how many further questions are waiting behind this one.
+func (q *Question) SetQueued(n int) {
+	q.queued = n
+}
+
+// Answered returns the user's answer once one has been given.
+func (q Question) Answered() (QuestionResult, bool) {
+	if q.result == nil {
+		return QuestionResult{}, false
+	}
+	return *q.result, true
+}
+
+// Handles reports whether the dialog consumes the given key.
+func (q Question) Handles(msg tea.KeyMsg) bool {
+	if q.onFreeform() {
+		switch msg.String() {
+		case "ctrl+c", "tab", "shift+tab":
+			return false
+		}
+		return true
+	}
+	switch msg.String() {
+	case "up", "down", "k", "j", "enter":
+		return true
+	}
+	_, ok := q.hotkey(msg)
+	return ok
+}
+
+// Update handles key presses for the dialog.
+func (q Question) Update(msg tea.Msg) (Question, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return q, nil
+	}
+
+	if q.onFreeform() {
+		switch key.String() {
+		case "enter":
+			if answer := strings.TrimSpace(q.freeform.Value()); answer != "" {
+				q.result = &QuestionResult{Answer: answer, WasFreeform: true}
+			}
+			return q, nil
+		case "up":
+			if len(q.req.Choices) > 0 {
+				q.cursor--
+				q.freeform.Blur()
+			}
+			return q, nil
+		}
+		var cmd tea.Cmd
+		q.freeform, cmd = q.freeform.Update(msg)
+		return q, cmd
+	}
+
+	switch key.String() {
+	case "up", "k":
+		if q.cursor > 0 {
+			q.cursor--
+		}
+		return q, nil
+	case "down", "j":
+		if q.cursor < q.lastIndex() {
+			q.cursor++
+		}
+		if q.onFreeform() {
+			return q, q.focusFreeform()
+		}
+		return q, nil
+	case "enter":
+		q.result = &QuestionResult{Answer: q.req.Choices[q.cursor]}
+		return q, nil
+	}
+	if i, ok := q.hotkey(key); ok {
+		q.cursor = i
+		q.result = &QuestionResult{Answer: q.req.Choices[i]}
+	}
+	return q, nil
+}
+
+// hotkey maps the keys 1-9 to a choice index.
+func (q Question) hotkey(msg tea.KeyMsg) (int, bool) {
+	s := msg.String()
+	if len(s) != 1 || s[0] < '1' || s[0] > '9' {
+		return 0, false
+	}
+	i := int(s[0] - '1')
+	return i, i < len(q.req.Choices)
+}
+
+func (q Question) lastIndex() int {
+	if q.req.AllowFreeform {
+		return len(q.req.Choices)
+	}
+	return len(q.req.Choices) - 1
+}
+
+func (q Question) onFreeform() bool {
+	return q.req.AllowFreeform && q.cursor == len(q.req.Choices)
+}
+
+func (q *Question) focusFreeform() tea.Cmd {
+	q.cursor = len(q.req.Choices)
+	return q.freeform.Focus()
+}
+
+var questionStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Bold(true)
+
+// View renders the dialog.
+func (q Question) View() string {
+	var sb strings.Builder
+
+	title := "❓ " + q.req.Question
+	if q.queued > 0 {
+		title = questionStyle.Render(title) + dialogLabelStyle.Render(fmt.Sprintf("  (+%d more waiting)", q.queued))
+	} else {
+		title = questionStyle.Render(title)
+	}
+	sb.WriteString(lipgloss.NewStyle().Width(q.width).Render(title))
+
+	for i, c := range q.req.Choices {
+		label := fmt.Sprintf("%d %s", i+1, c)
+		sb.WriteString("\n")
+		if i == q.cursor {
+			sb.WriteString("  " + selectedChoiceStyle.Render(label))
+		} else {
+			sb.WriteString("  " + choiceStyle.Render(label))
+		}
+	}
+	if q.req.AllowFreeform {
+		sb.WriteString("\n  " + q.freeform.View())
+	}
+	return sb.String()
+}
+
+// Height returns the number of lines View renders.
+func (q Question) Height() int {
+	return lipgloss.Height(q.View())
+}
@@ -0,0 +1,47 @@
+package prompt
+
+import "testing"
+
+func answer(q Question, keys ...string) (QuestionResult, bool) {
+	for _, k := range keys {
+		q, _ = q.Update(key(k))
+	}
+	return q.Answered()
+}
+
+func TestQuestionKeys(t *testing.T) {
+	req := QuestionRequest{Question: "Which?", Choices: []string{"Jest", "Vitest"}, AllowFreeform: true}
+	tests := []struct {
+		keys []string
+		want QuestionResult
+	}{
+		{[]string{"2"}, QuestionResult{Answer: "Vitest"}},
+		{[]string{"j", "enter"}, QuestionResult{Answer: "Vitest"}},
+		{[]string{"down", "down", "j", "k", "enter"}, QuestionResult{Answer: "jk", WasFreeform: true}},
+		{[]string{"down", "down", "up", "enter"}, QuestionResult{Answer: "Vitest"}},
+	}
+	for _, tt := range tests {
+		res, ok := answer(NewQuestion(req, 80), tt.keys...)
+		if !ok || res != tt.want {
+			t.Errorf("%v: answered %v %+v, want %+v", tt.keys, ok, res, tt.want)
+		}
+	}
+
+	q := NewQuestion(req, 80)
+	if q.Handles(key("3")) || q.Handles(key("q")) {
+		t.Error("keys without a choice should fall through to the app")
+	}
+	if _, ok := answer(q, "down", "down", "enter"); ok {
+		t.Error("an empty freeform answer should not be sent")
+	}
+}
+
+func TestQuestionWithoutChoices(t *testing.T) {
+	q := NewQuestion(QuestionRequest{Question: "Name?"}, 80)
+	if !q.Handles(key("1")) || q.Handles(key("tab")) {
+		t.Error("a freeform-only question should take typing but leave Tab to the app")
+	}
+	if res, ok := answer(q, "1", "enter"); !ok || res != (QuestionResult{Answer: "1", WasFreeform: true}) {
+		t.Errorf("answered %v %+v", ok, res)
+	}
+}
@@ -0,0 +1,188 @@
+// Package queue renders the prompts waiting to be sent to a busy session and
+// lets the user pick one to edit, delete or reorder.
+package queue
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/e-9/copilot-icq/internal/ui/theme"
+)
+
+// Action is what the user asked to do with the selected prompt.
+type Action int
+
+const (
+	None Action = iota
+	Edit
+	Delete
+	MoveUp
+	MoveDown
+	Close
+)
+
+// maxShown caps how many queued prompts are listed at once.
+const maxShown = 5
+
+// Model is the queued-prompt list shown above the composer.
+type Model struct {
+	items   []string
+	cursor  int
+	focused bool
+	width   int
+}
+
+// New creates an empty queue view.
+func New() Model {
+	return Model{}
+}
+
+// SetItems replaces the listed prompts, keeping the cursor in range.
+func (m *Model) SetItems(items []string) {
+	m.items = items
+	if m.cursor >= len(items) {
+		m.cursor = len(items) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	if len(items) == 0 {
+		m.focused = false
+	}
+}
+
+// SetWidth updates the list width.
+func (m *Model) SetWidth(w int) {
+	m.width = w
+}
+
+// Focus selects the list so its keys apply; it starts on the first prompt.
+func (m *Model) Focus() {
+	if len(m.items) > 0 {
+		m.focused = true
+		m.cursor = 0
+	}
+}
+
+// Blur hands the keys back to the composer.
+func (m *Model) Blur() {
+	m.focused = false
+}
+
+// Focused reports whether the list has the keys.
+func (m Model) Focused() bool {
+	return m.focused
+}
+
+// Cursor returns the index of the selected prompt.
+func (m Model) Cursor() int {
+	return m.cursor
+}
+
+// Select moves the cursor, e.g. after the app reordered the prompts.
+func (m *Model) Select(i int) {
+	if i >= 0 && i < len(m.items) {
+		m.cursor = i
+	}
+}
+
+// Update handles keys while the list is focused and reports the action the
+// app should apply to the prompt under the cursor.
+func (m Model) Update(msg tea.Msg) (Model, Action) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || !m.focused {
+		return m, None
+	}
+
+	switch key.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+	case "enter", "e":
+		return m, Edit
+	case "d", "x", "delete", "backspace":
+		return m, Delete
+	case "K", "shift+up":
+		return m, MoveUp
+	case "J", "shift+down":
+		return m, MoveDown
+	case "esc", "ctrl+o":
+		return m, Close
+	}
+	return m, None
+}
+
+var (
+	headerStyle   = lipgloss.NewStyle().Foreground(theme.Warning).Bold(true)
+	itemStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	selectedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("0")).
+			Background(theme.Accent)
+	hintStyle = lipgloss.NewStyle().Foreground(theme.Subtle)
+)
+
+// View renders the list, or nothing when the queue is empty.
+func (m Model) View() string {
+	if len(m.items) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	header := headerStyle.Render(fmt.Sprintf("⏭ %d queued", len(m.items)))
+	hint := "  sent when Copilot is idle · Ctrl+O edit queue · Ctrl+X cancel next"
+	if m.focused {
+		hint = "  ↑↓ select · e edit · d delete · K/J move · Esc back"
+	}
+	// Drop the hint rather than wrap it on narrow terminals
+	if lipgloss.Width(header+hint) <= m.width {
+		header += hintStyle.Render(hint)
+	}
+	sb.WriteString(header)
+
+	start := 0
+	if m.cursor >= maxShown {
+		start = m.cursor - maxShown + 1
+	}
+	end := min(start+maxShown, len(m.items))
+	for i := start; i < end; i++ {
+		line := fmt.Sprintf("%d. %s", i+1, preview(m.items[i], m.width-6))
+		sb.WriteString("\n  ")
+		if m.focused && i == m.cursor {
+			sb.WriteString(selectedStyle.Render(line))
+		} else {
+			sb.WriteString(itemStyle.Render(line))
+		}
+	}
+	if hidden := len(m.items) - (end - start); hidden > 0 {
+		sb.WriteString("\n  " + hintStyle.Render(fmt.Sprintf("… %d more", hidden)))
+	}
+	return sb.String()
+}
+
+// Height returns the number of lines View renders.
+func (m Model) Height() int {
+	if len(m.items) == 0 {
+		return 0
+	}
+	return lipgloss.Height(m.View())
+}
+
+// preview flattens a prompt to one line of at most width runes.
+func preview(s string, width int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if width < 10 {
+		width = 10
+	}
+	r := []rune(s)
+	if len(r) > width {
+		return string(r[:width-1]) + "…"
+	}
+	return s
+}
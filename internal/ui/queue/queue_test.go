@@ -0,0 +1,66 @@
+package queue
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func typed(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestQueueKeys(t *testing.T) {
+	m := New()
+	m.SetWidth(60)
+	m.SetItems([]string{"first", "second", "third"})
+	if _, a := m.Update(typed("d")); a != None {
+		t.Error("an unfocused list should ignore keys")
+	}
+
+	m.Focus()
+	tests := []struct {
+		key    tea.KeyMsg
+		action Action
+		cursor int
+	}{
+		{typed("j"), None, 1},
+		{tea.KeyMsg{Type: tea.KeyDown}, None, 2},
+		{typed("j"), None, 2},
+		{typed("K"), MoveUp, 2},
+		{typed("k"), None, 1},
+		{typed("J"), MoveDown, 1},
+		{typed("e"), Edit, 1},
+		{tea.KeyMsg{Type: tea.KeyBackspace}, Delete, 1},
+		{tea.KeyMsg{Type: tea.KeyEsc}, Close, 1},
+	}
+	for _, tt := range tests {
+		var a Action
+		m, a = m.Update(tt.key)
+		if a != tt.action || m.Cursor() != tt.cursor {
+			t.Errorf("%q: action %v, cursor %d; want %v, %d", tt.key, a, m.Cursor(), tt.action, tt.cursor)
+		}
+	}
+}
+
+func TestQueueItems(t *testing.T) {
+	m := New()
+	m.Focus()
+	if m.Focused() || m.View() != "" || m.Height() != 0 {
+		t.Error("an empty queue should take no focus and show nothing")
+	}
+
+	m.SetItems([]string{"first", "second"})
+	m.Focus()
+	m.Select(1)
+	m.SetItems([]string{"first"})
+	if m.Cursor() != 0 || !m.Focused() {
+		t.Errorf("cursor = %d, want it kept in range", m.Cursor())
+	}
+	if m.SetItems(nil); m.Focused() {
+		t.Error("emptying the queue should hand the keys back")
+	}
+	if got := preview("fix\n  the   tests please", 10); got != "fix the t…" {
+		t.Errorf("preview = %q", got)
+	}
+}
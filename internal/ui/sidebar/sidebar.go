@@ -28,6 +28,13 @@ type ItemDelegate struct {
 	Unread       map[string]int
 	LastSeen     map[string]time.Time
 	PendingSends map[string]bool
+	Approvals    map[string]int // sessionID → permission requests and questions awaiting an answer
+	Archived     map[string]bool
+	Drafts       map[string]bool   // sessionID → has an unsent draft
+	Queued       map[string]int    // sessionID → prompts waiting for the session to go idle
+	Marked       map[string]bool   // sessionID → selected for a broadcast
+	Muted        map[string]bool   // sessionID → desktop notifications muted
+	Activity     map[string]string // sessionID → what a session in another terminal is doing
 	ActiveID     string
 }
 
@@ -43,6 +50,13 @@ func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 
 	title := item.Title()
 	desc := item.Session.ShortID() + " · " + shortenPath(item.Session.CWD, 20)
+	if model := item.Session.Model; model != "" {
+		// Give the model priority and shorten the path into what is left
+		desc = item.Session.ShortID() + " · " + model
+		if room := m.Width() - lipgloss.Width(desc) - 6; room >= 8 {
+			desc += " · " + shortenPath(item.Session.CWD, room)
+		}
+	}
 
 	// Active indicator: session seen in the last 30 seconds
 	isActive := false
@@ -51,6 +65,9 @@ func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 			isActive = time.Since(t) < 30*time.Second
 		}
 	}
+	if act := d.Activity[item.Session.ID]; act != "" && isActive {
+		desc = item.Session.ShortID() + " · " + act
+	}
 
 	// Unread badge
 	badge := ""
@@ -65,17 +82,34 @@ func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		icon = "◉"
 	}
 
-	// Status icon for non-active sessions: ⏳ in-progress, 🔔 has response
+	// Draft marker: the active session's draft is already in the composer
+	if d.Drafts[item.Session.ID] && item.Session.ID != d.ActiveID {
+		badge += theme.DraftBadgeStyle.Render(" ✎")
+	}
+
+	// Queue badge: prompts that will be sent once Copilot is idle
+	if n := d.Queued[item.Session.ID]; n > 0 {
+		badge += theme.QueueBadgeStyle.Render(fmt.Sprintf(" ⏭%d", n))
+	}
+
+	// Approval badge: shown even for the active session, since it blocks the agent
+	if n := d.Approvals[item.Session.ID]; n > 0 {
+		badge += theme.ApprovalBadgeStyle.Render(fmt.Sprintf(" ⚡%d", n))
+	}
+
+	// Status icon for non-active sessions: ⚡ needs approval, ⏳ in-progress, 🔔 has response
 	statusIcon := ""
 	curIsActive := item.Session.ID == d.ActiveID
 	if !curIsActive {
-		if d.PendingSends[item.Session.ID] {
+		if d.Approvals[item.Session.ID] > 0 {
+			statusIcon = "⚡"
+		} else if d.PendingSends[item.Session.ID] {
 			statusIcon = "⏳"
 		} else if d.Unread[item.Session.ID] > 0 {
 			statusIcon = "🔔"
 		}
 	}
-	curUnread := d.Unread[item.Session.ID] > 0
+	curUnread := d.needsAttention(item.Session.ID)
 
 	// Group separator with section header labels
 	separator := ""
@@ -90,7 +124,7 @@ func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	} else if d.ActiveID != "" {
 		prevItem, ok := m.Items()[index-1].(Item)
 		if ok {
-			prevUnread := d.Unread[prevItem.Session.ID] > 0
+			prevUnread := d.needsAttention(prevItem.Session.ID)
 			prevIsActive := prevItem.Session.ID == d.ActiveID
 
 			if prevIsActive && !curIsActive {
@@ -109,6 +143,15 @@ func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	if statusIcon != "" {
 		prefix = icon + " " + statusIcon + " "
 	}
+	if d.Archived[item.Session.ID] {
+		prefix += "🗄 "
+	}
+	if d.Muted[item.Session.ID] {
+		prefix += "🔕 "
+	}
+	if d.Marked[item.Session.ID] {
+		prefix = "✔ " + prefix
+	}
 
 	if index == m.Index() {
 		title = theme.SelectedItemStyle.Render(prefix+title) + badge
@@ -121,6 +164,11 @@ func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	fmt.Fprintf(w, "%s%s\n%s", separator, title, desc)
 }
 
+// needsAttention reports whether a session belongs in the Notifications group.
+func (d ItemDelegate) needsAttention(id string) bool {
+	return d.Unread[id] > 0 || d.Approvals[id] > 0
+}
+
 // Model wraps the bubbles list component for our sidebar.
 type Model struct {
 	List     list.Model
@@ -128,6 +176,8 @@ type Model struct {
 	Height   int
 	delegate *ItemDelegate
 	activeID string // currently viewed session ID
+
+	showArchived bool
 }
 
 // New creates a new sidebar model.
@@ -140,6 +190,7 @@ func New(sessions []domain.Session, width, height int) Model {
 	d := &ItemDelegate{
 		Unread:   make(map[string]int),
 		LastSeen: make(map[string]time.Time),
+		Marked:   make(map[string]bool),
 	}
 
 	l := list.New(items, d, width, height)
@@ -182,6 +233,74 @@ func (m *Model) SetPendingSends(pending map[string]bool) {
 	m.delegate.PendingSends = pending
 }
 
+// SetApprovals updates the per-session count of requests awaiting the user.
+func (m *Model) SetApprovals(approvals map[string]int) {
+	m.delegate.Approvals = approvals
+}
+
+// SetArchived updates which sessions are archived. Archived sessions are
+// hidden unless ShowArchived is on; call SetItems afterwards to re-filter.
+func (m *Model) SetArchived(archived map[string]bool) {
+	m.delegate.Archived = archived
+}
+
+// SetMuted updates which sessions have their notifications muted.
+func (m *Model) SetMuted(muted map[string]bool) {
+	m.delegate.Muted = muted
+}
+
+// SetDrafts updates which sessions have an unsent draft.
+func (m *Model) SetDrafts(drafts map[string]bool) {
+	m.delegate.Drafts = drafts
+}
+
+// SetActivity updates what sessions running outside the TUI are doing.
+// It is shown while the session is active, in place of its path.
+func (m *Model) SetActivity(activity map[string]string) {
+	m.delegate.Activity = activity
+}
+
+// SetQueued updates the per-session count of queued prompts.
+func (m *Model) SetQueued(queued map[string]int) {
+	m.delegate.Queued = queued
+}
+
+// ToggleMarked adds a session to the broadcast selection, or removes it.
+func (m *Model) ToggleMarked(id string) {
+	if m.delegate.Marked[id] {
+		delete(m.delegate.Marked, id)
+	} else {
+		m.delegate.Marked[id] = true
+	}
+}
+
+// Marked returns the sessions selected for a broadcast, in list order.
+func (m Model) Marked() []domain.Session {
+	var out []domain.Session
+	for _, it := range m.List.Items() {
+		if item, ok := it.(Item); ok && m.delegate.Marked[item.Session.ID] {
+			out = append(out, item.Session)
+		}
+	}
+	return out
+}
+
+// ClearMarked empties the broadcast selection.
+func (m *Model) ClearMarked() {
+	clear(m.delegate.Marked)
+}
+
+// ShowArchived reports whether archived sessions are listed.
+func (m Model) ShowArchived() bool {
+	return m.showArchived
+}
+
+// SetShowArchived lists or hides archived sessions; call SetItems afterwards
+// to re-filter.
+func (m *Model) SetShowArchived(show bool) {
+	m.showArchived = show
+}
+
 // IsFiltering returns true if the list is in active filter mode.
 func (m Model) IsFiltering() bool {
 	return m.List.FilterState() == list.Filtering
@@ -192,7 +311,7 @@ func (m Model) IsFiltering() bool {
 func (m *Model) ClearFilterAndSetItems(sessions []domain.Session) {
 	m.List.ResetFilter()
 
-	sorted := m.sortSessions(sessions)
+	sorted := m.sortSessions(m.visible(sessions))
 	items := make([]list.Item, len(sorted))
 	for i, s := range sorted {
 		items[i] = Item{Session: s}
@@ -270,7 +389,7 @@ func (m *Model) setItemsInternal(sessions []domain.Session) {
 		cursorID = sel.ID
 	}
 
-	sorted := m.sortSessions(sessions)
+	sorted := m.sortSessions(m.visible(sessions))
 
 	items := make([]list.Item, len(sorted))
 	for i, s := range sorted {
@@ -289,9 +408,24 @@ func (m *Model) setItemsInternal(sessions []domain.Session) {
 	}
 }
 
+// visible drops archived sessions unless they are being shown. The active
+// session is always kept so the open conversation stays in the list.
+func (m *Model) visible(sessions []domain.Session) []domain.Session {
+	if m.showArchived || len(m.delegate.Archived) == 0 {
+		return sessions
+	}
+	out := make([]domain.Session, 0, len(sessions))
+	for _, s := range sessions {
+		if !m.delegate.Archived[s.ID] || s.ID == m.activeID {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
 // sortSessions orders sessions by priority:
 // 1. Active session (currently viewed) at top
-// 2. Sessions with unread messages, sorted by most recent activity
+// 2. Sessions with unread messages or pending approvals, sorted by most recent activity
 // 3. Idle sessions, sorted by most recently updated
 func (m *Model) sortSessions(sessions []domain.Session) []domain.Session {
 	result := make([]domain.Session, len(sessions))
@@ -308,11 +442,9 @@ func (m *Model) sortSessions(sessions []domain.Session) []domain.Session {
 			return false
 		}
 
-		// Unread sessions before idle
-		ui := m.delegate.Unread[si.ID]
-		uj := m.delegate.Unread[sj.ID]
-		hasUnreadI := ui > 0
-		hasUnreadJ := uj > 0
+		// Unread sessions and sessions awaiting approval before idle
+		hasUnreadI := m.delegate.needsAttention(si.ID)
+		hasUnreadJ := m.delegate.needsAttention(sj.ID)
 
 		if hasUnreadI && !hasUnreadJ {
 			return true
@@ -71,3 +71,48 @@ func TestSortPreservesCursor(t *testing.T) {
 		t.Errorf("cursor should stay on 'b' after re-sort, got %q", sel.ID)
 	}
 }
+
+func TestArchivedSessionsHidden(t *testing.T) {
+	sessions := []domain.Session{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+	m := New(nil, 30, 20)
+	m.SetArchived(map[string]bool{"b": true, "c": true})
+	m.SetActiveID("c")
+	m.SetItems(sessions)
+
+	// The open session stays listed even when archived.
+	if got := len(m.List.Items()); got != 2 {
+		t.Errorf("visible items = %d, want 2 (a and active c)", got)
+	}
+
+	m.SetShowArchived(true)
+	m.SetItems(sessions)
+	if got := len(m.List.Items()); got != 3 {
+		t.Errorf("visible items with archived shown = %d, want 3", got)
+	}
+}
+
+func TestMarkedFollowsListOrder(t *testing.T) {
+	now := time.Now()
+	m := New(nil, 30, 20)
+	m.SetItems([]domain.Session{
+		{ID: "a", UpdatedAt: now},
+		{ID: "b", UpdatedAt: now.Add(-time.Minute)},
+		{ID: "c", UpdatedAt: now.Add(-2 * time.Minute)},
+	})
+
+	m.ToggleMarked("c")
+	m.ToggleMarked("a")
+	m.ToggleMarked("b")
+	m.ToggleMarked("b")
+
+	got := m.Marked()
+	if len(got) != 2 || got[0].ID != "a" || got[1].ID != "c" {
+		t.Fatalf("Marked() = %v, want [a c]", got)
+	}
+
+	m.ClearMarked()
+	if len(m.Marked()) != 0 {
+		t.Error("ClearMarked should empty the selection")
+	}
+}
@@ -38,6 +38,16 @@ var (
 				Foreground(Warning).
 				Bold(true)
 
+	ApprovalBadgeStyle = lipgloss.NewStyle().
+				Foreground(Error).
+				Bold(true)
+
+	DraftBadgeStyle = lipgloss.NewStyle().
+			Foreground(Accent)
+
+	QueueBadgeStyle = lipgloss.NewStyle().
+			Foreground(Subtle)
+
 	// Title
 	TitleStyle = lipgloss.NewStyle().
 			Bold(true).